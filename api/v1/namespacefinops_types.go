@@ -44,11 +44,29 @@ type NamespaceFinOpsSpec struct {
 	// TargetNamespace is the namespace this CR is tracking metrics for
 	// +kubebuilder:validation:Required
 	TargetNamespace string `json:"targetNamespace"`
+
+	// HistoryRetentionMinutes is how many minutes of metrics history are kept in status
+	// +optional
+	// +kubebuilder:default=60
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1440
+	HistoryRetentionMinutes int32 `json:"historyRetentionMinutes,omitempty"`
+}
+
+// WorkloadInsight holds the insights computed from the running pods of one workload
+type WorkloadInsight struct {
+	// Kind is Deployment or StatefulSet
+	Kind string `json:"kind"`
+	// Name of the workload
+	Name string `json:"name"`
+	// Insights about the workload, with the same labels as the namespace insights
+	// +listType=atomic
+	Insights []string `json:"insights"`
 }
 
 // NamespaceFinOpsStatus defines the observed state of NamespaceFinOps.
 type NamespaceFinOpsStatus struct {
-	// History contains the last 60 minutes of metrics (1 data point per minute)
+	// History contains the last HistoryRetentionMinutes minutes of metrics (1 data point per minute)
 	// +optional
 	// +listType=atomic
 	History []MetricDataPoint `json:"history,omitempty"`
@@ -62,6 +80,26 @@ type NamespaceFinOpsStatus struct {
 	// +listType=atomic
 	Insights []string `json:"insights,omitempty"`
 
+	// WorkloadInsights lists the workloads with insights of their own, pointing at the ones
+	// responsible for the namespace insights
+	// +optional
+	// +listType=atomic
+	WorkloadInsights []WorkloadInsight `json:"workloadInsights,omitempty"`
+
+	// EstimatedMonthlyWaste is the estimated monthly cost of requested but unused CPU and memory,
+	// in the currency of the configured prices
+	// +optional
+	EstimatedMonthlyWaste string `json:"estimatedMonthlyWaste,omitempty"`
+
+	// LastMetricsError is the last error returned by the metrics API, cleared on the next successful poll
+	// +optional
+	LastMetricsError string `json:"lastMetricsError,omitempty"`
+
+	// MetricsStale is true when metrics have been unavailable long enough that History no longer
+	// reflects the current state of the namespace
+	// +optional
+	MetricsStale bool `json:"metricsStale,omitempty"`
+
 	// conditions represent the current state of the NamespaceFinOps resource.
 	// +listType=map
 	// +listMapKey=type
@@ -28,16 +28,80 @@ type ResourceValues struct {
 	MemoryLimit   string `json:"memoryLimit,omitempty"`
 }
 
+// ContainerOptimization stores optimization details for a single container of a workload
+type ContainerOptimization struct {
+	// Name of the container
+	Name string `json:"name"`
+	// Original values before optimization
+	Original ResourceValues `json:"original"`
+	// Optimized values applied
+	Optimized ResourceValues `json:"optimized"`
+	// Clamped lists the values moved into the bounds of the namespace LimitRanges
+	// +optional
+	Clamped []string `json:"clamped,omitempty"`
+	// Skipped is set when the container is excluded from optimization and kept its values
+	// +optional
+	Skipped bool `json:"skipped,omitempty"`
+	// Init is set for init containers
+	// +optional
+	Init bool `json:"init,omitempty"`
+}
+
 // WorkloadOptimization stores optimization details for a specific workload
 type WorkloadOptimization struct {
 	// Name of the workload (Deployment or StatefulSet)
 	Name string `json:"name"`
 	// Kind of the workload
 	Kind string `json:"kind"`
-	// Original values before optimization
+	// Original values before optimization, summed across all containers of the pod
 	Original ResourceValues `json:"original"`
-	// Optimized values applied
+	// Optimized values applied, summed across all containers of the pod
 	Optimized ResourceValues `json:"optimized"`
+	// Containers holds the original and optimized values of each container
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Containers []ContainerOptimization `json:"containers,omitempty"`
+}
+
+// SkippedWorkload is a workload left untouched by an optimization
+type SkippedWorkload struct {
+	// Name of the workload (Deployment or StatefulSet)
+	Name string `json:"name"`
+	// Kind of the workload
+	Kind string `json:"kind"`
+	// Reason explains why the workload was not optimized
+	Reason string `json:"reason"`
+}
+
+// OptimizationSnapshot records a single optimization run
+type OptimizationSnapshot struct {
+	// OptimizedAt is when the run was applied
+	OptimizedAt metav1.Time `json:"optimizedAt"`
+	// Strategy is the usage aggregation used by the run
+	// +optional
+	Strategy string `json:"strategy,omitempty"`
+	// Workloads holds the values the run started from and applied
+	// +optional
+	// +listType=atomic
+	Workloads []WorkloadOptimization `json:"workloads,omitempty"`
+}
+
+// OptimizationProposal is an optimization computed for review, applied only once approved
+type OptimizationProposal struct {
+	// ProposedAt is when the proposal was computed
+	ProposedAt metav1.Time `json:"proposedAt"`
+	// Strategy is the usage aggregation used to size the workloads
+	// +optional
+	Strategy string `json:"strategy,omitempty"`
+	// Workloads holds the current and proposed values of each workload
+	// +optional
+	// +listType=atomic
+	Workloads []WorkloadOptimization `json:"workloads,omitempty"`
+	// Skipped lists workloads the proposal leaves out
+	// +optional
+	// +listType=atomic
+	Skipped []SkippedWorkload `json:"skipped,omitempty"`
 }
 
 // NamespaceOptimizationSpec defines the desired state of NamespaceOptimization
@@ -54,11 +118,27 @@ type NamespaceOptimizationStatus struct {
 	// OptimizedAt is when the optimization was last applied
 	// +optional
 	OptimizedAt metav1.Time `json:"optimizedAt,omitempty"`
+	// Strategy is the usage aggregation used to size the workloads (average or p95)
+	// +optional
+	Strategy string `json:"strategy,omitempty"`
 	// Workloads contains the list of optimized workloads and their original values
 	// +optional
 	// +listType=map
 	// +listMapKey=name
 	Workloads []WorkloadOptimization `json:"workloads,omitempty"`
+	// Skipped lists workloads that were not optimized, e.g. for lack of usage data
+	// +optional
+	// +listType=atomic
+	Skipped []SkippedWorkload `json:"skipped,omitempty"`
+	// History lists the most recent optimization runs, oldest first. Workloads keeps the
+	// values from before the first run still applied, so a revert restores the true baseline.
+	// +optional
+	// +listType=atomic
+	History []OptimizationSnapshot `json:"history,omitempty"`
+	// Proposal is the optimization waiting for approval, if any. Applying any optimization
+	// clears it.
+	// +optional
+	Proposal *OptimizationProposal `json:"proposal,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -39,6 +39,46 @@ type ScalingSchedule struct {
 	// If empty, local operator time is used.
 	// +optional
 	Timezone string `json:"timezone,omitempty"`
+
+	// Overnight marks a window that runs past midnight, from StartTime on one of the Days
+	// to EndTime on the following day. It is required when EndTime is not after StartTime.
+	// +optional
+	Overnight bool `json:"overnight,omitempty"`
+}
+
+// ConditionalExclusion keeps workloads from being scaled down while one of its schedules
+// is active, e.g. batch workers that may only be stopped outside business hours
+type ConditionalExclusion struct {
+	// Names of the excluded workloads, accepting the same patterns as Exclusions
+	// +kubebuilder:validation:MinItems=1
+	// +listType=atomic
+	Names []string `json:"names"`
+
+	// Schedules during which the workloads are never scaled down
+	// +kubebuilder:validation:MinItems=1
+	// +listType=atomic
+	Schedules []ScalingSchedule `json:"schedules"`
+}
+
+// AutoIdlePolicy scales a namespace down once its CPU usage has stayed low for a number of
+// consecutive NamespaceFinOps history points, and back up when it rises again. The two
+// thresholds are apart so that usage hovering around one of them does not flap the namespace.
+type AutoIdlePolicy struct {
+	// IdleBelowMillicores is the CPU usage of the namespace under which a history point
+	// counts as idle
+	// +kubebuilder:validation:Minimum=0
+	IdleBelowMillicores int64 `json:"idleBelowMillicores"`
+
+	// ActiveAboveMillicores is the CPU usage from which an idle namespace is scaled back up.
+	// It must be above IdleBelowMillicores.
+	// +kubebuilder:validation:Minimum=1
+	ActiveAboveMillicores int64 `json:"activeAboveMillicores"`
+
+	// IdlePoints is how many consecutive history points must be idle before scaling down
+	// +optional
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	IdlePoints int32 `json:"idlePoints,omitempty"`
 }
 
 // ScalingConfigSpec defines the desired state of ScalingConfig
@@ -60,7 +100,8 @@ type ScalingConfigSpec struct {
 	Schedules []ScalingSchedule `json:"schedules,omitempty"`
 
 	// Sequence defines the order of scaling resources.
-	// Format: "Group/Version:Kind/Name" (e.g. "apps/v1:Deployment/my-app" or "apps/v1:Deployment/*")
+	// Format: "Group/Version:Kind/Name" (e.g. "apps/v1:Deployment/my-app" or "apps/v1:Deployment/*").
+	// "Kind/Name" matches any group and a bare name matches any kind; names accept globs.
 	// +optional
 	// +listType=atomic
 	Sequence []string `json:"sequence,omitempty"`
@@ -69,6 +110,93 @@ type ScalingConfigSpec struct {
 	// +optional
 	// +listType=atomic
 	Exclusions []string `json:"exclusions,omitempty"`
+
+	// ConditionalExclusions lists resources that are only kept from scaling down while
+	// their own schedule is active. Scale-up is never blocked by them.
+	// +optional
+	// +listType=atomic
+	ConditionalExclusions []ConditionalExclusion `json:"conditionalExclusions,omitempty"`
+
+	// StageTimeoutSeconds is how long a stage may stay not ready before the strict sequence
+	// is bypassed and the next stages are scaled anyway
+	// +optional
+	// +kubebuilder:default=60
+	// +kubebuilder:validation:Minimum=0
+	StageTimeoutSeconds int32 `json:"stageTimeoutSeconds,omitempty"`
+
+	// JitterSeconds delays the schedule boundaries by up to this many seconds, so that objects
+	// sharing a schedule do not all scale at the same instant. The delay is derived from the
+	// object name and is the same on every reconcile.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=3600
+	JitterSeconds int32 `json:"jitterSeconds,omitempty"`
+
+	// ScaleDownReplicaPercent keeps this percentage of the original replicas of every
+	// workload running while scaled down, rounded and at least 1. 0 scales to zero.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	ScaleDownReplicaPercent int32 `json:"scaleDownReplicaPercent,omitempty"`
+
+	// ScaleUpStepPercent ramps workloads up in steps of this percentage of their original
+	// replicas instead of restoring them at once. The next step starts once the pods of the
+	// previous one are ready. 0 restores the original replicas directly. Workloads managed
+	// by a HorizontalPodAutoscaler are not ramped.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	ScaleUpStepPercent int32 `json:"scaleUpStepPercent,omitempty"`
+
+	// ScaleKinds lists additional workload kinds to scale through their /scale subresource,
+	// besides Deployments and StatefulSets. Format: "Group/Version:Kind" (e.g. "argoproj.io/v1alpha1:Rollout")
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:items:Pattern=`^([a-z0-9.-]+/)?[a-z0-9]+:[A-Za-z0-9]+$`
+	ScaleKinds []string `json:"scaleKinds,omitempty"`
+
+	// ScaleReplicaSets also scales the standalone ReplicaSets of the namespace, those without
+	// a Deployment or any other controller owning them. ReplicaSets of Deployments are
+	// always left to their Deployment.
+	// +optional
+	ScaleReplicaSets bool `json:"scaleReplicaSets,omitempty"`
+
+	// AutoIdle scales the namespace down while it is idle within its active schedule
+	// windows. The manual override always wins.
+	// +optional
+	AutoIdle *AutoIdlePolicy `json:"autoIdle,omitempty"`
+}
+
+// MaxActionHistory is the number of scaling actions kept in the status, older ones are dropped
+const MaxActionHistory = 20
+
+// ScalingAction is a reconcile that changed replica counts
+type ScalingAction struct {
+	// Time is when the replicas were updated
+	Time metav1.Time `json:"time"`
+
+	// Direction is Up when scaling up and Down when scaling down
+	// +kubebuilder:validation:Enum=Up;Down
+	Direction string `json:"direction"`
+
+	// Changes are the replica updates attempted
+	Changes []ReplicaChange `json:"changes"`
+
+	// Succeeded is false when at least one update failed
+	Succeeded bool `json:"succeeded"`
+}
+
+// ReplicaChange is the update of the replica count of one workload
+type ReplicaChange struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	From      int32  `json:"from"`
+	To        int32  `json:"to"`
+
+	// Error is why the update failed, unset when it succeeded
+	// +optional
+	Error string `json:"error,omitempty"`
 }
 
 // ScalingConfigStatus defines the observed state of ScalingConfig.
@@ -86,6 +214,25 @@ type ScalingConfigStatus struct {
 	// +optional
 	OriginalReplicas map[string]int32 `json:"originalReplicas,omitempty"`
 
+	// ActionHistory lists the last MaxActionHistory scaling actions, oldest first
+	// +optional
+	// +kubebuilder:validation:MaxItems=20
+	ActionHistory []ScalingAction `json:"actionHistory,omitempty"`
+
+	// NextTransition is when the schedules next change the desired state. It is unset
+	// without schedules or while the manual override is set.
+	// +optional
+	NextTransition *metav1.Time `json:"nextTransition,omitempty"`
+
+	// NextTransitionState is the state entered at NextTransition
+	// +kubebuilder:validation:Enum=Active;Inactive
+	// +optional
+	NextTransitionState string `json:"nextTransitionState,omitempty"`
+
+	// Idle is set while the auto-idle policy keeps the namespace scaled down
+	// +optional
+	Idle bool `json:"idle,omitempty"`
+
 	// Conditions represent the current state of the ScalingConfig resource.
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
@@ -27,9 +27,15 @@ type ScalingGroupSpec struct {
 	Category string `json:"category"`
 
 	// Namespaces is the list of namespaces managed by this group
+	// +optional
 	// +kubebuilder:validation:MinItems=1
 	// +listType=set
-	Namespaces []string `json:"namespaces"`
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// NamespaceSelector adds every namespace whose labels match it to the group, on top of
+	// Namespaces. It is resolved on each reconcile, so new namespaces are picked up.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
 
 	// Active is the manual override for scaling.
 	// If null, the schedule is followed.
@@ -49,10 +55,54 @@ type ScalingGroupSpec struct {
 	// +listType=atomic
 	Sequence []string `json:"sequence,omitempty"`
 
+	// StageTimeoutSeconds is how long a stage may stay not ready before the strict sequence
+	// is bypassed and the next stages are scaled anyway
+	// +optional
+	// +kubebuilder:default=60
+	// +kubebuilder:validation:Minimum=0
+	StageTimeoutSeconds int32 `json:"stageTimeoutSeconds,omitempty"`
+
+	// JitterSeconds delays the schedule boundaries by up to this many seconds, so that objects
+	// sharing a schedule do not all scale at the same instant. The delay is derived from the
+	// object name and is the same on every reconcile.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=3600
+	JitterSeconds int32 `json:"jitterSeconds,omitempty"`
+
 	// ExternalTargets allows you to manage 3rd party cloud resources alongside Kubernetes resources.
 	// +optional
 	// +listType=atomic
 	ExternalTargets []ExternalTarget `json:"externalTargets,omitempty"`
+
+	// ScaleDownReplicaPercent keeps this percentage of the original replicas of every
+	// workload running while scaled down, rounded and at least 1. 0 scales to zero.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	ScaleDownReplicaPercent int32 `json:"scaleDownReplicaPercent,omitempty"`
+
+	// ScaleUpStepPercent ramps workloads up in steps of this percentage of their original
+	// replicas instead of restoring them at once. The next step starts once the pods of the
+	// previous one are ready. 0 restores the original replicas directly. Workloads managed
+	// by a HorizontalPodAutoscaler are not ramped.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	ScaleUpStepPercent int32 `json:"scaleUpStepPercent,omitempty"`
+
+	// ScaleKinds lists additional workload kinds to scale through their /scale subresource,
+	// besides Deployments and StatefulSets. Format: "Group/Version:Kind" (e.g. "argoproj.io/v1alpha1:Rollout")
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:items:Pattern=`^([a-z0-9.-]+/)?[a-z0-9]+:[A-Za-z0-9]+$`
+	ScaleKinds []string `json:"scaleKinds,omitempty"`
+
+	// ScaleReplicaSets also scales the standalone ReplicaSets of the namespace, those without
+	// a Deployment or any other controller owning them. ReplicaSets of Deployments are
+	// always left to their Deployment.
+	// +optional
+	ScaleReplicaSets bool `json:"scaleReplicaSets,omitempty"`
 }
 
 // ExternalTarget represents a 3rd party resource to scale
@@ -82,6 +132,9 @@ type ExternalTarget struct {
 	Status string `json:"status,omitempty"`
 }
 
+// ScaleUpAverageWindow is the number of completed scale-ups AvgScaleUpSeconds averages over
+const ScaleUpAverageWindow = 10
+
 // ScalingGroupStatus defines the observed state of ScalingGroup.
 type ScalingGroupStatus struct {
 	// Phase is the current state of the group (ScaledUp, ScalingDown, ScaledDown)
@@ -97,6 +150,18 @@ type ScalingGroupStatus struct {
 	// +optional
 	OriginalReplicas map[string]int32 `json:"originalReplicas,omitempty"`
 
+	// ActionHistory lists the last MaxActionHistory scaling actions across the namespaces of
+	// the group, oldest first
+	// +optional
+	// +kubebuilder:validation:MaxItems=20
+	ActionHistory []ScalingAction `json:"actionHistory,omitempty"`
+
+	// ManagedNamespaces is the resolved set of namespaces the group manages: Namespaces
+	// followed by the namespaces matching NamespaceSelector
+	// +optional
+	// +listType=set
+	ManagedNamespaces []string `json:"managedNamespaces,omitempty"`
+
 	// ManagedCount is the current number of successfully managed namespaces in the group
 	// +optional
 	ManagedCount int `json:"managedCount,omitempty"`
@@ -113,6 +178,49 @@ type ScalingGroupStatus struct {
 	// +optional
 	ReadyNamespaces []string `json:"readyNamespaces,omitempty"`
 
+	// CurrentStage is the 1-based stage of the sequence being executed, in the order of the
+	// current direction (stages run in reverse when scaling down). It equals TotalStages
+	// once every stage reached the target state.
+	// +optional
+	CurrentStage int `json:"currentStage,omitempty"`
+
+	// TotalStages is the number of stages of the sequence
+	// +optional
+	TotalStages int `json:"totalStages,omitempty"`
+
+	// BlockingNamespaces are the targets of the current stage that have not reached the
+	// target state yet
+	// +optional
+	BlockingNamespaces []string `json:"blockingNamespaces,omitempty"`
+
+	// NextTransition is when the schedules next change the desired state. It is unset
+	// without schedules or while the manual override is set.
+	// +optional
+	NextTransition *metav1.Time `json:"nextTransition,omitempty"`
+
+	// NextTransitionState is the state entered at NextTransition
+	// +kubebuilder:validation:Enum=Active;Inactive
+	// +optional
+	NextTransitionState string `json:"nextTransitionState,omitempty"`
+
+	// ScaleUpStartedAt is when the running scale-up entered ScalingUp, unset otherwise
+	// +optional
+	ScaleUpStartedAt *metav1.Time `json:"scaleUpStartedAt,omitempty"`
+
+	// LastScaleUpSeconds is how long the last completed scale-up took from ScalingUp to ScaledUp
+	// +optional
+	LastScaleUpSeconds int64 `json:"lastScaleUpSeconds,omitempty"`
+
+	// AvgScaleUpSeconds is the rolling average duration of the last ScaleUpAverageWindow
+	// completed scale-ups
+	// +optional
+	AvgScaleUpSeconds int64 `json:"avgScaleUpSeconds,omitempty"`
+
+	// ScaleUpSamples counts the completed scale-ups averaged in AvgScaleUpSeconds, up to
+	// ScaleUpAverageWindow
+	// +optional
+	ScaleUpSamples int32 `json:"scaleUpSamples,omitempty"`
+
 	// Conditions represent the current state of the ScalingGroup resource.
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
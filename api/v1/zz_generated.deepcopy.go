@@ -25,6 +25,70 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoIdlePolicy) DeepCopyInto(out *AutoIdlePolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoIdlePolicy.
+func (in *AutoIdlePolicy) DeepCopy() *AutoIdlePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoIdlePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConditionalExclusion) DeepCopyInto(out *ConditionalExclusion) {
+	*out = *in
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Schedules != nil {
+		in, out := &in.Schedules, &out.Schedules
+		*out = make([]ScalingSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConditionalExclusion.
+func (in *ConditionalExclusion) DeepCopy() *ConditionalExclusion {
+	if in == nil {
+		return nil
+	}
+	out := new(ConditionalExclusion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerOptimization) DeepCopyInto(out *ContainerOptimization) {
+	*out = *in
+	out.Original = in.Original
+	out.Optimized = in.Optimized
+	if in.Clamped != nil {
+		in, out := &in.Clamped, &out.Clamped
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerOptimization.
+func (in *ContainerOptimization) DeepCopy() *ContainerOptimization {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerOptimization)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExternalTarget) DeepCopyInto(out *ExternalTarget) {
 	*out = *in
@@ -148,6 +212,13 @@ func (in *NamespaceFinOpsStatus) DeepCopyInto(out *NamespaceFinOpsStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.WorkloadInsights != nil {
+		in, out := &in.WorkloadInsights, &out.WorkloadInsights
+		*out = make([]WorkloadInsight, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -248,8 +319,27 @@ func (in *NamespaceOptimizationStatus) DeepCopyInto(out *NamespaceOptimizationSt
 	if in.Workloads != nil {
 		in, out := &in.Workloads, &out.Workloads
 		*out = make([]WorkloadOptimization, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Skipped != nil {
+		in, out := &in.Skipped, &out.Skipped
+		*out = make([]SkippedWorkload, len(*in))
 		copy(*out, *in)
 	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]OptimizationSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Proposal != nil {
+		in, out := &in.Proposal, &out.Proposal
+		*out = new(OptimizationProposal)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceOptimizationStatus.
@@ -262,6 +352,72 @@ func (in *NamespaceOptimizationStatus) DeepCopy() *NamespaceOptimizationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OptimizationProposal) DeepCopyInto(out *OptimizationProposal) {
+	*out = *in
+	in.ProposedAt.DeepCopyInto(&out.ProposedAt)
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = make([]WorkloadOptimization, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Skipped != nil {
+		in, out := &in.Skipped, &out.Skipped
+		*out = make([]SkippedWorkload, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OptimizationProposal.
+func (in *OptimizationProposal) DeepCopy() *OptimizationProposal {
+	if in == nil {
+		return nil
+	}
+	out := new(OptimizationProposal)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OptimizationSnapshot) DeepCopyInto(out *OptimizationSnapshot) {
+	*out = *in
+	in.OptimizedAt.DeepCopyInto(&out.OptimizedAt)
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = make([]WorkloadOptimization, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OptimizationSnapshot.
+func (in *OptimizationSnapshot) DeepCopy() *OptimizationSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(OptimizationSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaChange) DeepCopyInto(out *ReplicaChange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaChange.
+func (in *ReplicaChange) DeepCopy() *ReplicaChange {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceMetrics) DeepCopyInto(out *ResourceMetrics) {
 	*out = *in
@@ -292,6 +448,27 @@ func (in *ResourceValues) DeepCopy() *ResourceValues {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingAction) DeepCopyInto(out *ScalingAction) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Changes != nil {
+		in, out := &in.Changes, &out.Changes
+		*out = make([]ReplicaChange, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingAction.
+func (in *ScalingAction) DeepCopy() *ScalingAction {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScalingConfig) DeepCopyInto(out *ScalingConfig) {
 	*out = *in
@@ -376,6 +553,23 @@ func (in *ScalingConfigSpec) DeepCopyInto(out *ScalingConfigSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ConditionalExclusions != nil {
+		in, out := &in.ConditionalExclusions, &out.ConditionalExclusions
+		*out = make([]ConditionalExclusion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ScaleKinds != nil {
+		in, out := &in.ScaleKinds, &out.ScaleKinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AutoIdle != nil {
+		in, out := &in.AutoIdle, &out.AutoIdle
+		*out = new(AutoIdlePolicy)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingConfigSpec.
@@ -399,6 +593,17 @@ func (in *ScalingConfigStatus) DeepCopyInto(out *ScalingConfigStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.ActionHistory != nil {
+		in, out := &in.ActionHistory, &out.ActionHistory
+		*out = make([]ScalingAction, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextTransition != nil {
+		in, out := &in.NextTransition, &out.NextTransition
+		*out = (*in).DeepCopy()
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -485,6 +690,11 @@ func (in *ScalingGroupSpec) DeepCopyInto(out *ScalingGroupSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Active != nil {
 		in, out := &in.Active, &out.Active
 		*out = new(bool)
@@ -507,6 +717,11 @@ func (in *ScalingGroupSpec) DeepCopyInto(out *ScalingGroupSpec) {
 		*out = make([]ExternalTarget, len(*in))
 		copy(*out, *in)
 	}
+	if in.ScaleKinds != nil {
+		in, out := &in.ScaleKinds, &out.ScaleKinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingGroupSpec.
@@ -530,11 +745,36 @@ func (in *ScalingGroupStatus) DeepCopyInto(out *ScalingGroupStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.ActionHistory != nil {
+		in, out := &in.ActionHistory, &out.ActionHistory
+		*out = make([]ScalingAction, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ManagedNamespaces != nil {
+		in, out := &in.ManagedNamespaces, &out.ManagedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.ReadyNamespaces != nil {
 		in, out := &in.ReadyNamespaces, &out.ReadyNamespaces
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.BlockingNamespaces != nil {
+		in, out := &in.BlockingNamespaces, &out.BlockingNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NextTransition != nil {
+		in, out := &in.NextTransition, &out.NextTransition
+		*out = (*in).DeepCopy()
+	}
+	if in.ScaleUpStartedAt != nil {
+		in, out := &in.ScaleUpStartedAt, &out.ScaleUpStartedAt
+		*out = (*in).DeepCopy()
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -574,11 +814,53 @@ func (in *ScalingSchedule) DeepCopy() *ScalingSchedule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SkippedWorkload) DeepCopyInto(out *SkippedWorkload) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SkippedWorkload.
+func (in *SkippedWorkload) DeepCopy() *SkippedWorkload {
+	if in == nil {
+		return nil
+	}
+	out := new(SkippedWorkload)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadInsight) DeepCopyInto(out *WorkloadInsight) {
+	*out = *in
+	if in.Insights != nil {
+		in, out := &in.Insights, &out.Insights
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadInsight.
+func (in *WorkloadInsight) DeepCopy() *WorkloadInsight {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadInsight)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadOptimization) DeepCopyInto(out *WorkloadOptimization) {
 	*out = *in
 	out.Original = in.Original
 	out.Optimized = in.Optimized
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]ContainerOptimization, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadOptimization.
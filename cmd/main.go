@@ -17,18 +17,25 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"os"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	uberzap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -41,6 +48,11 @@ import (
 	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
 	"github.com/migalsp/kubex-operator/internal/api"
 	"github.com/migalsp/kubex-operator/internal/controller"
+	"github.com/migalsp/kubex-operator/internal/operatorns"
+	"github.com/migalsp/kubex-operator/internal/reconcilestats"
+	"github.com/migalsp/kubex-operator/internal/scaling"
+	"github.com/migalsp/kubex-operator/internal/watchns"
+	webhookv1 "github.com/migalsp/kubex-operator/internal/webhook/v1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -66,6 +78,10 @@ func main() {
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var tlsOpts []func(*tls.Config)
+	// The flags default to KUBEX_REQUEUE_*, errors are reported once logging is set up
+	requeue, requeueErr := controller.RequeueIntervalsFromEnv()
+	maxScales, maxScalesErr := scaling.MaxConcurrentScalesFromEnv()
+	optimizeCooldown, optimizeCooldownErr := api.OptimizeCooldownFromEnv()
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -83,14 +99,57 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.DurationVar(&requeue.Base, "requeue-base", requeue.Base,
+		"How often settled resources are reconciled again, and NamespaceFinOps record a history point.")
+	flag.DurationVar(&requeue.InProgress, "requeue-in-progress", requeue.InProgress,
+		"How often ScalingGroups and ScalingConfigs are reconciled while a scaling rolls out.")
+	flag.DurationVar(&requeue.Overridden, "requeue-overridden", requeue.Overridden,
+		"How often ScalingConfigs overridden by a ScalingGroup are reconciled again.")
+	flag.IntVar(&maxScales, "max-concurrent-scales", maxScales,
+		"How many replica updates ScalingGroups and ScalingConfigs may have in flight at once, 0 for no limit.")
+	flag.DurationVar(&optimizeCooldown, "optimize-cooldown", optimizeCooldown,
+		"How long a namespace must wait between two applied optimizations, 0 for no cooldown.")
 	opts := zap.Options{
 		Development: true,
 	}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
+	// Keep the level adjustable at runtime through /api/operator/loglevel. --zap-log-level
+	// already yields an atomic level, otherwise start from the default of the mode.
+	logLevel, ok := opts.Level.(uberzap.AtomicLevel)
+	if !ok {
+		logLevel = uberzap.NewAtomicLevelAt(zapcore.InfoLevel)
+		if opts.Development {
+			logLevel.SetLevel(zapcore.DebugLevel)
+		}
+		opts.Level = logLevel
+	}
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if requeueErr == nil {
+		requeueErr = requeue.Validate()
+	}
+	if requeueErr != nil {
+		setupLog.Error(requeueErr, "Invalid requeue intervals")
+		os.Exit(1)
+	}
+	if maxScalesErr == nil && maxScales < 0 {
+		maxScalesErr = fmt.Errorf("max concurrent scales must not be negative, got %d", maxScales)
+	}
+	if maxScalesErr != nil {
+		setupLog.Error(maxScalesErr, "Invalid maximum of concurrent scale operations")
+		os.Exit(1)
+	}
+	scaleLimiter := scaling.NewLimiter(maxScales)
+	if optimizeCooldownErr == nil && optimizeCooldown < 0 {
+		optimizeCooldownErr = fmt.Errorf("optimize cooldown must not be negative, got %s", optimizeCooldown)
+	}
+	if optimizeCooldownErr != nil {
+		setupLog.Error(optimizeCooldownErr, "Invalid optimize cooldown")
+		os.Exit(1)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -152,9 +211,22 @@ func main() {
 		metricsServerOptions.KeyName = metricsCertKey
 	}
 
+	// With WATCH_NAMESPACES set, workloads are only cached in those namespaces and the
+	// operator one. Namespaces can't be listed without cluster-wide RBAC, they are read
+	// directly from the API server instead.
+	watched := watchns.FromEnv()
+	cacheOptions := cache.Options{DefaultNamespaces: watched.CacheNamespaces(operatorns.Namespace())}
+	clientOptions := client.Options{}
+	if watched.Restricted() {
+		setupLog.Info("Watching a restricted set of namespaces", "namespaces", watched)
+		clientOptions.Cache = &client.CacheOptions{DisableFor: []client.Object{&corev1.Namespace{}}}
+	}
+
 	config := ctrl.GetConfigOrDie()
 	mgr, err := ctrl.NewManager(config, ctrl.Options{
 		Scheme:                 scheme,
+		Cache:                  cacheOptions,
+		Client:                 clientOptions,
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
@@ -166,10 +238,18 @@ func main() {
 		os.Exit(1)
 	}
 
-	metricsClient, err := metricsv.NewForConfig(config)
-	if err != nil {
-		setupLog.Error(err, "Failed to create metrics client")
-		os.Exit(1)
+	if ns, fallback := operatorns.Resolve(); fallback {
+		setupLog.Info("WARNING: neither KUBEX_OPERATOR_NAMESPACE nor POD_NAMESPACE is set, falling back to the default operator namespace",
+			"namespace", ns)
+	}
+
+	// Without a metrics client the operator still runs, optimize answers 503 and the
+	// NamespaceFinOps report the metrics as unavailable
+	var metricsClient metricsv.Interface
+	if mc, err := metricsv.NewForConfig(config); err != nil {
+		setupLog.Error(err, "Failed to create metrics client, metrics are disabled")
+	} else {
+		metricsClient = mc
 	}
 
 	k8sClient, err := kubernetes.NewForConfig(config)
@@ -178,47 +258,92 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := api.SetupFieldIndexes(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		setupLog.Error(err, "Failed to register field indexes")
+		os.Exit(1)
+	}
+
+	reconcilers := reconcilestats.NewRecorder()
 	apiServer := &api.Server{
-		Client:        mgr.GetClient(),
-		K8sClient:     k8sClient,
-		MetricsClient: metricsClient,
-		Port:          "8082",
+		Client:           mgr.GetClient(),
+		K8sClient:        k8sClient,
+		MetricsClient:    metricsClient,
+		Recorder:         mgr.GetEventRecorderFor("kubex-api"),
+		Cache:            mgr.GetCache(),
+		LogLevel:         &logLevel,
+		Reconcilers:      reconcilers,
+		Port:             "8082",
+		OptimizeCooldown: optimizeCooldown,
+		WatchNamespaces:  watched,
 	}
 	if err := mgr.Add(apiServer); err != nil {
 		setupLog.Error(err, "Failed to add API server to manager")
 		os.Exit(1)
 	}
 
+	pricing, err := controller.PricingFromEnv()
+	if err != nil {
+		setupLog.Error(err, "Failed to load pricing model")
+		os.Exit(1)
+	}
+
 	if err := (&controller.NamespaceFinOpsReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
 		MetricsClient: metricsClient,
+		Pricing:       pricing,
+		Stats:         reconcilers,
+		Requeue:       requeue,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "Failed to create controller", "controller", "NamespaceFinOps")
 		os.Exit(1)
 	}
 
 	if err := (&controller.NamespaceDiscoveryReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		IgnorePatterns: controller.DiscoveryIgnoreFromEnv(),
+		Watched:        watched,
+		Stats:          reconcilers,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "Failed to create controller", "controller", "NamespaceDiscovery")
 		os.Exit(1)
 	}
+	notifier := controller.NewWebhookNotifier(os.Getenv("KUBEX_WEBHOOK_URL"))
+
 	if err := (&controller.ScalingConfigReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Notifier:     notifier,
+		Stats:        reconcilers,
+		Requeue:      requeue,
+		ScaleLimiter: scaleLimiter,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "Failed to create controller", "controller", "ScalingConfig")
 		os.Exit(1)
 	}
 	if err := (&controller.ScalingGroupReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Notifier:     notifier,
+		Stats:        reconcilers,
+		Requeue:      requeue,
+		ScaleLimiter: scaleLimiter,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "Failed to create controller", "controller", "ScalingGroup")
 		os.Exit(1)
 	}
+	// Webhooks need a serving certificate, so they are only served when enabled explicitly
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err := webhookv1.SetupScalingConfigWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "Failed to create webhook", "webhook", "ScalingConfig")
+			os.Exit(1)
+		}
+		if err := webhookv1.SetupScalingGroupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "Failed to create webhook", "webhook", "ScalingGroup")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
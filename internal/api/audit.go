@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+type contextKey string
+
+// userContextKey holds the username of the authenticated session in the request context
+const userContextKey contextKey = "kubex-user"
+
+// Audited actions, also used as the reason of the emitted Kubernetes events
+const (
+	auditOptimize       = "Optimize"
+	auditRevert         = "RevertOptimization"
+	auditScaleWorkload  = "ScaleWorkload"
+	auditManualOverride = "ManualOverride"
+	auditUpdateGroup    = "UpdateScalingGroup"
+	auditDeleteGroup    = "DeleteScalingGroup"
+	auditUpdateConfig   = "UpdateScalingConfig"
+	auditDeleteConfig   = "DeleteScalingConfig"
+	auditEmergency      = "EmergencyRestore"
+	auditRevokeSessions = "RevokeSessions"
+	auditImportScaling  = "ImportScaling"
+	auditScaleNamespace = "ScaleNamespace"
+	auditSetLogLevel    = "SetLogLevel"
+	auditPropose        = "ProposeOptimization"
+	auditApplyProposal  = "ApplyOptimization"
+	auditScalingFreeze  = "ScalingFreeze"
+)
+
+func withUser(ctx context.Context, username string) context.Context {
+	return context.WithValue(ctx, userContextKey, username)
+}
+
+// userFromContext returns the authenticated username, or "anonymous" when auth is disabled.
+func userFromContext(ctx context.Context) string {
+	if user, ok := ctx.Value(userContextKey).(string); ok && user != "" {
+		return user
+	}
+	return "anonymous"
+}
+
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// audit records who performed a mutating action. The entry always goes to the operator
+// log; when obj is set, it is also attached to that object as a Kubernetes event.
+func (s *Server) audit(r *http.Request, action, namespace, name string, obj runtime.Object) {
+	user := userFromContext(r.Context())
+	logf.Log.WithName("audit").Info("Audit",
+		"user", user,
+		"action", action,
+		"namespace", namespace,
+		"resource", name,
+		"timestamp", time.Now().UTC().Format(time.RFC3339),
+	)
+
+	if s.Recorder != nil && obj != nil {
+		s.Recorder.Eventf(obj, corev1.EventTypeNormal, action, "%s of %s/%s requested by %s", action, namespace, name, user)
+	}
+}
@@ -2,40 +2,152 @@ package api
 
 import (
 	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 var (
-	authUser     string
-	authPassword string
-	hmacKey      []byte
-	authOnce     sync.Once
+	authUser         string
+	authPassword     string
+	authPasswordHash []byte
+	authUsersFile    string
+	hmacKey          []byte
+	authOnce         sync.Once
+
+	// sessionEpoch is signed into every session token; bumping it invalidates all
+	// sessions issued before
+	sessionEpoch atomic.Int64
 )
 
 func loadAuthConfig() {
 	authOnce.Do(func() {
 		authUser = os.Getenv("KUBEX_AUTH_USER")
 		authPassword = os.Getenv("KUBEX_AUTH_PASSWORD")
-		if authPassword != "" {
+		if hash := os.Getenv("KUBEX_AUTH_PASSWORD_HASH"); hash != "" {
+			authPasswordHash = []byte(hash)
+		}
+		authUsersFile = os.Getenv("KUBEX_AUTH_USERS_FILE")
+
+		switch {
+		case os.Getenv("KUBEX_SESSION_KEY") != "":
+			hmacKey = []byte(os.Getenv("KUBEX_SESSION_KEY"))
+		case authPassword != "":
+			// Legacy setups without a dedicated session key sign with the password
 			hmacKey = []byte(authPassword + "-kubex-hmac-key")
+		case authPasswordHash != nil || authUsersFile != "":
+			// No stable key available, sessions will not survive a restart
+			logf.Log.Info("KUBEX_SESSION_KEY is not set, using a random session signing key")
+			hmacKey = make([]byte, 32)
+			rand.Read(hmacKey)
 		}
 	})
 }
 
+// singleUserConfigured reports whether the KUBEX_AUTH_USER account is usable.
+func singleUserConfigured() bool {
+	return authUser != "" && (authPassword != "" || authPasswordHash != nil)
+}
+
+// authEnabled reports whether credentials are configured.
+func authEnabled() bool {
+	return singleUserConfigured() || authUsersFile != ""
+}
+
+// roleReadOnly marks a users file entry that may only read, as in "carol:<hash>:readonly"
+const roleReadOnly = "readonly"
+
+// userEntry is a line of KUBEX_AUTH_USERS_FILE
+type userEntry struct {
+	hash     []byte
+	readOnly bool
+}
+
+// loadUsers reads the username:bcryptHash[:readonly] entries of KUBEX_AUTH_USERS_FILE. The
+// file is read on every call so that adding or revoking a user takes effect without a restart.
+func loadUsers() map[string]userEntry {
+	users := make(map[string]userEntry)
+	if authUsersFile == "" {
+		return users
+	}
+
+	data, err := os.ReadFile(authUsersFile)
+	if err != nil {
+		logf.Log.Error(err, "Failed to read users file", "path", authUsersFile)
+		return users
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		// bcrypt hashes never contain a colon, so anything after one is the role
+		hash, role, _ := strings.Cut(rest, ":")
+		users[name] = userEntry{hash: []byte(hash), readOnly: role == roleReadOnly}
+	}
+	return users
+}
+
+// checkCredentials validates a login against the users file, then the single configured user.
+func checkCredentials(username, password string) bool {
+	if u, ok := loadUsers()[username]; ok {
+		return bcrypt.CompareHashAndPassword(u.hash, []byte(password)) == nil
+	}
+	return singleUserConfigured() && subtle.ConstantTimeCompare([]byte(username), []byte(authUser)) == 1 && checkPassword(password)
+}
+
+// userExists reports whether the owner of a session is still allowed to log in.
+func userExists(username string) bool {
+	if singleUserConfigured() && username == authUser {
+		return true
+	}
+	_, ok := loadUsers()[username]
+	return ok
+}
+
+// isReadOnly reports whether a user is limited to read requests. The single configured
+// user always has full access.
+func isReadOnly(username string) bool {
+	if singleUserConfigured() && username == authUser {
+		return false
+	}
+	return loadUsers()[username].readOnly
+}
+
+// checkPassword verifies a password against the bcrypt hash when one is configured,
+// falling back to the plaintext password otherwise.
+func checkPassword(password string) bool {
+	if authPasswordHash != nil {
+		return bcrypt.CompareHashAndPassword(authPasswordHash, []byte(password)) == nil
+	}
+	return subtle.ConstantTimeCompare([]byte(password), []byte(authPassword)) == 1
+}
+
 // AuthMiddleware wraps the handler with session-cookie authentication.
-// If KUBEX_AUTH_USER is not set, auth is disabled (dev mode).
+// If neither KUBEX_AUTH_USER nor KUBEX_AUTH_USERS_FILE is set, auth is disabled (dev mode).
 func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		loadAuthConfig()
-		if authUser == "" || authPassword == "" {
+		if !authEnabled() {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -43,7 +155,8 @@ func AuthMiddleware(next http.Handler) http.Handler {
 		path := r.URL.Path
 
 		// Always allow these endpoints without auth
-		if path == "/api/login" || path == "/api/logout" || path == "/api/docs" || path == "/api/openapi.yaml" {
+		if path == "/api/login" || path == "/api/logout" || path == "/api/docs" || path == "/api/openapi.yaml" ||
+			path == "/healthz" || path == "/readyz" {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -55,15 +168,22 @@ func AuthMiddleware(next http.Handler) http.Handler {
 		}
 
 		// All /api/* endpoints require a valid session cookie
-		cookie, err := r.Cookie("kubex-session")
-		if err != nil || !validateSession(cookie.Value) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusUnauthorized)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Authentication required"})
+		user, valid := "", false
+		if cookie, err := r.Cookie("kubex-session"); err == nil {
+			user, valid = validateSession(cookie.Value)
+		}
+		if !valid {
+			writeJSONError(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+
+		// Read-only users may browse but never change anything
+		if isReadOnly(user) && r.Method != http.MethodGet && r.Method != http.MethodHead {
+			writeJSONError(w, "Read-only users cannot perform this action", http.StatusForbidden)
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(withUser(r.Context(), user)))
 	})
 }
 
@@ -72,35 +192,42 @@ func HandleLogin(w http.ResponseWriter, r *http.Request) {
 	loadAuthConfig()
 
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	// If auth is disabled, always succeed
-	if authUser == "" || authPassword == "" {
+	if !authEnabled() {
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 		return
 	}
 
+	addr := clientAddr(r)
+	if wait := loginAttempts.retryAfter(addr); wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		writeJSONError(w, "Too many failed login attempts, try again later", http.StatusTooManyRequests)
+		return
+	}
+
 	var creds struct {
 		Username string `json:"username"`
 		Password string `json:"password"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeJSONError(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	if creds.Username != authUser || creds.Password != authPassword {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid credentials"})
+	if !checkCredentials(creds.Username, creds.Password) {
+		loginAttempts.fail(addr)
+		writeJSONError(w, "Invalid credentials", http.StatusUnauthorized)
 		return
 	}
+	loginAttempts.reset(addr)
 
-	// Generate session token: timestamp|hmac(timestamp)
-	token := generateSession()
+	// Generate session token: user.timestamp.hmac(user.timestamp)
+	token := generateSession(creds.Username)
 	http.SetCookie(w, &http.Cookie{
 		Name:     "kubex-session",
 		Value:    token,
@@ -129,34 +256,54 @@ func HandleLogout(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
-func generateSession() string {
-	ts := fmt.Sprintf("%d", time.Now().Unix())
+// generateSession builds a token of the form user.epoch.timestamp.signature, with the
+// username base64url-encoded so it cannot contain the separator.
+func generateSession(username string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(username)) + "." + fmt.Sprintf("%d.%d", sessionEpoch.Load(), time.Now().Unix())
 	mac := hmac.New(sha256.New, hmacKey)
-	mac.Write([]byte(ts))
+	mac.Write([]byte(payload))
 	sig := hex.EncodeToString(mac.Sum(nil))
-	return ts + "." + sig
+	return payload + "." + sig
 }
 
-func validateSession(token string) bool {
-	parts := strings.SplitN(token, ".", 2)
-	if len(parts) != 2 {
-		return false
+// validateSession checks the token signature, epoch and expiry and returns the username
+// it was issued to. Sessions of users that have since been removed are rejected.
+func validateSession(token string) (string, bool) {
+	parts := strings.SplitN(token, ".", 4)
+	if len(parts) != 4 {
+		return "", false
 	}
 
-	ts := parts[0]
-	sig := parts[1]
+	user, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", false
+	}
+	epoch := parts[1]
+	ts := parts[2]
+	sig := parts[3]
+
+	// Reject sessions issued before the last revocation
+	if epoch != strconv.FormatInt(sessionEpoch.Load(), 10) {
+		return "", false
+	}
 
 	// Check if token is expired (24h)
 	var tokenTime int64
 	fmt.Sscanf(ts, "%d", &tokenTime)
 	if time.Now().Unix()-tokenTime > 86400 {
-		return false
+		return "", false
 	}
 
 	// Verify HMAC
 	mac := hmac.New(sha256.New, hmacKey)
-	mac.Write([]byte(ts))
+	mac.Write([]byte(parts[0] + "." + epoch + "." + ts))
 	expectedSig := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(sig), []byte(expectedSig)) {
+		return "", false
+	}
 
-	return hmac.Equal([]byte(sig), []byte(expectedSig))
+	if !userExists(string(user)) {
+		return "", false
+	}
+	return string(user), true
 }
@@ -0,0 +1,318 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func resetAuthConfig(t *testing.T, env map[string]string) {
+	t.Helper()
+	for _, k := range []string{"KUBEX_AUTH_USER", "KUBEX_AUTH_PASSWORD", "KUBEX_AUTH_PASSWORD_HASH", "KUBEX_AUTH_USERS_FILE", "KUBEX_SESSION_KEY"} {
+		os.Unsetenv(k)
+	}
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+	authUser, authPassword, authPasswordHash, authUsersFile, hmacKey = "", "", nil, "", nil
+	authOnce = sync.Once{}
+	loginAttempts = newLoginLimiter()
+	sessionEpoch.Store(0)
+	t.Cleanup(func() {
+		authUser, authPassword, authPasswordHash, authUsersFile, hmacKey = "", "", nil, "", nil
+		authOnce = sync.Once{}
+		sessionEpoch.Store(0)
+	})
+}
+
+func login(username, password string) *httptest.ResponseRecorder {
+	body := []byte(`{"username":"` + username + `","password":"` + password + `"}`)
+	req, _ := http.NewRequest("POST", "/api/login", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	HandleLogin(rr, req)
+	return rr
+}
+
+func TestHandleLoginBcryptHash(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resetAuthConfig(t, map[string]string{
+		"KUBEX_AUTH_USER":          "admin",
+		"KUBEX_AUTH_PASSWORD_HASH": string(hash),
+		"KUBEX_SESSION_KEY":        "session-key",
+	})
+
+	if rr := login("admin", "wrong"); rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for wrong password, got %v", rr.Code)
+	}
+
+	rr := login("admin", "s3cret")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 for correct password, got %v", rr.Code)
+	}
+	cookies := rr.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected a session cookie, got %v", cookies)
+	}
+	if user, ok := validateSession(cookies[0].Value); !ok || user != "admin" {
+		t.Errorf("expected a valid session cookie, got %v", cookies)
+	}
+}
+
+func TestSessionKeyIndependentOfPassword(t *testing.T) {
+	resetAuthConfig(t, map[string]string{
+		"KUBEX_AUTH_USER":     "admin",
+		"KUBEX_AUTH_PASSWORD": "old-password",
+		"KUBEX_SESSION_KEY":   "session-key",
+	})
+	loadAuthConfig()
+	token := generateSession("admin")
+
+	// Rotating the password must not invalidate sessions signed with the session key
+	resetAuthConfig(t, map[string]string{
+		"KUBEX_AUTH_USER":     "admin",
+		"KUBEX_AUTH_PASSWORD": "new-password",
+		"KUBEX_SESSION_KEY":   "session-key",
+	})
+	loadAuthConfig()
+	if _, ok := validateSession(token); !ok {
+		t.Errorf("expected session to survive a password change")
+	}
+	if rr := login("admin", "new-password"); rr.Code != http.StatusOK {
+		t.Errorf("expected plaintext password login to keep working, got %v", rr.Code)
+	}
+}
+
+func TestHandleLoginUsersFile(t *testing.T) {
+	aliceHash, _ := bcrypt.GenerateFromPassword([]byte("alice-pw"), bcrypt.MinCost)
+	bobHash, _ := bcrypt.GenerateFromPassword([]byte("bob-pw"), bcrypt.MinCost)
+
+	usersFile := filepath.Join(t.TempDir(), "users")
+	content := "# platform team\nalice:" + string(aliceHash) + "\nbob:" + string(bobHash) + "\n"
+	if err := os.WriteFile(usersFile, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	resetAuthConfig(t, map[string]string{
+		"KUBEX_AUTH_USERS_FILE": usersFile,
+		"KUBEX_SESSION_KEY":     "session-key",
+	})
+
+	if rr := login("alice", "bob-pw"); rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for another user's password, got %v", rr.Code)
+	}
+
+	rr := login("bob", "bob-pw")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 for bob, got %v", rr.Code)
+	}
+	token := rr.Result().Cookies()[0].Value
+	if user, ok := validateSession(token); !ok || user != "bob" {
+		t.Errorf("expected session for bob, got %q %v", user, ok)
+	}
+
+	// Revoking bob must invalidate his existing session without a restart
+	if err := os.WriteFile(usersFile, []byte("alice:"+string(aliceHash)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := validateSession(token); ok {
+		t.Errorf("expected session of a revoked user to be rejected")
+	}
+}
+
+func TestAuthMiddlewarePropagatesUser(t *testing.T) {
+	resetAuthConfig(t, map[string]string{
+		"KUBEX_AUTH_USER":     "admin",
+		"KUBEX_AUTH_PASSWORD": "pw",
+		"KUBEX_SESSION_KEY":   "session-key",
+	})
+	loadAuthConfig()
+
+	var seen string
+	handler := AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = userFromContext(r.Context())
+	}))
+
+	req, _ := http.NewRequest("GET", "/api/namespaces", nil)
+	req.AddCookie(&http.Cookie{Name: "kubex-session", Value: generateSession("admin")})
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if seen != "admin" {
+		t.Errorf("expected admin in request context, got %q", seen)
+	}
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	resetAuthConfig(t, map[string]string{"KUBEX_AUTH_USER": "admin", "KUBEX_AUTH_PASSWORD": "secret"})
+	t.Setenv("KUBEX_CORS_ORIGINS", "http://localhost:5173, https://ui.example.com/")
+
+	handler := CORSMiddleware(AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	// Preflight from an allowed origin is answered without a session
+	req := httptest.NewRequest(http.MethodOptions, "/api/namespaces", nil)
+	req.Header.Set("Origin", "https://ui.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 for preflight, got %v", rr.Code)
+	}
+	if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "https://ui.example.com" {
+		t.Errorf("expected origin to be allowed, got %q", got)
+	}
+	if rr.Header().Get("Access-Control-Allow-Credentials") != "true" || rr.Header().Get("Access-Control-Allow-Methods") == "" {
+		t.Errorf("expected credentials and methods to be allowed, got %v", rr.Header())
+	}
+
+	// Unknown origins get no CORS headers and go through authentication
+	req = httptest.NewRequest(http.MethodOptions, "/api/namespaces", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Header().Get("Access-Control-Allow-Origin") != "" || rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected unknown origin to be rejected, got %v %v", rr.Code, rr.Header())
+	}
+
+	// Without the variable the handler is left untouched
+	os.Unsetenv("KUBEX_CORS_ORIGINS")
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "http://localhost:5173")
+	rr = httptest.NewRecorder()
+	CORSMiddleware(http.NotFoundHandler()).ServeHTTP(rr, req)
+	if rr.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("expected no CORS headers when KUBEX_CORS_ORIGINS is unset")
+	}
+}
+
+func TestHandleLoginRateLimit(t *testing.T) {
+	resetAuthConfig(t, map[string]string{"KUBEX_AUTH_USER": "admin", "KUBEX_AUTH_PASSWORD": "secret"})
+	now := time.Now()
+	loginAttempts.now = func() time.Time { return now }
+
+	for i := 0; i < loginMaxFailures; i++ {
+		if rr := login("admin", "wrong"); rr.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: expected 401, got %d", i+1, rr.Code)
+		}
+	}
+
+	// Even the right password is refused while the client is blocked
+	rr := login("admin", "secret")
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 after %d failures, got %d", loginMaxFailures, rr.Code)
+	}
+	if rr.Header().Get("Retry-After") != "60" {
+		t.Errorf("expected Retry-After of 60 seconds, got %q", rr.Header().Get("Retry-After"))
+	}
+
+	// Other clients are not affected
+	body := []byte(`{"username":"admin","password":"secret"}`)
+	req := httptest.NewRequest("POST", "/api/login", bytes.NewReader(body))
+	req.RemoteAddr = "10.0.0.2:4321"
+	other := httptest.NewRecorder()
+	HandleLogin(other, req)
+	if other.Code != http.StatusOK {
+		t.Errorf("expected another client to log in, got %d", other.Code)
+	}
+
+	now = now.Add(loginFailureWindow)
+	if rr := login("admin", "secret"); rr.Code != http.StatusOK {
+		t.Errorf("expected login to succeed once the window expired, got %d", rr.Code)
+	}
+}
+
+func TestRevokeSessions(t *testing.T) {
+	resetAuthConfig(t, map[string]string{
+		"KUBEX_AUTH_USER":     "admin",
+		"KUBEX_AUTH_PASSWORD": "pw",
+		"KUBEX_SESSION_KEY":   "session-key",
+		"POD_NAMESPACE":       "kubex",
+	})
+	loadAuthConfig()
+
+	server := buildMockServerWithK8s()
+	token := generateSession("admin")
+
+	rr := httptest.NewRecorder()
+	server.handleRevokeSessions(rr, httptest.NewRequest("POST", "/api/sessions/revoke", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if _, ok := validateSession(token); ok {
+		t.Error("expected sessions issued before the revocation to be rejected")
+	}
+	if _, ok := validateSession(generateSession("admin")); !ok {
+		t.Error("expected new sessions to be accepted")
+	}
+
+	// The epoch survives a restart
+	sessionEpoch.Store(0)
+	if err := server.loadSessionEpoch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if sessionEpoch.Load() != 1 {
+		t.Errorf("expected the persisted epoch 1, got %d", sessionEpoch.Load())
+	}
+	if _, ok := validateSession(token); ok {
+		t.Error("expected revoked sessions to stay rejected after a restart")
+	}
+}
+
+func TestAuthMiddlewareReadOnlyUser(t *testing.T) {
+	carolHash, _ := bcrypt.GenerateFromPassword([]byte("carol-pw"), bcrypt.MinCost)
+	aliceHash, _ := bcrypt.GenerateFromPassword([]byte("alice-pw"), bcrypt.MinCost)
+
+	usersFile := filepath.Join(t.TempDir(), "users")
+	content := "alice:" + string(aliceHash) + "\ncarol:" + string(carolHash) + ":readonly\n"
+	if err := os.WriteFile(usersFile, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	resetAuthConfig(t, map[string]string{
+		"KUBEX_AUTH_USERS_FILE": usersFile,
+		"KUBEX_SESSION_KEY":     "session-key",
+	})
+
+	if rr := login("carol", "carol-pw"); rr.Code != http.StatusOK {
+		t.Fatalf("expected read-only users to log in, got %v", rr.Code)
+	}
+
+	handler := AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	do := func(user, method, path string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.AddCookie(&http.Cookie{Name: "kubex-session", Value: generateSession(user)})
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if code := do("carol", "GET", "/api/scaling/groups"); code != http.StatusOK {
+		t.Errorf("expected read-only GET to be allowed, got %d", code)
+	}
+	for _, tc := range []struct{ method, path string }{
+		{"PUT", "/api/scaling/groups/non-prod"},
+		{"POST", "/api/namespaces/test-ns/optimize"},
+		{"POST", "/api/namespaces/test-ns/revert"},
+		{"PUT", "/api/namespaces/test-ns/workloads/web"},
+	} {
+		if code := do("carol", tc.method, tc.path); code != http.StatusForbidden {
+			t.Errorf("expected 403 for read-only %s %s, got %d", tc.method, tc.path, code)
+		}
+	}
+	if code := do("alice", "POST", "/api/namespaces/test-ns/optimize"); code != http.StatusOK {
+		t.Errorf("expected full users to keep write access, got %d", code)
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultOptimizeCooldown is how long a namespace must wait between two applied
+// optimizations when KUBEX_OPTIMIZE_COOLDOWN is unset
+const DefaultOptimizeCooldown = 10 * time.Minute
+
+// OptimizeCooldownFromEnv reads KUBEX_OPTIMIZE_COOLDOWN, DefaultOptimizeCooldown when unset
+// and no cooldown when 0
+func OptimizeCooldownFromEnv() (time.Duration, error) {
+	v := os.Getenv("KUBEX_OPTIMIZE_COOLDOWN")
+	if v == "" {
+		return DefaultOptimizeCooldown, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return DefaultOptimizeCooldown, fmt.Errorf("invalid KUBEX_OPTIMIZE_COOLDOWN %q, expected a duration such as 10m, 0 for no cooldown", v)
+	}
+	return d, nil
+}
+
+// optimizeCooldownRemaining is how long a namespace optimized at optimizedAt must still wait
+// before the next optimization is applied, zero when it may go ahead
+func (s *Server) optimizeCooldownRemaining(optimizedAt metav1.Time) time.Duration {
+	if s.OptimizeCooldown <= 0 || optimizedAt.IsZero() {
+		return 0
+	}
+	return max(time.Until(optimizedAt.Add(s.OptimizeCooldown)), 0)
+}
+
+// writeOptimizeCooldown rejects an optimization applied within the cooldown of the previous
+// one, every run restarting the workloads of the namespace.
+func writeOptimizeCooldown(w http.ResponseWriter, nsName string, remaining time.Duration) {
+	seconds := int(math.Ceil(remaining.Seconds()))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(struct {
+		apiError
+		RetryAfterSeconds int `json:"retryAfterSeconds"`
+	}{
+		apiError: apiError{
+			Error: fmt.Sprintf("Namespace %s was optimized recently, try again in %s", nsName, (time.Duration(seconds) * time.Second).String()),
+			Code:  http.StatusTooManyRequests,
+		},
+		RetryAfterSeconds: seconds,
+	})
+}
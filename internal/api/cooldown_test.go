@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOptimizeCooldownFromEnv(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", DefaultOptimizeCooldown, false},
+		{"30m", 30 * time.Minute, false},
+		{"0", 0, false},
+		{"-1m", DefaultOptimizeCooldown, true},
+		{"soon", DefaultOptimizeCooldown, true},
+	}
+	for _, tt := range tests {
+		t.Setenv("KUBEX_OPTIMIZE_COOLDOWN", tt.value)
+		got, err := OptimizeCooldownFromEnv()
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%q: expected %s (error %v), got %s (%v)", tt.value, tt.want, tt.wantErr, got, err)
+		}
+	}
+}
+
+func TestHandleNamespaceOptimizeCooldown(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServerWithK8s()
+	server.OptimizeCooldown = 10 * time.Minute
+	ctx := context.Background()
+
+	server.Client.Create(ctx, &finopsv1.NamespaceOptimization{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Namespace: "kubex"},
+		Spec:       finopsv1.NamespaceOptimizationSpec{TargetNamespace: "test-ns"},
+		Status: finopsv1.NamespaceOptimizationStatus{
+			Active:      true,
+			OptimizedAt: metav1.NewTime(time.Now().Add(-4 * time.Minute)),
+			Proposal:    &finopsv1.OptimizationProposal{ProposedAt: metav1.Now(), Strategy: strategyAverage},
+		},
+	})
+
+	for _, path := range []string{"/api/namespaces/test-ns/optimize", "/api/namespaces/test-ns/optimize/apply"} {
+		rr := httptest.NewRecorder()
+		server.handleNamespaceRouting(rr, httptest.NewRequest("POST", path, nil))
+		if rr.Code != http.StatusTooManyRequests {
+			t.Fatalf("%s: expected 429, got %d: %s", path, rr.Code, rr.Body.String())
+		}
+		var body struct {
+			RetryAfterSeconds int `json:"retryAfterSeconds"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.RetryAfterSeconds <= 5*60 || body.RetryAfterSeconds > 6*60 {
+			t.Errorf("%s: expected about 6 minutes left, got %ds", path, body.RetryAfterSeconds)
+		}
+		if rr.Header().Get("Retry-After") == "" {
+			t.Errorf("%s: expected a Retry-After header", path)
+		}
+	}
+
+	// Dry runs change nothing and are not limited
+	rr := httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, httptest.NewRequest("POST", "/api/namespaces/test-ns/optimize?dryRun=true", nil))
+	if rr.Code == http.StatusTooManyRequests {
+		t.Errorf("expected dry runs to skip the cooldown")
+	}
+
+	server.OptimizeCooldown = 3 * time.Minute
+	if remaining := server.optimizeCooldownRemaining(metav1.NewTime(time.Now().Add(-4 * time.Minute))); remaining != 0 {
+		t.Errorf("expected the cooldown to be over, %s left", remaining)
+	}
+}
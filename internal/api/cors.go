@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+)
+
+const (
+	corsAllowMethods = "GET, POST, PUT, DELETE, OPTIONS"
+	corsAllowHeaders = "Content-Type, Authorization"
+)
+
+// corsOriginsFromEnv reads the comma separated allowlist of KUBEX_CORS_ORIGINS.
+func corsOriginsFromEnv() []string {
+	var origins []string
+	for _, o := range strings.Split(os.Getenv("KUBEX_CORS_ORIGINS"), ",") {
+		if o = strings.TrimSpace(o); o != "" {
+			origins = append(origins, strings.TrimSuffix(o, "/"))
+		}
+	}
+	return origins
+}
+
+// CORSMiddleware allows cross-origin calls from the origins in KUBEX_CORS_ORIGINS, with
+// credentials so the session cookie is sent. "*" allows any origin. Without the variable
+// no CORS headers are set and browsers keep enforcing the same-origin policy.
+func CORSMiddleware(next http.Handler) http.Handler {
+	origins := corsOriginsFromEnv()
+	if len(origins) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		w.Header().Add("Vary", "Origin")
+		if origin == "" || !(slices.Contains(origins, origin) || slices.Contains(origins, "*")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// The origin is echoed back because "*" is not accepted together with credentials
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+
+		// Preflight requests are answered here, before authentication
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", corsAllowMethods)
+			w.Header().Set("Access-Control-Allow-Headers", corsAllowHeaders)
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+)
+
+// OptimizationDrift is returned by GET /api/namespaces/{ns}/optimization/drift
+type OptimizationDrift struct {
+	// Active is false when the namespace has no optimization applied, nothing is compared then
+	Active      bool         `json:"active"`
+	OptimizedAt *metav1.Time `json:"optimizedAt,omitempty"`
+	// InSync counts the optimized workloads still running the optimized values
+	InSync  int             `json:"inSync"`
+	Drifted []WorkloadDrift `json:"drifted"`
+}
+
+// WorkloadDrift is an optimized workload whose live resources no longer match the
+// optimized values
+type WorkloadDrift struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// Missing is set when the workload was deleted since the optimization
+	Missing    bool             `json:"missing,omitempty"`
+	Containers []ContainerDrift `json:"containers,omitempty"`
+}
+
+// ContainerDrift compares the optimized and live values of a container
+type ContainerDrift struct {
+	Name string `json:"name"`
+	// Missing is set when the container was removed from the pod template
+	Missing   bool                    `json:"missing,omitempty"`
+	Optimized finopsv1.ResourceValues `json:"optimized"`
+	Current   finopsv1.ResourceValues `json:"current"`
+	// Fields lists the values that differ: cpuRequest, cpuLimit, memoryRequest, memoryLimit
+	Fields []string `json:"fields,omitempty"`
+}
+
+// handleNamespaceOptimizationDrift reports the optimized workloads edited since the
+// optimization, telling whether it is still in effect.
+func (s *Server) handleNamespaceOptimizationDrift(w http.ResponseWriter, r *http.Request, nsName string) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	result := OptimizationDrift{Drifted: []WorkloadDrift{}}
+	opt, err := s.resolveNamespaceOptimization(ctx, nsName)
+	if err != nil && !errors.IsNotFound(err) {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err == nil && opt.Status.Active {
+		result.Active = true
+		result.OptimizedAt = &opt.Status.OptimizedAt
+		for _, wo := range opt.Status.Workloads {
+			drift, err := s.workloadDrift(ctx, nsName, wo)
+			if err != nil {
+				writeJSONError(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if drift == nil {
+				result.InSync++
+				continue
+			}
+			result.Drifted = append(result.Drifted, *drift)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// workloadDrift compares an optimized workload with its live pod template, nil when it
+// still runs the optimized values.
+func (s *Server) workloadDrift(ctx context.Context, nsName string, wo finopsv1.WorkloadOptimization) (*WorkloadDrift, error) {
+	_, spec, _, err := s.getWorkload(ctx, nsName, wo.Kind, wo.Name)
+	if errors.IsNotFound(err) {
+		return &WorkloadDrift{Name: wo.Name, Kind: wo.Kind, Missing: true}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	containers := wo.Containers
+	// Records written before per-container tracking only covered the first container
+	if len(containers) == 0 && len(spec.Containers) > 0 {
+		containers = []finopsv1.ContainerOptimization{{Name: spec.Containers[0].Name, Optimized: wo.Optimized}}
+	}
+
+	var drifted []ContainerDrift
+	for _, co := range containers {
+		if co.Skipped {
+			continue
+		}
+		c := podContainer(spec, co.Name)
+		if c == nil {
+			drifted = append(drifted, ContainerDrift{Name: co.Name, Missing: true, Optimized: co.Optimized})
+			continue
+		}
+		current := containerResourceValues(*c)
+		if fields := driftedFields(co.Optimized, current); len(fields) > 0 {
+			drifted = append(drifted, ContainerDrift{Name: co.Name, Optimized: co.Optimized, Current: current, Fields: fields})
+		}
+	}
+	if len(drifted) == 0 {
+		return nil, nil
+	}
+	return &WorkloadDrift{Name: wo.Name, Kind: wo.Kind, Containers: drifted}, nil
+}
+
+// driftedFields lists the values of current differing from the optimized ones. Values the
+// optimization left empty, such as memory for a CPU-only run, are not compared.
+func driftedFields(optimized, current finopsv1.ResourceValues) []string {
+	var fields []string
+	for _, f := range []struct {
+		name              string
+		optimized, actual string
+	}{
+		{"cpuRequest", optimized.CPURequest, current.CPURequest},
+		{"cpuLimit", optimized.CPULimit, current.CPULimit},
+		{"memoryRequest", optimized.MemoryRequest, current.MemoryRequest},
+		{"memoryLimit", optimized.MemoryLimit, current.MemoryLimit},
+	} {
+		if f.optimized == "" {
+			continue
+		}
+		want, err := resource.ParseQuantity(f.optimized)
+		if err != nil {
+			continue
+		}
+		got, _ := resource.ParseQuantity(f.actual)
+		if want.Cmp(got) != 0 {
+			fields = append(fields, f.name)
+		}
+	}
+	return fields
+}
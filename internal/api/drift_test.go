@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"testing"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHandleNamespaceOptimizationDrift(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServerWithK8s()
+	ctx := context.Background()
+
+	deployment := func(name, cpu string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse("128Mi")},
+				},
+			}}}}},
+		}
+	}
+	// "1" and "1000m" are the same quantity, api keeps the optimized values
+	server.Client.Create(ctx, deployment("api", "1"))
+	server.Client.Create(ctx, deployment("web", "500m"))
+
+	optimized := func(name string) finopsv1.WorkloadOptimization {
+		values := finopsv1.ResourceValues{CPURequest: "1000m", MemoryRequest: "128Mi"}
+		return finopsv1.WorkloadOptimization{Name: name, Kind: "Deployment", Optimized: values,
+			Containers: []finopsv1.ContainerOptimization{{Name: "app", Optimized: values}}}
+	}
+	server.Client.Create(ctx, &finopsv1.NamespaceOptimization{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Namespace: "kubex"},
+		Spec:       finopsv1.NamespaceOptimizationSpec{TargetNamespace: "test-ns"},
+		Status: finopsv1.NamespaceOptimizationStatus{
+			Active:      true,
+			OptimizedAt: metav1.Now(),
+			Workloads:   []finopsv1.WorkloadOptimization{optimized("api"), optimized("web"), optimized("worker")},
+		},
+	})
+
+	rr := httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, httptest.NewRequest("GET", "/api/namespaces/test-ns/optimization/drift", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var drift OptimizationDrift
+	if err := json.NewDecoder(rr.Body).Decode(&drift); err != nil {
+		t.Fatal(err)
+	}
+	if !drift.Active || drift.InSync != 1 || len(drift.Drifted) != 2 {
+		t.Fatalf("expected api in sync and web, worker drifted, got %+v", drift)
+	}
+	web := drift.Drifted[0]
+	if web.Name != "web" || len(web.Containers) != 1 || !slices.Equal(web.Containers[0].Fields, []string{"cpuRequest"}) {
+		t.Errorf("expected the CPU request of web to drift, got %+v", web)
+	}
+	if web.Containers[0].Current.CPURequest != "500m" {
+		t.Errorf("expected the live value 500m, got %s", web.Containers[0].Current.CPURequest)
+	}
+	if worker := drift.Drifted[1]; worker.Name != "worker" || !worker.Missing {
+		t.Errorf("expected the deleted worker to be reported missing, got %+v", worker)
+	}
+
+	rr = httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, httptest.NewRequest("GET", "/api/namespaces/other/optimization/drift", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 without an optimization, got %d", rr.Code)
+	}
+	drift = OptimizationDrift{}
+	json.NewDecoder(rr.Body).Decode(&drift)
+	if drift.Active || len(drift.Drifted) != 0 {
+		t.Errorf("expected nothing compared without an optimization, got %+v", drift)
+	}
+}
@@ -0,0 +1,22 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// apiError is the body of every error response of the API
+type apiError struct {
+	Error string `json:"error"`
+	Code  int    `json:"code"`
+}
+
+// writeJSONError replies to the request with the given message and HTTP status as JSON.
+func writeJSONError(w http.ResponseWriter, message string, code int) {
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "application/json")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(apiError{Error: message, Code: code})
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/csv"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+)
+
+// historyCSVColumns are the columns of a history export, CPU in millicores and memory in MiB
+var historyCSVColumns = []string{"timestamp", "cpuUsage", "cpuRequests", "cpuLimits", "memUsage", "memRequests", "memLimits"}
+
+// serveHistoryCSV exports the usage history of a namespace as a CSV download.
+func (s *Server) serveHistoryCSV(w http.ResponseWriter, r *http.Request, nsName string) {
+	history, ok := s.namespaceHistory(w, r, nsName)
+	if !ok {
+		return
+	}
+
+	cw := startCSV(w, "kubex-"+nsName+"-history.csv", historyCSVColumns)
+	for _, p := range history {
+		cw.Write(historyCSVRecord(p))
+	}
+	cw.Flush()
+}
+
+// handleHistoryCSV exports the usage history of every tracked namespace, prefixed with a
+// namespace column.
+func (s *Server) handleHistoryCSV(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	resolution, ok := historyResolution(w, r)
+	if !ok {
+		return
+	}
+	from, to, ok := historyRange(w, r)
+	if !ok {
+		return
+	}
+
+	var list finopsv1.NamespaceFinOpsList
+	if err := s.Client.List(r.Context(), &list, client.InNamespace(getOperatorNamespace())); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	items := s.watchedNamespaceFinOps(list.Items)
+	sort.Slice(items, func(i, j int) bool { return items[i].Spec.TargetNamespace < items[j].Spec.TargetNamespace })
+
+	cw := startCSV(w, "kubex-history.csv", append([]string{"namespace"}, historyCSVColumns...))
+	for _, item := range items {
+		if !item.DeletionTimestamp.IsZero() {
+			continue
+		}
+		for _, p := range downsampleHistory(filterHistory(item.Status.History, from, to), resolution) {
+			cw.Write(append([]string{item.Spec.TargetNamespace}, historyCSVRecord(p)...))
+		}
+	}
+	cw.Flush()
+}
+
+// startCSV writes the download headers and the header row of a CSV response.
+func startCSV(w http.ResponseWriter, filename string, header []string) *csv.Writer {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename="+filename)
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	return cw
+}
+
+func historyCSVRecord(p finopsv1.MetricDataPoint) []string {
+	return []string{
+		p.Timestamp.UTC().Format(time.RFC3339),
+		millicores(p.CPU.Usage),
+		millicores(p.CPU.Requests),
+		millicores(p.CPU.Limits),
+		mebibytes(p.Memory.Usage),
+		mebibytes(p.Memory.Requests),
+		mebibytes(p.Memory.Limits),
+	}
+}
+
+// millicores formats a CPU quantity string in millicores, empty when it does not parse.
+func millicores(s string) string {
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return ""
+	}
+	return strconv.FormatInt(q.MilliValue(), 10)
+}
+
+// mebibytes formats a memory quantity string in MiB, empty when it does not parse.
+func mebibytes(s string) string {
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return ""
+	}
+	return strconv.FormatFloat(q.AsApproximateFloat64()/(1<<20), 'f', 2, 64)
+}
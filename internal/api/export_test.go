@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+)
+
+func TestHistoryCSV(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServerWithK8s()
+	point := finopsv1.MetricDataPoint{
+		Timestamp: metav1.NewTime(time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)),
+		CPU:       finopsv1.ResourceMetrics{Usage: "250m", Requests: "1", Limits: "2"},
+		Memory:    finopsv1.ResourceMetrics{Usage: "512Mi", Requests: "1Gi", Limits: ""},
+	}
+	for _, name := range []string{"web", "api"} {
+		server.Client.Create(context.Background(), &finopsv1.NamespaceFinOps{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kubex"},
+			Spec:       finopsv1.NamespaceFinOpsSpec{TargetNamespace: name},
+			Status:     finopsv1.NamespaceFinOpsStatus{History: []finopsv1.MetricDataPoint{point}},
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/namespaces/web/history.csv", nil)
+	rr := httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got := rr.Header().Get("Content-Disposition"); got != "attachment; filename=kubex-web-history.csv" {
+		t.Errorf("unexpected Content-Disposition %q", got)
+	}
+	want := "timestamp,cpuUsage,cpuRequests,cpuLimits,memUsage,memRequests,memLimits\n" +
+		"2026-01-01T10:00:00Z,250,1000,2000,512.00,1024.00,\n"
+	if rr.Body.String() != want {
+		t.Errorf("unexpected CSV:\n%s", rr.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/namespaces/history.csv", nil)
+	rr = httptest.NewRecorder()
+	server.handleHistoryCSV(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	want = "namespace,timestamp,cpuUsage,cpuRequests,cpuLimits,memUsage,memRequests,memLimits\n" +
+		"api,2026-01-01T10:00:00Z,250,1000,2000,512.00,1024.00,\n" +
+		"web,2026-01-01T10:00:00Z,250,1000,2000,512.00,1024.00,\n"
+	if rr.Body.String() != want {
+		t.Errorf("unexpected CSV:\n%s", rr.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/namespaces/history.csv?resolution=10s", nil)
+	rr = httptest.NewRecorder()
+	server.handleHistoryCSV(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid resolution, got %d", rr.Code)
+	}
+}
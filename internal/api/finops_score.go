@@ -0,0 +1,179 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+)
+
+// Weights of the cluster FinOps score components, summing to 1
+const (
+	scoreWeightRequestEfficiency = 0.5
+	scoreWeightRequestCoverage   = 0.2
+	scoreWeightNodeUtilization   = 0.3
+)
+
+// FinOpsScore is returned by GET /api/cluster/finops-score
+type FinOpsScore struct {
+	// Score is the weighted average of the available components, from 0 to 100
+	Score int `json:"score"`
+	// Namespaces is the number of NamespaceFinOps with usage data
+	Namespaces int `json:"namespaces"`
+	// Components explains the score. Components without data are listed as unavailable
+	// and their weight is shared among the others.
+	Components []FinOpsScoreComponent `json:"components"`
+}
+
+// FinOpsScoreComponent is one weighted part of the cluster FinOps score
+type FinOpsScoreComponent struct {
+	Name        string  `json:"name"`
+	Weight      float64 `json:"weight"`
+	Score       float64 `json:"score"`
+	Available   bool    `json:"available"`
+	Description string  `json:"description"`
+}
+
+// handleClusterFinOpsScore rates how efficiently the cluster uses what it reserves, from the
+// latest usage point of every NamespaceFinOps and the node summary of GET /api/cluster/nodes.
+// Nothing is sampled here, the score only aggregates data the operator already collected.
+func (s *Server) handleClusterFinOpsScore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	var list finopsv1.NamespaceFinOpsList
+	if err := s.Client.List(ctx, &list); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	nodes, err := s.clusterNodeSummary(ctx, labels.Everything())
+	if err != nil {
+		writeJSONError(w, "Failed to list nodes: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := FinOpsScore{}
+	efficiency := FinOpsScoreComponent{
+		Name:        "requestEfficiency",
+		Weight:      scoreWeightRequestEfficiency,
+		Description: "Used over requested CPU and memory across all NamespaceFinOps, capped at 100%. A namespace using 30% of its requests scores 30.",
+	}
+	coverage := FinOpsScoreComponent{
+		Name:        "requestCoverage",
+		Weight:      scoreWeightRequestCoverage,
+		Description: "Share of namespaces where every container sets CPU and memory requests, namespaces with the \"Missing Requests\" insight score 0.",
+	}
+	var cpuUsed, cpuRequested, memUsed, memRequested resource.Quantity
+	covered := 0
+	for _, item := range s.watchedNamespaceFinOps(list.Items) {
+		if len(item.Status.History) == 0 {
+			continue
+		}
+		latest := item.Status.History[len(item.Status.History)-1]
+		addQuantity(&cpuUsed, latest.CPU.Usage)
+		addQuantity(&cpuRequested, latest.CPU.Requests)
+		addQuantity(&memUsed, latest.Memory.Usage)
+		addQuantity(&memRequested, latest.Memory.Requests)
+		if !slices.Contains(item.Status.Insights, "Missing Requests") {
+			covered++
+		}
+		result.Namespaces++
+	}
+	if ratios := usageRatios(&cpuUsed, &cpuRequested, &memUsed, &memRequested); len(ratios) > 0 {
+		efficiency.Available = true
+		efficiency.Score = averagePercent(ratios)
+	}
+	if result.Namespaces > 0 {
+		coverage.Available = true
+		coverage.Score = roundScore(float64(covered) / float64(result.Namespaces) * 100)
+	}
+
+	utilization := FinOpsScoreComponent{
+		Name:        "nodeUtilization",
+		Weight:      scoreWeightNodeUtilization,
+		Description: "Used over allocatable CPU and memory of the nodes, capped at 100%. Unavailable without the metrics server.",
+	}
+	if s.MetricsClient != nil {
+		capacity, _ := nodes["totalCapacity"].(map[string]interface{})
+		usage, _ := nodes["totalUsage"].(map[string]interface{})
+		var ratios []float64
+		if c := summaryValue(capacity["cpu"]); c > 0 {
+			ratios = append(ratios, summaryValue(usage["cpu"])/c)
+		}
+		if c := summaryValue(capacity["mem"]); c > 0 {
+			ratios = append(ratios, summaryValue(usage["mem"])/c)
+		}
+		if len(ratios) > 0 {
+			utilization.Available = true
+			utilization.Score = averagePercent(ratios)
+		}
+	}
+
+	result.Components = []FinOpsScoreComponent{efficiency, coverage, utilization}
+	var weighted, weights float64
+	for _, c := range result.Components {
+		if c.Available {
+			weighted += c.Weight * c.Score
+			weights += c.Weight
+		}
+	}
+	if weights > 0 {
+		result.Score = int(math.Round(weighted / weights))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// addQuantity adds a quantity recorded as a string, values that do not parse count as zero
+func addQuantity(total *resource.Quantity, value string) {
+	if q, err := resource.ParseQuantity(value); err == nil {
+		total.Add(q)
+	}
+}
+
+// usageRatios returns used over requested for the resources with requests
+func usageRatios(cpuUsed, cpuRequested, memUsed, memRequested *resource.Quantity) []float64 {
+	var ratios []float64
+	if !cpuRequested.IsZero() {
+		ratios = append(ratios, cpuUsed.AsApproximateFloat64()/cpuRequested.AsApproximateFloat64())
+	}
+	if !memRequested.IsZero() {
+		ratios = append(ratios, memUsed.AsApproximateFloat64()/memRequested.AsApproximateFloat64())
+	}
+	return ratios
+}
+
+// averagePercent averages ratios capped at 1, as a percentage
+func averagePercent(ratios []float64) float64 {
+	var sum float64
+	for _, r := range ratios {
+		sum += min(r, 1)
+	}
+	return roundScore(sum / float64(len(ratios)) * 100)
+}
+
+// roundScore rounds a component score to one decimal
+func roundScore(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// summaryValue reads a number of the node summary, which holds CPU as float64 and memory
+// as int64
+func summaryValue(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int64:
+		return float64(n)
+	}
+	return 0
+}
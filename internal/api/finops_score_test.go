@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+func TestHandleClusterFinOpsScore(t *testing.T) {
+	server := buildMockServerWithK8s()
+	ctx := context.Background()
+
+	score := func() FinOpsScore {
+		server.nodeSummaries = nil
+		rr := httptest.NewRecorder()
+		server.handleClusterFinOpsScore(rr, httptest.NewRequest("GET", "/api/cluster/finops-score", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var result FinOpsScore
+		if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	if result := score(); result.Score != 0 || len(result.Components) != 3 || result.Components[0].Available {
+		t.Fatalf("expected no score without data, got %+v", result)
+	}
+
+	point := func(cpuUsage, cpuRequests, memUsage, memRequests string) []finopsv1.MetricDataPoint {
+		return []finopsv1.MetricDataPoint{{
+			CPU:    finopsv1.ResourceMetrics{Usage: cpuUsage, Requests: cpuRequests},
+			Memory: finopsv1.ResourceMetrics{Usage: memUsage, Requests: memRequests},
+		}}
+	}
+	server.Client.Create(ctx, &finopsv1.NamespaceFinOps{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "kubex"},
+		Status:     finopsv1.NamespaceFinOpsStatus{History: point("300m", "1", "1Gi", "2Gi")},
+	})
+	server.Client.Create(ctx, &finopsv1.NamespaceFinOps{
+		ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: "kubex"},
+		Status: finopsv1.NamespaceFinOpsStatus{
+			History:  point("100m", "1", "1Gi", "2Gi"),
+			Insights: []string{"Missing Requests"},
+		},
+	})
+	server.Client.Create(ctx, &finopsv1.NamespaceFinOps{ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "kubex"}})
+
+	// CPU 400m of 2 (20%), memory 2Gi of 4Gi (50%) -> 35, half the namespaces covered -> 50
+	result := score()
+	if result.Namespaces != 2 {
+		t.Errorf("expected the namespace without history to be left out, got %d", result.Namespaces)
+	}
+	if c := result.Components[0]; !c.Available || c.Score != 35 {
+		t.Errorf("expected a request efficiency of 35, got %+v", c)
+	}
+	if c := result.Components[1]; !c.Available || c.Score != 50 {
+		t.Errorf("expected a request coverage of 50, got %+v", c)
+	}
+	if c := result.Components[2]; c.Available {
+		t.Errorf("expected node utilization unavailable without the metrics server, got %+v", c)
+	}
+	// (0.5*35 + 0.2*50) / 0.7
+	if result.Score != 39 {
+		t.Errorf("expected the available components to be reweighted to 39, got %d", result.Score)
+	}
+
+	server.K8sClient.CoreV1().Nodes().Create(ctx, &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("4"),
+			corev1.ResourceMemory: resource.MustParse("8Gi"),
+		}},
+	}, metav1.CreateOptions{})
+	metricsClient := metricsfake.NewSimpleClientset()
+	metricsClient.PrependReactor("list", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &metricsv1beta1.NodeMetricsList{Items: []metricsv1beta1.NodeMetrics{{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Usage:      corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("2Gi")},
+		}}}, nil
+	})
+	server.MetricsClient = metricsClient
+
+	// CPU 50%, memory 25% -> 37.5, (0.5*35 + 0.2*50 + 0.3*37.5) = 38.75
+	result = score()
+	if c := result.Components[2]; !c.Available || c.Score != 37.5 {
+		t.Errorf("expected a node utilization of 37.5, got %+v", c)
+	}
+	if result.Score != 39 {
+		t.Errorf("expected a score of 39, got %d", result.Score)
+	}
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/migalsp/kubex-operator/internal/scaling"
+)
+
+// ScalingFreezeRequest is the body of PUT /api/scaling/freeze
+type ScalingFreezeRequest struct {
+	Frozen bool   `json:"frozen"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// loadScalingFreeze reads the cluster-wide scaling freeze, not frozen when its ConfigMap
+// doesn't exist.
+func (s *Server) loadScalingFreeze(ctx context.Context) (scaling.Freeze, error) {
+	cm, err := s.K8sClient.CoreV1().ConfigMaps(getOperatorNamespace()).Get(ctx, scaling.FreezeConfigMapName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return scaling.Freeze{}, nil
+	}
+	if err != nil {
+		return scaling.Freeze{}, err
+	}
+	return scaling.FreezeFromConfigMap(cm), nil
+}
+
+// handleScalingFreeze reads or toggles the scaling freeze. While frozen the reconcilers
+// change no replica counts, whatever the schedules and manual overrides say.
+func (s *Server) handleScalingFreeze(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		freeze, err := s.loadScalingFreeze(r.Context())
+		if err != nil {
+			writeJSONError(w, "Failed to read the scaling freeze: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(freeze)
+	case http.MethodPut:
+		var req ScalingFreezeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		freeze := scaling.Freeze{Frozen: req.Frozen}
+		if req.Frozen {
+			now := metav1.Now()
+			freeze.Reason = req.Reason
+			freeze.Since = &now
+			freeze.By = userFromContext(r.Context())
+		}
+
+		cm, err := s.storeScalingFreeze(r.Context(), freeze)
+		if err != nil {
+			writeJSONError(w, "Failed to store the scaling freeze: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		logf.Log.Info("Changed the scaling freeze", "frozen", freeze.Frozen, "reason", freeze.Reason)
+		s.audit(r, auditScalingFreeze, getOperatorNamespace(), scaling.FreezeConfigMapName, cm)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(scaling.FreezeFromConfigMap(cm))
+	default:
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// storeScalingFreeze writes the freeze into its ConfigMap, creating it on the first freeze
+func (s *Server) storeScalingFreeze(ctx context.Context, freeze scaling.Freeze) (*corev1.ConfigMap, error) {
+	operatorNs := getOperatorNamespace()
+	configMaps := s.K8sClient.CoreV1().ConfigMaps(operatorNs)
+
+	var stored *corev1.ConfigMap
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := configMaps.Get(ctx, scaling.FreezeConfigMapName, metav1.GetOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		exists := err == nil
+		if !exists {
+			cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: scaling.FreezeConfigMapName, Namespace: operatorNs}}
+		}
+		freeze.ApplyTo(cm)
+
+		if exists {
+			stored, err = configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+		} else {
+			stored, err = configMaps.Create(ctx, cm, metav1.CreateOptions{})
+		}
+		return err
+	})
+	return stored, err
+}
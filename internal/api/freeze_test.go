@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/migalsp/kubex-operator/internal/scaling"
+)
+
+func TestHandleScalingFreeze(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+	server := buildMockServerWithK8s()
+
+	send := func(method, body string) scaling.Freeze {
+		t.Helper()
+		req := httptest.NewRequest(method, "/api/scaling/freeze", strings.NewReader(body))
+		req = req.WithContext(withUser(req.Context(), "alice"))
+		rr := httptest.NewRecorder()
+		server.handleScalingFreeze(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", method, rr.Code, rr.Body.String())
+		}
+		var freeze scaling.Freeze
+		if err := json.NewDecoder(rr.Body).Decode(&freeze); err != nil {
+			t.Fatal(err)
+		}
+		return freeze
+	}
+
+	if freeze := send("GET", ""); freeze.Frozen {
+		t.Errorf("expected no freeze without the ConfigMap, got %+v", freeze)
+	}
+
+	freeze := send("PUT", `{"frozen":true,"reason":"CHG-42"}`)
+	if !freeze.Frozen || freeze.Reason != "CHG-42" || freeze.By != "alice" || freeze.Since == nil {
+		t.Errorf("unexpected freeze %+v", freeze)
+	}
+	if got := send("GET", ""); !got.Frozen || got.Reason != "CHG-42" {
+		t.Errorf("expected the freeze to be stored, got %+v", got)
+	}
+	cm, err := server.K8sClient.CoreV1().ConfigMaps("kubex").Get(context.Background(), scaling.FreezeConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cm.Data["frozen"] != "true" {
+		t.Errorf("expected frozen=true in the ConfigMap, got %v", cm.Data)
+	}
+
+	if freeze := send("PUT", `{"frozen":false}`); freeze.Frozen || freeze.Reason != "" {
+		t.Errorf("expected the freeze to be lifted, got %+v", freeze)
+	}
+
+	rr := httptest.NewRecorder()
+	server.handleScalingFreeze(rr, httptest.NewRequest("PUT", "/api/scaling/freeze", strings.NewReader("{")))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid body, got %d", rr.Code)
+	}
+	rr = httptest.NewRecorder()
+	server.handleScalingFreeze(rr, httptest.NewRequest("DELETE", "/api/scaling/freeze", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", rr.Code)
+	}
+}
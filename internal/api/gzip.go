@@ -0,0 +1,107 @@
+package api
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// uncompressedPaths are streamed to the client as they are produced, buffering them in a
+// gzip writer would hold back every line until the stream ends
+var uncompressedPaths = []string{
+	"/api/operator/logs/stream",
+}
+
+// GzipMiddleware compresses API responses for clients sending Accept-Encoding: gzip. The
+// embedded UI is served as is, so range requests on its files keep working.
+func GzipMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if r.Method == http.MethodHead || !strings.HasPrefix(r.URL.Path, "/api/") || !acceptsGzip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		for _, p := range uncompressedPaths {
+			if r.URL.Path == p {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: w}
+		defer gw.Close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip reports whether the Accept-Encoding header lists gzip without q=0
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(enc), ";")
+		if strings.TrimSpace(name) != "gzip" {
+			continue
+		}
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, err := strconv.ParseFloat(v, 64)
+			return err == nil && q > 0
+		}
+		return true
+	}
+	return false
+}
+
+// gzipResponseWriter compresses the body once the status is known to carry one. Responses
+// without a body, such as 204 and 304, are passed through untouched.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	wroteHeader bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	h := w.Header()
+	if code != http.StatusNoContent && code != http.StatusNotModified && h.Get("Content-Encoding") == "" {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gz == nil {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.gz.Write(b)
+}
+
+// Flush sends what was compressed so far
+func (w *gzipResponseWriter) Flush() {
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close writes the gzip footer, it is a no-op when nothing was compressed
+func (w *gzipResponseWriter) Close() error {
+	if w.gz == nil {
+		return nil
+	}
+	return w.gz.Close()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGzipMiddleware(t *testing.T) {
+	body := strings.Repeat(`{"name":"ns"},`, 100)
+	handler := GzipMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/empty" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, body)
+	}))
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		compressed     bool
+	}{
+		{"gzip accepted", "/api/namespaces", "br, gzip", true},
+		{"gzip with quality", "/api/namespaces", "gzip;q=0.5", true},
+		{"gzip refused", "/api/namespaces", "gzip;q=0", false},
+		{"no accept-encoding", "/api/namespaces", "", false},
+		{"log stream", "/api/operator/logs/stream", "gzip", false},
+		{"ui assets", "/assets/index.js", "gzip", false},
+		{"no body", "/api/empty", "gzip", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if got := rr.Header().Get("Content-Encoding") == "gzip"; got != tt.compressed {
+				t.Fatalf("expected compressed=%v, got Content-Encoding %q", tt.compressed, rr.Header().Get("Content-Encoding"))
+			}
+			if rr.Code == http.StatusNoContent {
+				if rr.Body.Len() != 0 {
+					t.Errorf("expected an empty body, got %d bytes", rr.Body.Len())
+				}
+				return
+			}
+
+			var reader io.Reader = rr.Body
+			if tt.compressed {
+				gz, err := gzip.NewReader(rr.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				reader = gz
+			}
+			got, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != body {
+				t.Errorf("unexpected body %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+)
+
+// filterHistory keeps the points between from and to, both inclusive. A zero bound leaves
+// that side open.
+func filterHistory(history []finopsv1.MetricDataPoint, from, to time.Time) []finopsv1.MetricDataPoint {
+	if from.IsZero() && to.IsZero() {
+		return history
+	}
+	result := []finopsv1.MetricDataPoint{}
+	for _, dp := range history {
+		if (!from.IsZero() && dp.Timestamp.Time.Before(from)) || (!to.IsZero() && dp.Timestamp.Time.After(to)) {
+			continue
+		}
+		result = append(result, dp)
+	}
+	return result
+}
+
+// downsampleHistory averages history points into buckets of the given resolution, aligned
+// on the clock (e.g. 10:00, 10:05 for 5m). Each bucket is stamped with its start time.
+func downsampleHistory(history []finopsv1.MetricDataPoint, resolution time.Duration) []finopsv1.MetricDataPoint {
+	result := []finopsv1.MetricDataPoint{}
+	if resolution <= 0 {
+		return append(result, history...)
+	}
+
+	var bucket []finopsv1.MetricDataPoint
+	var bucketStart time.Time
+	for _, dp := range history {
+		start := dp.Timestamp.Truncate(resolution)
+		if len(bucket) > 0 && !start.Equal(bucketStart) {
+			result = append(result, averagePoints(bucketStart, bucket))
+			bucket = bucket[:0]
+		}
+		bucketStart = start
+		bucket = append(bucket, dp)
+	}
+	if len(bucket) > 0 {
+		result = append(result, averagePoints(bucketStart, bucket))
+	}
+	return result
+}
+
+func averagePoints(start time.Time, points []finopsv1.MetricDataPoint) finopsv1.MetricDataPoint {
+	var cpu, mem [3]float64
+	for _, dp := range points {
+		for i, v := range []string{dp.CPU.Usage, dp.CPU.Requests, dp.CPU.Limits} {
+			q, _ := resource.ParseQuantity(v)
+			cpu[i] += q.AsApproximateFloat64()
+		}
+		for i, v := range []string{dp.Memory.Usage, dp.Memory.Requests, dp.Memory.Limits} {
+			q, _ := resource.ParseQuantity(v)
+			mem[i] += q.AsApproximateFloat64()
+		}
+	}
+
+	n := float64(len(points))
+	cpuStr := func(total float64) string {
+		return resource.NewMilliQuantity(int64(total/n*1000), resource.DecimalSI).String()
+	}
+	memStr := func(total float64) string {
+		return resource.NewQuantity(int64(total/n), resource.BinarySI).String()
+	}
+	return finopsv1.MetricDataPoint{
+		Timestamp: metav1.NewTime(start),
+		CPU:       finopsv1.ResourceMetrics{Usage: cpuStr(cpu[0]), Requests: cpuStr(cpu[1]), Limits: cpuStr(cpu[2])},
+		Memory:    finopsv1.ResourceMetrics{Usage: memStr(mem[0]), Requests: memStr(mem[1]), Limits: memStr(mem[2])},
+	}
+}
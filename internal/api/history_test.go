@@ -0,0 +1,72 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+)
+
+func TestDownsampleHistory(t *testing.T) {
+	base := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	point := func(minute int, cpu, mem string) finopsv1.MetricDataPoint {
+		return finopsv1.MetricDataPoint{
+			Timestamp: metav1.NewTime(base.Add(time.Duration(minute) * time.Minute)),
+			CPU:       finopsv1.ResourceMetrics{Usage: cpu, Requests: "1"},
+			Memory:    finopsv1.ResourceMetrics{Usage: mem},
+		}
+	}
+	history := []finopsv1.MetricDataPoint{
+		point(0, "100m", "100Mi"),
+		point(1, "300m", "300Mi"),
+		point(5, "500m", "1Gi"),
+	}
+
+	result := downsampleHistory(history, 5*time.Minute)
+	if len(result) != 2 {
+		t.Fatalf("expected 2 buckets, got %d", len(result))
+	}
+	if !result[0].Timestamp.Time.Equal(base) || result[0].CPU.Usage != "200m" || result[0].Memory.Usage != "200Mi" {
+		t.Errorf("unexpected first bucket: %+v", result[0])
+	}
+	if result[0].CPU.Requests != "1" {
+		t.Errorf("expected requests to be averaged to 1, got %s", result[0].CPU.Requests)
+	}
+	if result[1].CPU.Usage != "500m" || result[1].Memory.Usage != "1Gi" {
+		t.Errorf("unexpected second bucket: %+v", result[1])
+	}
+
+	if raw := downsampleHistory(history, 0); len(raw) != len(history) {
+		t.Errorf("expected raw history without resolution, got %d points", len(raw))
+	}
+}
+
+func TestFilterHistory(t *testing.T) {
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	var history []finopsv1.MetricDataPoint
+	for i := range 5 {
+		history = append(history, finopsv1.MetricDataPoint{Timestamp: metav1.NewTime(base.Add(time.Duration(i) * time.Minute))})
+	}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     int
+	}{
+		{"open", time.Time{}, time.Time{}, 5},
+		{"from only", base.Add(3 * time.Minute), time.Time{}, 2},
+		{"to only", time.Time{}, base.Add(time.Minute), 2},
+		{"inclusive bounds", base.Add(time.Minute), base.Add(3 * time.Minute), 3},
+		{"no points", base.Add(time.Hour), time.Time{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterHistory(history, tt.from, tt.to)
+			if got == nil || len(got) != tt.want {
+				t.Errorf("expected %d points, got %v", tt.want, got)
+			}
+		})
+	}
+}
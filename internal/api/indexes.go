@@ -0,0 +1,31 @@
+package api
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Field indexes on Events, used to select the events of a single object from the cache
+const (
+	eventInvolvedNameField = "involvedObject.name"
+	eventInvolvedKindField = "involvedObject.kind"
+)
+
+func eventInvolvedName(obj client.Object) []string {
+	return []string{obj.(*corev1.Event).InvolvedObject.Name}
+}
+
+func eventInvolvedKind(obj client.Object) []string {
+	return []string{obj.(*corev1.Event).InvolvedObject.Kind}
+}
+
+// SetupFieldIndexes registers the cache indexes the API server queries by field selector.
+// It must be called before the manager is started.
+func SetupFieldIndexes(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &corev1.Event{}, eventInvolvedNameField, eventInvolvedName); err != nil {
+		return err
+	}
+	return indexer.IndexField(ctx, &corev1.Event{}, eventInvolvedKindField, eventInvolvedKind)
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// logLevels are the levels accepted by POST /api/operator/loglevel
+var logLevels = map[string]zapcore.Level{
+	"debug": zapcore.DebugLevel,
+	"info":  zapcore.InfoLevel,
+	"warn":  zapcore.WarnLevel,
+	"error": zapcore.ErrorLevel,
+}
+
+// LogLevelResponse is returned by /api/operator/loglevel
+type LogLevelResponse struct {
+	Level string `json:"level"`
+}
+
+// handleLogLevel reads or changes the operator log level without a restart. The change is
+// not persisted, a restart goes back to the --zap-log-level flag.
+func (s *Server) handleLogLevel(w http.ResponseWriter, r *http.Request) {
+	if s.LogLevel == nil {
+		writeJSONError(w, "The log level cannot be changed at runtime", http.StatusNotImplemented)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req LogLevelResponse
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		level, ok := logLevels[req.Level]
+		if !ok {
+			writeJSONError(w, "Invalid level, expected debug, info, warn or error", http.StatusBadRequest)
+			return
+		}
+		s.LogLevel.SetLevel(level)
+		s.audit(r, auditSetLogLevel, getOperatorNamespace(), req.Level, nil)
+	default:
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(LogLevelResponse{Level: s.LogLevel.Level().String()})
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestHandleLogLevel(t *testing.T) {
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	server := &Server{LogLevel: &level}
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		code   int
+		want   zapcore.Level
+	}{
+		{"read", "GET", "", http.StatusOK, zapcore.InfoLevel},
+		{"debug", "POST", `{"level":"debug"}`, http.StatusOK, zapcore.DebugLevel},
+		{"unknown level", "POST", `{"level":"verbose"}`, http.StatusBadRequest, zapcore.DebugLevel},
+		{"error", "POST", `{"level":"error"}`, http.StatusOK, zapcore.ErrorLevel},
+		{"wrong method", "DELETE", "", http.StatusMethodNotAllowed, zapcore.ErrorLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			server.handleLogLevel(rr, httptest.NewRequest(tt.method, "/api/operator/loglevel", strings.NewReader(tt.body)))
+			if rr.Code != tt.code {
+				t.Fatalf("expected %d, got %d: %s", tt.code, rr.Code, rr.Body.String())
+			}
+			if level.Level() != tt.want {
+				t.Errorf("expected level %s, got %s", tt.want, level.Level())
+			}
+			if tt.code == http.StatusOK && strings.TrimSpace(rr.Body.String()) != `{"level":"`+tt.want.String()+`"}` {
+				t.Errorf("unexpected body %s", rr.Body.String())
+			}
+		})
+	}
+
+	rr := httptest.NewRecorder()
+	(&Server{}).handleLogLevel(rr, httptest.NewRequest("GET", "/api/operator/loglevel", nil))
+	if rr.Code != http.StatusNotImplemented {
+		t.Errorf("expected 501 without an adjustable level, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+	"github.com/migalsp/kubex-operator/internal/scaling"
+)
+
+// Replica counts recorded when scaling a namespace that has no ScalingConfig are kept in a
+// ConfigMap of the operator namespace, under one JSON-encoded key per namespace
+const manualScalingConfigMapName = "kubex-manual-scaling"
+
+// NamespaceScaleResult is returned by POST /api/namespaces/{ns}/scale
+type NamespaceScaleResult struct {
+	Namespace string `json:"namespace"`
+	Active    bool   `json:"active"`
+	// Ready is true once every workload reached the requested state
+	Ready bool `json:"ready"`
+	// ScalingConfig is the config whose sequence and exclusions were applied, if any
+	ScalingConfig    string           `json:"scalingConfig,omitempty"`
+	OriginalReplicas map[string]int32 `json:"originalReplicas,omitempty"`
+}
+
+// serveNamespaceScale scales a whole namespace up or down through the scaling engine.
+// When a ScalingConfig targets the namespace its sequence, exclusions and scale kinds are
+// used and the request is stored as its manual override, so the controller finishes the
+// remaining stages instead of undoing them. Namespaces of a ScalingGroup are refused.
+func (s *Server) serveNamespaceScale(w http.ResponseWriter, r *http.Request, nsName string) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Active *bool `json:"active"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Active == nil {
+		writeJSONError(w, "active is required", http.StatusBadRequest)
+		return
+	}
+	active := *req.Active
+
+	ctx := r.Context()
+	operatorNs := getOperatorNamespace()
+
+	if err := s.Client.Get(ctx, client.ObjectKey{Name: nsName}, &corev1.Namespace{}); err != nil {
+		writeJSONError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	groups := &finopsv1.ScalingGroupList{}
+	if err := s.Client.List(ctx, groups, client.InNamespace(operatorNs)); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if g := scaling.ManagingGroup(groups.Items, nsName); g != nil {
+		writeJSONError(w, "Namespace is managed by ScalingGroup "+g.Name+", use its manual override instead", http.StatusConflict)
+		return
+	}
+
+	configs := &finopsv1.ScalingConfigList{}
+	if err := s.Client.List(ctx, configs, client.InNamespace(operatorNs)); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var config *finopsv1.ScalingConfig
+	for i := range configs.Items {
+		if configs.Items[i].Spec.TargetNamespace == nsName {
+			config = &configs.Items[i]
+			break
+		}
+	}
+
+	engine := &scaling.Engine{Client: s.Client, Recorder: s.Recorder}
+	result := NamespaceScaleResult{Namespace: nsName, Active: active}
+
+	if config == nil {
+		originals, err := s.loadManualOriginals(r, nsName)
+		if err != nil {
+			writeJSONError(w, "Failed to read recorded replicas: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		originals, result.Ready, err = engine.ScaleTarget(ctx, nsName, active, nil, scaling.Exclusions{}, nil, false, originals, 0, 0, false)
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := s.storeManualOriginals(r, nsName, originals); err != nil {
+			writeJSONError(w, "Failed to store recorded replicas: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		result.OriginalReplicas = originals
+		s.audit(r, auditScaleNamespace, nsName, nsName, nil)
+	} else {
+		config.Spec.Active = &active
+		if err := s.Client.Update(ctx, config); err != nil {
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		exclusions := scaling.Exclusions{Names: config.Spec.Exclusions, Conditional: config.Spec.ConditionalExclusions}
+		originals, ready, err := engine.ScaleTarget(ctx, nsName, active, config.Spec.Sequence, exclusions, config.Spec.ScaleKinds, config.Spec.ScaleReplicaSets, config.Status.OriginalReplicas, config.Spec.ScaleDownReplicaPercent, config.Spec.ScaleUpStepPercent, false)
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// The controller may have reconciled the override in the meantime
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := s.Client.Get(ctx, client.ObjectKeyFromObject(config), config); err != nil {
+				return err
+			}
+			config.Status.OriginalReplicas = originals
+			return s.Client.Status().Update(ctx, config)
+		})
+		if err != nil {
+			writeJSONError(w, "Failed to store recorded replicas: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		result.Ready = ready
+		result.ScalingConfig = config.Name
+		result.OriginalReplicas = originals
+		s.audit(r, auditScaleNamespace, config.Namespace, config.Name, config)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// loadManualOriginals returns the replica counts recorded for a namespace without a
+// ScalingConfig, nil when none were recorded.
+func (s *Server) loadManualOriginals(r *http.Request, nsName string) (map[string]int32, error) {
+	cm, err := s.K8sClient.CoreV1().ConfigMaps(getOperatorNamespace()).Get(r.Context(), manualScalingConfigMapName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, ok := cm.Data[nsName]
+	if !ok {
+		return nil, nil
+	}
+	var originals map[string]int32
+	if err := json.Unmarshal([]byte(data), &originals); err != nil {
+		return nil, err
+	}
+	return originals, nil
+}
+
+// storeManualOriginals persists the replica counts recorded for a namespace, dropping its
+// key once nothing is left to restore.
+func (s *Server) storeManualOriginals(r *http.Request, nsName string, originals map[string]int32) error {
+	ctx := r.Context()
+	configMaps := s.K8sClient.CoreV1().ConfigMaps(getOperatorNamespace())
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := configMaps.Get(ctx, manualScalingConfigMapName, metav1.GetOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		exists := err == nil
+		if !exists {
+			if len(originals) == 0 {
+				return nil
+			}
+			cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: manualScalingConfigMapName, Namespace: getOperatorNamespace()}}
+		}
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		if len(originals) == 0 {
+			delete(cm.Data, nsName)
+		} else {
+			data, err := json.Marshal(originals)
+			if err != nil {
+				return err
+			}
+			cm.Data[nsName] = string(data)
+		}
+
+		if exists {
+			_, err = configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+		} else {
+			_, err = configMaps.Create(ctx, cm, metav1.CreateOptions{})
+		}
+		return err
+	})
+}
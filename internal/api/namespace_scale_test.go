@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+)
+
+func buildNamespaceScaleServer(objs ...client.Object) *Server {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(finopsv1.AddToScheme(scheme))
+
+	objs = append(objs, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}})
+	return &Server{
+		Client:    fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithStatusSubresource(&finopsv1.ScalingConfig{}).Build(),
+		K8sClient: fake.NewSimpleClientset(),
+	}
+}
+
+func scaleDeployment(name string, replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{Replicas: replicas, ReadyReplicas: replicas},
+	}
+}
+
+func scaleNamespace(t *testing.T, server *Server, body string) (int, NamespaceScaleResult) {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, httptest.NewRequest(http.MethodPost, "/api/namespaces/shop/scale", strings.NewReader(body)))
+	var result NamespaceScaleResult
+	if rr.Code == http.StatusOK {
+		json.NewDecoder(rr.Body).Decode(&result)
+	}
+	return rr.Code, result
+}
+
+func deploymentReplicas(t *testing.T, server *Server, name string) int32 {
+	t.Helper()
+	var d appsv1.Deployment
+	if err := server.Client.Get(context.Background(), client.ObjectKey{Name: name, Namespace: "shop"}, &d); err != nil {
+		t.Fatal(err)
+	}
+	return *d.Spec.Replicas
+}
+
+func TestNamespaceScaleWithoutConfig(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildNamespaceScaleServer(scaleDeployment("web", 3))
+
+	code, result := scaleNamespace(t, server, `{"active": false}`)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if got := deploymentReplicas(t, server, "web"); got != 0 {
+		t.Errorf("expected web scaled to 0, got %d", got)
+	}
+	if len(result.OriginalReplicas) != 1 || result.ScalingConfig != "" {
+		t.Errorf("expected the original replicas to be reported, got %+v", result)
+	}
+	cm, err := server.K8sClient.CoreV1().ConfigMaps("kubex").Get(context.Background(), manualScalingConfigMapName, metav1.GetOptions{})
+	if err != nil || cm.Data["shop"] == "" {
+		t.Fatalf("expected the original replicas to be persisted, got %v, %v", cm, err)
+	}
+
+	if code, _ := scaleNamespace(t, server, `{"active": true}`); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if got := deploymentReplicas(t, server, "web"); got != 3 {
+		t.Errorf("expected web restored to 3 replicas, got %d", got)
+	}
+}
+
+func TestNamespaceScaleWithConfig(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildNamespaceScaleServer(
+		scaleDeployment("web", 2),
+		scaleDeployment("keep", 1),
+		&finopsv1.ScalingConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "kubex"},
+			Spec:       finopsv1.ScalingConfigSpec{TargetNamespace: "shop", Exclusions: []string{"keep"}},
+		},
+	)
+
+	code, result := scaleNamespace(t, server, `{"active": false}`)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if result.ScalingConfig != "shop" {
+		t.Errorf("expected the ScalingConfig to be reported, got %+v", result)
+	}
+	if got := deploymentReplicas(t, server, "web"); got != 0 {
+		t.Errorf("expected web scaled to 0, got %d", got)
+	}
+	if got := deploymentReplicas(t, server, "keep"); got != 1 {
+		t.Errorf("expected the excluded workload to be left alone, got %d replicas", got)
+	}
+
+	var config finopsv1.ScalingConfig
+	server.Client.Get(context.Background(), client.ObjectKey{Name: "shop", Namespace: "kubex"}, &config)
+	if config.Spec.Active == nil || *config.Spec.Active {
+		t.Errorf("expected the scale to be stored as the manual override, got %v", config.Spec.Active)
+	}
+	if len(config.Status.OriginalReplicas) != 1 {
+		t.Errorf("expected the original replicas in the config status, got %v", config.Status.OriginalReplicas)
+	}
+}
+
+func TestNamespaceScaleRejected(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildNamespaceScaleServer(&finopsv1.ScalingGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "night", Namespace: "kubex"},
+		Spec:       finopsv1.ScalingGroupSpec{Namespaces: []string{"shop"}},
+	})
+
+	if code, _ := scaleNamespace(t, server, `{"active": false}`); code != http.StatusConflict {
+		t.Errorf("expected 409 for a namespace of a ScalingGroup, got %d", code)
+	}
+	if code, _ := scaleNamespace(t, server, `{}`); code != http.StatusBadRequest {
+		t.Errorf("expected 400 without active, got %d", code)
+	}
+}
@@ -0,0 +1,313 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// openAPIPathParams are the values substituted for the path parameters of the spec, naming
+// the objects created by openAPIFixtures
+var openAPIPathParams = map[string]string{
+	"{ns}":       "test-ns",
+	"{provider}": "aws",
+	"{type}":     "rds",
+}
+
+func loadOpenAPISpec(t *testing.T) map[string]any {
+	t.Helper()
+	var spec map[string]any
+	if err := yaml.Unmarshal(openapiSpec, &spec); err != nil {
+		t.Fatalf("invalid openapi.yaml: %v", err)
+	}
+	return spec
+}
+
+// openAPIFixtures returns a server holding one object of each kind the API serves, so
+// that successful responses carry most of their fields.
+func openAPIFixtures() *Server {
+	server := buildMockServerWithK8s()
+	server.MetricsClient = webMetricsClient()
+	ctx := context.Background()
+	active := true
+	now := metav1.Now()
+	resources := finopsv1.ResourceValues{CPURequest: "1", CPULimit: "2", MemoryRequest: "1Gi", MemoryLimit: "2Gi"}
+	optimized := []finopsv1.WorkloadOptimization{{
+		Name: "web", Kind: "Deployment", Original: resources, Optimized: resources,
+		Containers: []finopsv1.ContainerOptimization{{Name: "app", Original: resources, Optimized: resources, Clamped: []string{"cpu"}}},
+	}}
+
+	server.Client.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns"}})
+	server.Client.Create(ctx, &finopsv1.NamespaceFinOps{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Namespace: "kubex"},
+		Spec:       finopsv1.NamespaceFinOpsSpec{TargetNamespace: "test-ns"},
+		Status: finopsv1.NamespaceFinOpsStatus{
+			History: []finopsv1.MetricDataPoint{{
+				Timestamp: now,
+				CPU:       finopsv1.ResourceMetrics{Usage: "10m", Requests: "1", Limits: "2"},
+				Memory:    finopsv1.ResourceMetrics{Usage: "10Mi", Requests: "1Gi", Limits: "2Gi"},
+			}},
+		},
+	})
+	server.Client.Create(ctx, &finopsv1.NamespaceOptimization{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Namespace: "kubex"},
+		Spec:       finopsv1.NamespaceOptimizationSpec{TargetNamespace: "test-ns"},
+		Status: finopsv1.NamespaceOptimizationStatus{
+			Active: true, OptimizedAt: now, Strategy: strategyAverage, Workloads: optimized,
+			History: []finopsv1.OptimizationSnapshot{{OptimizedAt: now, Strategy: strategyAverage, Workloads: optimized}},
+		},
+	})
+	schedules := []finopsv1.ScalingSchedule{{Days: []int{1, 2, 3}, StartTime: "08:00", EndTime: "20:00", Timezone: "UTC"}}
+	server.Client.Create(ctx, &finopsv1.ScalingGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "g1", Namespace: "kubex"},
+		Spec: finopsv1.ScalingGroupSpec{
+			Category: "dev", Namespaces: []string{"test-ns"}, Active: &active, Schedules: schedules,
+			Sequence: []string{"test-ns"}, StageTimeoutSeconds: 60, ScaleDownReplicaPercent: 50, ScaleUpStepPercent: 25, ScaleKinds: []string{"Deployment"},
+		},
+		Status: finopsv1.ScalingGroupStatus{
+			Phase: "ScaledUp", LastAction: now, OriginalReplicas: map[string]int32{"test-ns/Deployment/web": 1},
+			ManagedNamespaces: []string{"test-ns"}, NamespacesReady: 1, NamespacesTotal: 1, ReadyNamespaces: []string{"test-ns"},
+			CurrentStage: 1, TotalStages: 1, NextTransition: &now, NextTransitionState: "ScaledDown",
+		},
+	})
+	server.Client.Create(ctx, &finopsv1.ScalingConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "c1", Namespace: "kubex"},
+		Spec: finopsv1.ScalingConfigSpec{
+			TargetNamespace: "test-ns", Active: &active, Schedules: schedules, Sequence: []string{"web"},
+			Exclusions:            []string{"db"},
+			ConditionalExclusions: []finopsv1.ConditionalExclusion{{Names: []string{"batch"}, Schedules: schedules}},
+			StageTimeoutSeconds:   60, ScaleDownReplicaPercent: 50, ScaleUpStepPercent: 25, ScaleKinds: []string{"Deployment"},
+		},
+		Status: finopsv1.ScalingConfigStatus{
+			Phase: "ScaledUp", LastAction: now, OriginalReplicas: map[string]int32{"Deployment/web": 1},
+			NextTransition: &now, NextTransitionState: "ScaledDown",
+		},
+	})
+	server.Client.Create(ctx, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-ns"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:      "app",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+		}}}}},
+	})
+	return server
+}
+
+// TestOpenAPIPathsHaveHandlers sends a request for every operation of openapi.yaml and fails
+// when none of the handlers serves it, so that the Swagger UI never advertises an endpoint
+// that does not exist. Successful JSON responses must also match the documented schema.
+func TestOpenAPIPathsHaveHandlers(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	spec := loadOpenAPISpec(t)
+	paths, _ := spec["paths"].(map[string]any)
+	if len(paths) == 0 {
+		t.Fatal("openapi.yaml documents no paths")
+	}
+
+	for path, item := range paths {
+		for method, op := range item.(map[string]any) {
+			if !slices.Contains([]string{"get", "post", "put", "delete"}, method) {
+				continue
+			}
+			method := strings.ToUpper(method)
+			t.Run(method+" "+path, func(t *testing.T) {
+				mux, err := openAPIFixtures().routes()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				url := path
+				for param, value := range openAPIPathParams {
+					url = strings.ReplaceAll(url, param, value)
+				}
+				switch {
+				case strings.HasPrefix(path, "/api/scaling/groups/"):
+					url = strings.ReplaceAll(url, "{name}", "g1")
+				case strings.HasPrefix(path, "/api/scaling/configs/"):
+					url = strings.ReplaceAll(url, "{name}", "c1")
+				default:
+					url = strings.ReplaceAll(url, "{name}", "web")
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				req := httptest.NewRequest(method, url, strings.NewReader("{}")).WithContext(ctx)
+				if _, pattern := mux.Handler(req); pattern == "/" {
+					t.Fatalf("%s is served by the UI, no API handler is registered", url)
+				}
+				rr := httptest.NewRecorder()
+				mux.ServeHTTP(rr, req)
+
+				body := rr.Body.String()
+				if rr.Code == http.StatusMethodNotAllowed || strings.Contains(body, "Unknown namespace action") || strings.Contains(body, "Invalid path") {
+					t.Fatalf("%s %s is not handled: %d %s", method, url, rr.Code, body)
+				}
+
+				schema := responseSchema(op.(map[string]any), rr.Code)
+				if schema == nil || !strings.HasPrefix(rr.Header().Get("Content-Type"), "application/json") {
+					return
+				}
+				var value any
+				if err := json.Unmarshal(rr.Body.Bytes(), &value); err != nil {
+					t.Fatalf("invalid JSON response: %v", err)
+				}
+				for _, problem := range matchSchema(spec, schema, value, "response") {
+					t.Error(problem)
+				}
+			})
+		}
+	}
+}
+
+// responseSchema returns the JSON schema documented for a response code of an operation
+func responseSchema(op map[string]any, code int) map[string]any {
+	responses, _ := op["responses"].(map[string]any)
+	response, _ := responses[fmt.Sprint(code)].(map[string]any)
+	content, _ := response["content"].(map[string]any)
+	media, _ := content["application/json"].(map[string]any)
+	schema, _ := media["schema"].(map[string]any)
+	return schema
+}
+
+// resolveRef follows a local $ref such as #/components/schemas/Error
+func resolveRef(spec map[string]any, ref string) map[string]any {
+	var node any = spec
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		m, _ := node.(map[string]any)
+		node = m[part]
+	}
+	schema, _ := node.(map[string]any)
+	return schema
+}
+
+// matchSchema lists where a decoded JSON value departs from a schema. Objects may only
+// carry documented properties unless the schema allows additional ones, so that a field
+// added to a handler without documenting it is caught.
+func matchSchema(spec, schema map[string]any, value any, at string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved := resolveRef(spec, ref)
+		if resolved == nil {
+			return []string{fmt.Sprintf("%s: unresolved $ref %s", at, ref)}
+		}
+		return matchSchema(spec, resolved, value, at)
+	}
+	if value == nil {
+		return nil
+	}
+	if alternatives, ok := schema["oneOf"].([]any); ok {
+		var problems []string
+		for _, alt := range alternatives {
+			p := matchSchema(spec, alt.(map[string]any), value, at)
+			if len(p) == 0 {
+				return nil
+			}
+			problems = append(problems, p...)
+		}
+		return append([]string{fmt.Sprintf("%s: matches none of the oneOf schemas", at)}, problems...)
+	}
+	if parts, ok := schema["allOf"].([]any); ok {
+		merged := map[string]any{"type": "object", "properties": map[string]any{}}
+		for _, part := range parts {
+			p := part.(map[string]any)
+			if ref, ok := p["$ref"].(string); ok {
+				p = resolveRef(spec, ref)
+			}
+			props, _ := p["properties"].(map[string]any)
+			for k, v := range props {
+				merged["properties"].(map[string]any)[k] = v
+			}
+		}
+		return matchSchema(spec, merged, value, at)
+	}
+
+	switch schema["type"] {
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an array, got %T", at, value)}
+		}
+		itemSchema, _ := schema["items"].(map[string]any)
+		var problems []string
+		for i, item := range items {
+			if itemSchema != nil {
+				problems = append(problems, matchSchema(spec, itemSchema, item, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+		return problems
+	case "string":
+		if _, ok := value.(string); !ok {
+			return []string{fmt.Sprintf("%s: expected a string, got %T", at, value)}
+		}
+	case "integer", "number":
+		if _, ok := value.(float64); !ok {
+			return []string{fmt.Sprintf("%s: expected a number, got %T", at, value)}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{fmt.Sprintf("%s: expected a boolean, got %T", at, value)}
+		}
+	}
+
+	props, hasProps := schema["properties"].(map[string]any)
+	if schema["type"] != "object" && !hasProps {
+		return nil
+	}
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return []string{fmt.Sprintf("%s: expected an object, got %T", at, value)}
+	}
+	additional := schema["additionalProperties"]
+	if !hasProps && additional == nil {
+		// A free-form object
+		return nil
+	}
+	var problems []string
+	for key, v := range obj {
+		if propSchema, ok := props[key].(map[string]any); ok {
+			problems = append(problems, matchSchema(spec, propSchema, v, at+"."+key)...)
+			continue
+		}
+		switch a := additional.(type) {
+		case map[string]any:
+			problems = append(problems, matchSchema(spec, a, v, at+"."+key)...)
+		case bool:
+			if !a {
+				problems = append(problems, fmt.Sprintf("%s: undocumented field %q", at, key))
+			}
+		default:
+			problems = append(problems, fmt.Sprintf("%s: undocumented field %q", at, key))
+		}
+	}
+	return problems
+}
+
+func TestMatchSchema(t *testing.T) {
+	spec := map[string]any{"components": map[string]any{"schemas": map[string]any{
+		"Item": map[string]any{"type": "object", "properties": map[string]any{"name": map[string]any{"type": "string"}}},
+	}}}
+	schema := map[string]any{"type": "array", "items": map[string]any{"$ref": "#/components/schemas/Item"}}
+
+	if problems := matchSchema(spec, schema, []any{map[string]any{"name": "a"}}, "r"); len(problems) != 0 {
+		t.Errorf("expected a match, got %v", problems)
+	}
+	problems := matchSchema(spec, schema, []any{map[string]any{"name": 1, "extra": true}}, "r")
+	if len(problems) != 2 {
+		t.Errorf("expected a type mismatch and an undocumented field, got %v", problems)
+	}
+}
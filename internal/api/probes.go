@@ -0,0 +1,38 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// readinessTimeout bounds the cache sync wait of a single /readyz request
+const readinessTimeout = time.Second
+
+// handleHealthz reports that the API server is serving. It is unauthenticated so that
+// it can back a Kubernetes liveness probe.
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte("ok"))
+}
+
+// handleReadyz returns 503 until the manager cache has synced and the apiserver answers
+// a discovery request.
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if s.Cache != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+		if !s.Cache.WaitForCacheSync(ctx) {
+			writeJSONError(w, "Informer cache has not synced yet", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	if _, err := s.K8sClient.Discovery().ServerVersion(); err != nil {
+		writeJSONError(w, "Kubernetes API server unreachable: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte("ok"))
+}
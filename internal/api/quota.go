@@ -0,0 +1,199 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=core,resources=limitranges;resourcequotas,verbs=get;list;watch
+
+// resourceBounds are the LimitRange constraints on one resource of a container, zero
+// meaning unconstrained. CPU is in cores and memory in bytes.
+type resourceBounds struct {
+	min, max, maxRatio float64
+}
+
+// containerBounds are the combined Container LimitRange constraints of a namespace
+type containerBounds struct {
+	cpu, memory resourceBounds
+}
+
+// namespaceContainerBounds combines every Container LimitRange of a namespace into the
+// tightest bounds: the highest min, the lowest max and the lowest limit/request ratio.
+func (s *Server) namespaceContainerBounds(ctx context.Context, nsName string) (containerBounds, error) {
+	var bounds containerBounds
+	limitRanges := &corev1.LimitRangeList{}
+	if err := s.Client.List(ctx, limitRanges, client.InNamespace(nsName)); err != nil {
+		return bounds, err
+	}
+	for _, lr := range limitRanges.Items {
+		for _, item := range lr.Spec.Limits {
+			if item.Type != corev1.LimitTypeContainer {
+				continue
+			}
+			bounds.cpu.merge(item, corev1.ResourceCPU)
+			bounds.memory.merge(item, corev1.ResourceMemory)
+		}
+	}
+	return bounds, nil
+}
+
+func (b *resourceBounds) merge(item corev1.LimitRangeItem, name corev1.ResourceName) {
+	if q, ok := item.Min[name]; ok {
+		b.min = math.Max(b.min, q.AsApproximateFloat64())
+	}
+	if q, ok := item.Max[name]; ok && (b.max == 0 || q.AsApproximateFloat64() < b.max) {
+		b.max = q.AsApproximateFloat64()
+	}
+	if q, ok := item.MaxLimitRequestRatio[name]; ok && (b.maxRatio == 0 || q.AsApproximateFloat64() < b.maxRatio) {
+		b.maxRatio = q.AsApproximateFloat64()
+	}
+}
+
+// clamp moves a computed request and limit into the bounds and describes every change.
+// Zero values are left alone since they stand for an unset request or limit. Raised
+// values are rounded up to unit, the precision the optimizer writes them with.
+func (b resourceBounds) clamp(resourceName string, req, lim *float64, unit float64, format func(float64) string) []string {
+	var notes []string
+	for _, v := range []struct {
+		kind  string
+		value *float64
+	}{{"request", req}, {"limit", lim}} {
+		if *v.value == 0 {
+			continue
+		}
+		if b.min > 0 && *v.value < b.min {
+			*v.value = math.Ceil(b.min/unit-1e-9) * unit
+			notes = append(notes, fmt.Sprintf("%s %s raised to LimitRange min %s", resourceName, v.kind, format(b.min)))
+		}
+		if b.max > 0 && *v.value > b.max {
+			*v.value = b.max
+			notes = append(notes, fmt.Sprintf("%s %s lowered to LimitRange max %s", resourceName, v.kind, format(b.max)))
+		}
+	}
+	if b.maxRatio > 0 && *req > 0 && *lim > *req*b.maxRatio {
+		*lim = *req * b.maxRatio
+		notes = append(notes, fmt.Sprintf("%s limit lowered to %s by LimitRange maxLimitRequestRatio %g", resourceName, format(*lim), b.maxRatio))
+	}
+	return notes
+}
+
+// clampQuantities is clamp for quantities, converting back only the values the bounds
+// changed so the others keep their exact value.
+func (b resourceBounds) clampQuantities(resourceName string, req, lim *resource.Quantity, unit float64, format func(float64) string, quantity func(float64) resource.Quantity) []string {
+	origReq, origLim := req.AsApproximateFloat64(), lim.AsApproximateFloat64()
+	reqValue, limValue := origReq, origLim
+	notes := b.clamp(resourceName, &reqValue, &limValue, unit, format)
+	if reqValue != origReq {
+		*req = quantity(reqValue)
+	}
+	if limValue != origLim {
+		*lim = quantity(limValue)
+	}
+	return notes
+}
+
+func formatCPU(cores float64) string {
+	return resource.NewMilliQuantity(int64(math.Round(cores*1000)), resource.DecimalSI).String()
+}
+
+func formatMemory(bytes float64) string {
+	return resource.NewQuantity(int64(bytes), resource.BinarySI).String()
+}
+
+// quotaUsage returns what the pods of a workload count against a ResourceQuota. Init
+// containers run one at a time before the others, so a pod counts the largest of its init
+// containers or the sum of its containers, whichever is higher.
+func quotaUsage(spec *corev1.PodSpec, replicas int32) corev1.ResourceList {
+	usage := containersUsage(spec.Containers, func(total, q *resource.Quantity) { total.Add(*q) })
+	initUsage := containersUsage(spec.InitContainers, func(total, q *resource.Quantity) {
+		if q.Cmp(*total) > 0 {
+			*total = q.DeepCopy()
+		}
+	})
+	for name, q := range initUsage {
+		if total, ok := usage[name]; !ok || q.Cmp(total) > 0 {
+			usage[name] = q
+		}
+	}
+	for name, q := range usage {
+		q.Mul(int64(replicas))
+		usage[name] = q
+	}
+	return usage
+}
+
+// containersUsage combines the requests and limits of containers per quota resource
+func containersUsage(containers []corev1.Container, combine func(total, q *resource.Quantity)) corev1.ResourceList {
+	usage := corev1.ResourceList{}
+	add := func(name corev1.ResourceName, list corev1.ResourceList, resourceName corev1.ResourceName) {
+		q, ok := list[resourceName]
+		if !ok {
+			return
+		}
+		total := usage[name]
+		combine(&total, &q)
+		usage[name] = total
+	}
+	for _, c := range containers {
+		add(corev1.ResourceRequestsCPU, c.Resources.Requests, corev1.ResourceCPU)
+		add(corev1.ResourceRequestsMemory, c.Resources.Requests, corev1.ResourceMemory)
+		add(corev1.ResourceLimitsCPU, c.Resources.Limits, corev1.ResourceCPU)
+		add(corev1.ResourceLimitsMemory, c.Resources.Limits, corev1.ResourceMemory)
+	}
+	return usage
+}
+
+// addQuotaDelta adds the change from before to after into delta
+func addQuotaDelta(delta, before, after corev1.ResourceList) {
+	for name, q := range after {
+		total := delta[name]
+		total.Add(q)
+		delta[name] = total
+	}
+	for name, q := range before {
+		total := delta[name]
+		total.Sub(q)
+		delta[name] = total
+	}
+}
+
+// checkQuota verifies that every ResourceQuota of a namespace can absorb the aggregate
+// change of an optimization, so that it is rejected as a whole rather than leaving some
+// workloads unable to roll out their new pods. It returns why the change does not fit,
+// or an empty string when it does.
+func (s *Server) checkQuota(ctx context.Context, nsName string, delta corev1.ResourceList) (string, error) {
+	quotas := &corev1.ResourceQuotaList{}
+	if err := s.Client.List(ctx, quotas, client.InNamespace(nsName)); err != nil {
+		return "", err
+	}
+	for _, quota := range quotas.Items {
+		for name, hard := range quota.Spec.Hard {
+			// cpu and memory are shorthands for the requests quotas
+			deltaName := name
+			switch name {
+			case corev1.ResourceCPU:
+				deltaName = corev1.ResourceRequestsCPU
+			case corev1.ResourceMemory:
+				deltaName = corev1.ResourceRequestsMemory
+			}
+			d, ok := delta[deltaName]
+			if !ok || d.Sign() <= 0 {
+				continue
+			}
+			used := quota.Status.Used[name]
+			total := used.DeepCopy()
+			total.Add(d)
+			if total.Cmp(hard) > 0 {
+				return fmt.Sprintf("Optimization needs %s more %s, exceeding ResourceQuota %s (hard %s, used %s)",
+					d.String(), name, quota.Name, hard.String(), used.String()), nil
+			}
+		}
+	}
+	return "", nil
+}
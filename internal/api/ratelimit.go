@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Failed logins allowed per client address within loginFailureWindow
+const (
+	loginMaxFailures   = 5
+	loginFailureWindow = time.Minute
+)
+
+// loginLimiter counts failed logins per client address over a fixed window.
+type loginLimiter struct {
+	mu       sync.Mutex
+	failures map[string]*loginFailures
+	now      func() time.Time
+}
+
+type loginFailures struct {
+	count int
+	start time.Time
+}
+
+var loginAttempts = newLoginLimiter()
+
+func newLoginLimiter() *loginLimiter {
+	return &loginLimiter{failures: make(map[string]*loginFailures), now: time.Now}
+}
+
+// retryAfter returns how long a client has to wait before trying again, or zero when it
+// may attempt a login now. Expired entries are dropped on the way.
+func (l *loginLimiter) retryAfter(addr string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for a, f := range l.failures {
+		if now.Sub(f.start) >= loginFailureWindow {
+			delete(l.failures, a)
+		}
+	}
+
+	f, ok := l.failures[addr]
+	if !ok || f.count < loginMaxFailures {
+		return 0
+	}
+	return loginFailureWindow - now.Sub(f.start)
+}
+
+func (l *loginLimiter) fail(addr string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if f, ok := l.failures[addr]; ok {
+		f.count++
+		return
+	}
+	l.failures[addr] = &loginFailures{count: 1, start: l.now()}
+}
+
+func (l *loginLimiter) reset(addr string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.failures, addr)
+}
+
+// clientAddr is the address failed logins are counted against. X-Forwarded-For is
+// ignored since any client can set it to dodge the limit.
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+)
+
+// resolveNamespaceFinOps returns the NamespaceFinOps tracking a namespace: the one named
+// after it in the operator namespace, otherwise the first one whose spec.targetNamespace is
+// the namespace. Entries being deleted are ignored, a NotFound error is returned when none
+// is left.
+func (s *Server) resolveNamespaceFinOps(ctx context.Context, nsName string) (*finopsv1.NamespaceFinOps, error) {
+	var nsFinOps finopsv1.NamespaceFinOps
+	err := s.Client.Get(ctx, client.ObjectKey{Name: nsName, Namespace: getOperatorNamespace()}, &nsFinOps)
+	if err == nil && nsFinOps.DeletionTimestamp.IsZero() {
+		return &nsFinOps, nil
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	var list finopsv1.NamespaceFinOpsList
+	if err := s.Client.List(ctx, &list); err != nil {
+		return nil, err
+	}
+	for i, item := range list.Items {
+		if item.Spec.TargetNamespace == nsName && item.DeletionTimestamp.IsZero() {
+			return &list.Items[i], nil
+		}
+	}
+	return nil, errors.NewNotFound(finopsv1.GroupVersion.WithResource("namespacefinops").GroupResource(), nsName)
+}
+
+// resolveNamespaceOptimization returns the NamespaceOptimization of a namespace the same way,
+// so that a record declared through GitOps under another name is found too.
+func (s *Server) resolveNamespaceOptimization(ctx context.Context, nsName string) (*finopsv1.NamespaceOptimization, error) {
+	var opt finopsv1.NamespaceOptimization
+	err := s.Client.Get(ctx, client.ObjectKey{Name: nsName, Namespace: getOperatorNamespace()}, &opt)
+	if err == nil && opt.DeletionTimestamp.IsZero() {
+		return &opt, nil
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	var list finopsv1.NamespaceOptimizationList
+	if err := s.Client.List(ctx, &list); err != nil {
+		return nil, err
+	}
+	for i, item := range list.Items {
+		if item.Spec.TargetNamespace == nsName && item.DeletionTimestamp.IsZero() {
+			return &list.Items[i], nil
+		}
+	}
+	return nil, errors.NewNotFound(finopsv1.GroupVersion.WithResource("namespaceoptimizations").GroupResource(), nsName)
+}
+
+// watchedNamespaceFinOps drops the NamespaceFinOps of namespaces outside WatchNamespaces,
+// left over from before the operator was restricted
+func (s *Server) watchedNamespaceFinOps(items []finopsv1.NamespaceFinOps) []finopsv1.NamespaceFinOps {
+	if !s.WatchNamespaces.Restricted() {
+		return items
+	}
+	watched := make([]finopsv1.NamespaceFinOps, 0, len(items))
+	for _, item := range items {
+		if s.WatchNamespaces.Allows(item.Spec.TargetNamespace) {
+			watched = append(watched, item)
+		}
+	}
+	return watched
+}
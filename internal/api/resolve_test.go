@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+	"github.com/migalsp/kubex-operator/internal/watchns"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestNamespaceHandlersResolveByTargetNamespace(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServerWithK8s()
+	ctx := context.Background()
+	server.Client.Create(ctx, &finopsv1.NamespaceFinOps{
+		ObjectMeta: metav1.ObjectMeta{Name: "backend-finops", Namespace: "kubex"},
+		Spec:       finopsv1.NamespaceFinOpsSpec{TargetNamespace: "staging-backend"},
+		Status: finopsv1.NamespaceFinOpsStatus{
+			History: []finopsv1.MetricDataPoint{{Timestamp: metav1.Now(), CPU: finopsv1.ResourceMetrics{Usage: "10m"}}},
+		},
+	})
+	server.Client.Create(ctx, &finopsv1.NamespaceOptimization{
+		ObjectMeta: metav1.ObjectMeta{Name: "backend-optimization", Namespace: "kubex"},
+		Spec:       finopsv1.NamespaceOptimizationSpec{TargetNamespace: "staging-backend"},
+		Status: finopsv1.NamespaceOptimizationStatus{
+			Active:  true,
+			History: []finopsv1.OptimizationSnapshot{{OptimizedAt: metav1.Now(), Strategy: strategyAverage}},
+		},
+	})
+
+	finOps, err := server.resolveNamespaceFinOps(ctx, "staging-backend")
+	if err != nil || finOps.Name != "backend-finops" {
+		t.Fatalf("expected backend-finops, got %v (%v)", finOps, err)
+	}
+	if _, err := server.resolveNamespaceFinOps(ctx, "other"); err == nil {
+		t.Error("expected NotFound for an untracked namespace")
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		server.handleNamespaceRouting(rr, httptest.NewRequest("GET", path, nil))
+		return rr
+	}
+	if rr := get("/api/namespaces/staging-backend/history"); rr.Code != http.StatusOK {
+		t.Errorf("history: expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr := get("/api/namespaces/staging-backend/optimization")
+	var status finopsv1.NamespaceOptimizationStatus
+	if err := json.NewDecoder(rr.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if !status.Active {
+		t.Errorf("expected the GitOps optimization record, got %+v", status)
+	}
+
+	var history []finopsv1.OptimizationSnapshot
+	if err := json.NewDecoder(get("/api/namespaces/staging-backend/optimization/history").Body).Decode(&history); err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 {
+		t.Errorf("expected one recorded run, got %d", len(history))
+	}
+
+	rr = httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, httptest.NewRequest("POST", "/api/namespaces/staging-backend/revert", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("revert: expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var opt finopsv1.NamespaceOptimization
+	if err := server.Client.Get(ctx, client.ObjectKey{Name: "backend-optimization", Namespace: "kubex"}, &opt); err != nil {
+		t.Fatal(err)
+	}
+	if opt.Status.Active {
+		t.Error("expected the revert to clear the GitOps optimization record")
+	}
+}
+
+func TestWatchNamespaces(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServerWithK8s()
+	server.WatchNamespaces = watchns.Namespaces{"team-a"}
+	ctx := context.Background()
+	for _, name := range []string{"team-a", "team-b"} {
+		server.Client.Create(ctx, &finopsv1.NamespaceFinOps{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kubex"},
+			Spec:       finopsv1.NamespaceFinOpsSpec{TargetNamespace: name},
+		})
+	}
+
+	rr := httptest.NewRecorder()
+	server.handleNamespaces(rr, httptest.NewRequest("GET", "/api/namespaces", nil))
+	var items []finopsv1.NamespaceFinOps
+	if err := json.NewDecoder(rr.Body).Decode(&items); err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Spec.TargetNamespace != "team-a" {
+		t.Errorf("expected only team-a to be listed, got %+v", items)
+	}
+
+	for path, code := range map[string]int{
+		"/api/namespaces/team-a/history": http.StatusOK,
+		"/api/namespaces/team-b/history": http.StatusForbidden,
+	} {
+		rr := httptest.NewRecorder()
+		server.handleNamespaceRouting(rr, httptest.NewRequest("GET", path, nil))
+		if rr.Code != code {
+			t.Errorf("%s: expected %d, got %d: %s", path, code, rr.Code, rr.Body.String())
+		}
+	}
+}
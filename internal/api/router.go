@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+// pathSegments returns the segments of an URL path following prefix. A trailing slash is
+// ignored; ok is false when any other segment is empty, as in "/api/namespaces//pods".
+func pathSegments(path, prefix string) (segments []string, ok bool) {
+	rest := strings.TrimSuffix(strings.TrimPrefix(path, prefix), "/")
+	if rest == "" {
+		return nil, true
+	}
+	segments = strings.Split(rest, "/")
+	for _, seg := range segments {
+		if seg == "" {
+			return nil, false
+		}
+	}
+	return segments, true
+}
+
+// namespaceRoute resolves the handler of /api/namespaces/{ns}/{action}/{rest...}.
+func (s *Server) namespaceRoute(r *http.Request, action string, rest []string) (func(http.ResponseWriter, *http.Request, string), bool) {
+	switch len(rest) {
+	case 0:
+		switch action {
+		case "history":
+			return s.serveHistory, true
+		case "history.csv":
+			return s.serveHistoryCSV, true
+		case "pods":
+			return s.servePods, true
+		case "workloads":
+			if r.Method == http.MethodPut {
+				return s.serveWorkloadsScale, true
+			}
+			return s.serveWorkloads, true
+		case "optimize":
+			return s.handleNamespaceOptimize, true
+		case "revert":
+			return s.handleNamespaceRevert, true
+		case "optimization":
+			return s.handleNamespaceOptimizationInfo, true
+		case "scale":
+			return s.serveNamespaceScale, true
+		}
+	case 1:
+		if action == "optimization" && rest[0] == "history" {
+			return s.handleNamespaceOptimizationHistory, true
+		}
+		if action == "optimization" && rest[0] == "drift" {
+			return s.handleNamespaceOptimizationDrift, true
+		}
+		if action == "optimize" && rest[0] == "apply" {
+			return s.handleNamespaceOptimizeApply, true
+		}
+		if action != "workloads" {
+			return nil, false
+		}
+		// A workload named "metrics" can still be scaled, only GET serves the aggregate
+		if rest[0] == "metrics" && r.Method == http.MethodGet {
+			return s.serveWorkloadMetrics, true
+		}
+		return func(w http.ResponseWriter, r *http.Request, nsName string) {
+			s.serveWorkloadAction(w, r, nsName, rest[0])
+		}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+)
+
+// NamespaceSavings are the requests reclaimed by the active optimization of a namespace
+type NamespaceSavings struct {
+	Namespace              string      `json:"namespace"`
+	OptimizedAt            metav1.Time `json:"optimizedAt"`
+	Workloads              int         `json:"workloads"`
+	CPUReclaimedMillicores int64       `json:"cpuReclaimedMillicores"`
+	MemoryReclaimedMiB     float64     `json:"memoryReclaimedMiB"`
+}
+
+// OptimizationSummary is returned by GET /api/optimization/summary. Reclaimed values are
+// the requests removed from the pod templates, negative when optimizations raised them.
+type OptimizationSummary struct {
+	Namespaces             []NamespaceSavings `json:"namespaces"`
+	Workloads              int                `json:"workloads"`
+	CPUReclaimedMillicores int64              `json:"cpuReclaimedMillicores"`
+	MemoryReclaimedMiB     float64            `json:"memoryReclaimedMiB"`
+}
+
+// handleOptimizationSummary aggregates the savings of every active NamespaceOptimization.
+func (s *Server) handleOptimizationSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	opts := &finopsv1.NamespaceOptimizationList{}
+	if err := s.Client.List(r.Context(), opts, client.InNamespace(getOperatorNamespace())); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	summary := OptimizationSummary{Namespaces: []NamespaceSavings{}}
+	var totalMem int64
+	for _, opt := range opts.Items {
+		if !opt.Status.Active {
+			continue
+		}
+		ns := NamespaceSavings{
+			Namespace:   opt.Spec.TargetNamespace,
+			OptimizedAt: opt.Status.OptimizedAt,
+			Workloads:   len(opt.Status.Workloads),
+		}
+		if ns.Namespace == "" {
+			ns.Namespace = opt.Name
+		}
+		if !s.WatchNamespaces.Allows(ns.Namespace) {
+			continue
+		}
+		var nsMem int64
+		for _, wo := range opt.Status.Workloads {
+			cpu := reclaimed(wo.Original.CPURequest, wo.Optimized.CPURequest)
+			mem := reclaimed(wo.Original.MemoryRequest, wo.Optimized.MemoryRequest)
+			ns.CPUReclaimedMillicores += cpu.MilliValue()
+			nsMem += mem.Value()
+		}
+		ns.MemoryReclaimedMiB = toMiB(nsMem)
+
+		summary.Namespaces = append(summary.Namespaces, ns)
+		summary.Workloads += ns.Workloads
+		summary.CPUReclaimedMillicores += ns.CPUReclaimedMillicores
+		totalMem += nsMem
+	}
+	summary.MemoryReclaimedMiB = toMiB(totalMem)
+	sort.Slice(summary.Namespaces, func(i, j int) bool {
+		return summary.Namespaces[i].Namespace < summary.Namespaces[j].Namespace
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// reclaimed returns original minus optimized. Values that were not recorded, such as the
+// dimension a CPU-only optimization left alone, or do not parse count as nothing reclaimed.
+func reclaimed(original, optimized string) resource.Quantity {
+	orig, err := resource.ParseQuantity(original)
+	if err != nil {
+		return resource.Quantity{}
+	}
+	opt, err := resource.ParseQuantity(optimized)
+	if err != nil {
+		return resource.Quantity{}
+	}
+	orig.Sub(opt)
+	return orig
+}
+
+// toMiB converts bytes to MiB rounded to 2 decimals
+func toMiB(bytes int64) float64 {
+	return math.Round(float64(bytes)/(1<<20)*100) / 100
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+)
+
+func TestHandleOptimizationSummary(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServerWithK8s()
+	ctx := context.Background()
+
+	optimization := func(ns string, active bool, workloads ...finopsv1.WorkloadOptimization) {
+		opt := &finopsv1.NamespaceOptimization{
+			ObjectMeta: metav1.ObjectMeta{Name: ns, Namespace: "kubex"},
+			Spec:       finopsv1.NamespaceOptimizationSpec{TargetNamespace: ns},
+		}
+		server.Client.Create(ctx, opt)
+		opt.Status = finopsv1.NamespaceOptimizationStatus{Active: active, Workloads: workloads}
+		server.Client.Status().Update(ctx, opt)
+	}
+	optimization("shop", true,
+		finopsv1.WorkloadOptimization{
+			Name:      "web",
+			Original:  finopsv1.ResourceValues{CPURequest: "1", MemoryRequest: "1Gi"},
+			Optimized: finopsv1.ResourceValues{CPURequest: "250m", MemoryRequest: "256Mi"},
+		},
+		// A CPU-only optimization records no memory
+		finopsv1.WorkloadOptimization{
+			Name:      "worker",
+			Original:  finopsv1.ResourceValues{CPURequest: "100m"},
+			Optimized: finopsv1.ResourceValues{CPURequest: "150m"},
+		},
+	)
+	optimization("blog", true, finopsv1.WorkloadOptimization{
+		Name:      "wordpress",
+		Original:  finopsv1.ResourceValues{CPURequest: "500m", MemoryRequest: "512Mi"},
+		Optimized: finopsv1.ResourceValues{CPURequest: "100m", MemoryRequest: "128Mi"},
+	})
+	optimization("reverted", false, finopsv1.WorkloadOptimization{
+		Name:      "api",
+		Original:  finopsv1.ResourceValues{CPURequest: "8", MemoryRequest: "8Gi"},
+		Optimized: finopsv1.ResourceValues{CPURequest: "1", MemoryRequest: "1Gi"},
+	})
+
+	rr := httptest.NewRecorder()
+	server.handleOptimizationSummary(rr, httptest.NewRequest(http.MethodGet, "/api/optimization/summary", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var summary OptimizationSummary
+	if err := json.NewDecoder(rr.Body).Decode(&summary); err != nil {
+		t.Fatal(err)
+	}
+	if len(summary.Namespaces) != 2 || summary.Namespaces[0].Namespace != "blog" || summary.Namespaces[1].Namespace != "shop" {
+		t.Fatalf("expected the two active namespaces sorted by name, got %+v", summary.Namespaces)
+	}
+	if shop := summary.Namespaces[1]; shop.CPUReclaimedMillicores != 700 || shop.MemoryReclaimedMiB != 768 || shop.Workloads != 2 {
+		t.Errorf("unexpected shop savings %+v", shop)
+	}
+	if summary.CPUReclaimedMillicores != 1100 || summary.MemoryReclaimedMiB != 1152 || summary.Workloads != 3 {
+		t.Errorf("unexpected cluster savings %+v", summary)
+	}
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+	webhookv1 "github.com/migalsp/kubex-operator/internal/webhook/v1"
+)
+
+// ScalingExport is the portable form of every ScalingGroup and ScalingConfig, carrying
+// only names, labels and specs so it can be imported into another cluster
+type ScalingExport struct {
+	Groups  []ExportedScalingGroup  `json:"groups"`
+	Configs []ExportedScalingConfig `json:"configs"`
+}
+
+type ExportedScalingGroup struct {
+	Name   string                    `json:"name"`
+	Labels map[string]string         `json:"labels,omitempty"`
+	Spec   finopsv1.ScalingGroupSpec `json:"spec"`
+}
+
+type ExportedScalingConfig struct {
+	Name   string                     `json:"name"`
+	Labels map[string]string          `json:"labels,omitempty"`
+	Spec   finopsv1.ScalingConfigSpec `json:"spec"`
+}
+
+// Outcomes of importing a single object
+const (
+	importCreated = "created"
+	importUpdated = "updated"
+	importSkipped = "skipped"
+	importFailed  = "failed"
+)
+
+// ScalingImportResult is the outcome of importing one object
+type ScalingImportResult struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// handleScalingExport returns all scaling configuration of the operator namespace.
+func (s *Server) handleScalingExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := r.Context()
+	operatorNs := getOperatorNamespace()
+
+	var groups finopsv1.ScalingGroupList
+	if err := s.Client.List(ctx, &groups, client.InNamespace(operatorNs)); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var configs finopsv1.ScalingConfigList
+	if err := s.Client.List(ctx, &configs, client.InNamespace(operatorNs)); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	export := ScalingExport{Groups: []ExportedScalingGroup{}, Configs: []ExportedScalingConfig{}}
+	for _, g := range groups.Items {
+		export.Groups = append(export.Groups, ExportedScalingGroup{Name: g.Name, Labels: g.Labels, Spec: g.Spec})
+	}
+	for _, c := range configs.Items {
+		export.Configs = append(export.Configs, ExportedScalingConfig{Name: c.Name, Labels: c.Labels, Spec: c.Spec})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", "attachment; filename=kubex-scaling.json")
+	json.NewEncoder(w).Encode(export)
+}
+
+// handleScalingImport recreates exported scaling configuration in the operator namespace.
+// With ?mode=create (the default) existing objects are skipped, with ?mode=upsert their
+// spec is replaced.
+func (s *Server) handleScalingImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = "create"
+	}
+	if mode != "create" && mode != "upsert" {
+		writeJSONError(w, "Invalid mode, expected create or upsert", http.StatusBadRequest)
+		return
+	}
+
+	var export ScalingExport
+	if err := json.NewDecoder(r.Body).Decode(&export); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	operatorNs := getOperatorNamespace()
+	results := []ScalingImportResult{}
+
+	for _, g := range export.Groups {
+		group := &finopsv1.ScalingGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: g.Name, Namespace: operatorNs, Labels: g.Labels},
+			Spec:       g.Spec,
+		}
+		_, err := (&webhookv1.ScalingGroupCustomValidator{}).ValidateCreate(ctx, group)
+		current := &finopsv1.ScalingGroup{}
+		result := s.importObject(r, mode, "ScalingGroup", group, current, err, func() { current.Spec = group.Spec })
+		results = append(results, result)
+	}
+	for _, c := range export.Configs {
+		config := &finopsv1.ScalingConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: c.Name, Namespace: operatorNs, Labels: c.Labels},
+			Spec:       c.Spec,
+		}
+		_, err := (&webhookv1.ScalingConfigCustomValidator{}).ValidateCreate(ctx, config)
+		if err == nil && config.Spec.TargetNamespace == "" {
+			err = fmt.Errorf("spec.targetNamespace is required")
+		}
+		current := &finopsv1.ScalingConfig{}
+		result := s.importObject(r, mode, "ScalingConfig", config, current, err, func() { current.Spec = config.Spec })
+		results = append(results, result)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+}
+
+// importObject creates obj, or copies its spec onto the existing object in upsert mode.
+// current receives the existing object and copySpec applies the imported spec to it.
+func (s *Server) importObject(r *http.Request, mode, kind string, obj, current client.Object, validationErr error, copySpec func()) ScalingImportResult {
+	ctx := r.Context()
+	result := ScalingImportResult{Kind: kind, Name: obj.GetName()}
+	fail := func(err error) ScalingImportResult {
+		result.Result = importFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	if obj.GetName() == "" {
+		return fail(fmt.Errorf("name is required"))
+	}
+	if validationErr != nil {
+		return fail(validationErr)
+	}
+
+	err := s.Client.Get(ctx, client.ObjectKeyFromObject(obj), current)
+	switch {
+	case errors.IsNotFound(err):
+		if err := s.Client.Create(ctx, obj); err != nil {
+			return fail(err)
+		}
+		result.Result = importCreated
+	case err != nil:
+		return fail(err)
+	case mode != "upsert":
+		result.Result = importSkipped
+		return result
+	default:
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := s.Client.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+				return err
+			}
+			copySpec()
+			return s.Client.Update(ctx, current)
+		})
+		if err != nil {
+			return fail(err)
+		}
+		result.Result = importUpdated
+		obj = current
+	}
+
+	s.audit(r, auditImportScaling, obj.GetNamespace(), obj.GetName(), obj)
+	return result
+}
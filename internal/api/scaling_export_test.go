@@ -0,0 +1,100 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+)
+
+func TestScalingExportImport(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+	ctx := context.Background()
+
+	source := buildMockServer()
+	source.Client.Create(ctx, &finopsv1.ScalingGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "kubex", Labels: map[string]string{"team": "web"}},
+		Spec:       finopsv1.ScalingGroupSpec{Namespaces: []string{"frontend", "backend"}},
+		Status:     finopsv1.ScalingGroupStatus{Phase: "ScaledDown"},
+	})
+	source.Client.Create(ctx, &finopsv1.ScalingConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "kubex"},
+		Spec:       finopsv1.ScalingConfigSpec{TargetNamespace: "backend", Sequence: []string{"db"}},
+	})
+
+	rr := httptest.NewRecorder()
+	source.handleScalingExport(rr, httptest.NewRequest(http.MethodGet, "/api/scaling/export", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("export: expected 200, got %d", rr.Code)
+	}
+	exported := rr.Body.Bytes()
+	if bytes.Contains(exported, []byte("resourceVersion")) || bytes.Contains(exported, []byte("ScaledDown")) {
+		t.Errorf("export should only carry specs: %s", exported)
+	}
+
+	// The target cluster already has a diverging config
+	target := buildMockServer()
+	target.Client.Create(ctx, &finopsv1.ScalingConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "kubex"},
+		Spec:       finopsv1.ScalingConfigSpec{TargetNamespace: "backend"},
+	})
+
+	importDoc := func(mode string, body []byte) []ScalingImportResult {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		target.handleScalingImport(rr, httptest.NewRequest(http.MethodPost, "/api/scaling/import?mode="+mode, bytes.NewReader(body)))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("import: expected 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var resp struct {
+			Results []ScalingImportResult `json:"results"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.Results
+	}
+
+	results := importDoc("create", exported)
+	if len(results) != 2 || results[0].Result != importCreated || results[1].Result != importSkipped {
+		t.Fatalf("unexpected create results: %+v", results)
+	}
+	group := &finopsv1.ScalingGroup{}
+	if err := target.Client.Get(ctx, client.ObjectKey{Name: "dev", Namespace: "kubex"}, group); err != nil {
+		t.Fatal(err)
+	}
+	if len(group.Spec.Namespaces) != 2 || group.Labels["team"] != "web" || group.Status.Phase != "" {
+		t.Errorf("unexpected imported group: %+v", group)
+	}
+
+	results = importDoc("upsert", exported)
+	if len(results) != 2 || results[0].Result != importUpdated || results[1].Result != importUpdated {
+		t.Fatalf("unexpected upsert results: %+v", results)
+	}
+	config := &finopsv1.ScalingConfig{}
+	target.Client.Get(ctx, client.ObjectKey{Name: "backend", Namespace: "kubex"}, config)
+	if len(config.Spec.Sequence) != 1 {
+		t.Errorf("expected upsert to replace the spec, got %+v", config.Spec)
+	}
+
+	invalid := []byte(`{"groups":[{"name":"empty","spec":{"namespaces":[]}}],"configs":[{"name":"night","spec":{"targetNamespace":"batch","schedules":[{"days":[1],"startTime":"22:00","endTime":"06:00"}]}}]}`)
+	results = importDoc("create", invalid)
+	if len(results) != 2 || results[0].Result != importFailed || results[1].Result != importFailed || results[1].Error == "" {
+		t.Errorf("expected invalid objects to fail validation: %+v", results)
+	}
+
+	rr = httptest.NewRecorder()
+	target.handleScalingImport(rr, httptest.NewRequest(http.MethodPost, "/api/scaling/import?mode=replace", bytes.NewReader(exported)))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown mode, got %d", rr.Code)
+	}
+}
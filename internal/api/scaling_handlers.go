@@ -3,15 +3,21 @@ package api
 import (
 	"encoding/json"
 	"net/http"
-	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+	"github.com/migalsp/kubex-operator/internal/operatorns"
+	"github.com/migalsp/kubex-operator/internal/scaling"
+	webhookv1 "github.com/migalsp/kubex-operator/internal/webhook/v1"
 )
 
 func (s *Server) handleScalingGroups(w http.ResponseWriter, r *http.Request) {
@@ -22,7 +28,7 @@ func (s *Server) handleScalingGroups(w http.ResponseWriter, r *http.Request) {
 	case http.MethodGet:
 		var list finopsv1.ScalingGroupList
 		if err := s.Client.List(ctx, &list, client.InNamespace(operatorNs)); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
@@ -31,19 +37,18 @@ func (s *Server) handleScalingGroups(w http.ResponseWriter, r *http.Request) {
 	case http.MethodPost:
 		var group finopsv1.ScalingGroup
 		if err := json.NewDecoder(r.Body).Decode(&group); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		group.Namespace = operatorNs
-		if err := s.Client.Create(ctx, &group); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		if !s.checkGroupNamespaces(w, r, group.Spec) {
 			return
 		}
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(group)
+		group.Namespace = operatorNs
+		current := &finopsv1.ScalingGroup{}
+		s.createScalingObject(w, r, "ScalingGroup", &group, current, func() { current.Spec = group.Spec })
 
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
 }
 
@@ -51,7 +56,7 @@ func (s *Server) handleScalingGroupActions(w http.ResponseWriter, r *http.Reques
 	ctx := r.Context()
 	parts := strings.Split(r.URL.Path, "/")
 	if len(parts) < 5 {
-		http.Error(w, "Invalid path", http.StatusBadRequest)
+		writeJSONError(w, "Invalid path", http.StatusBadRequest)
 		return
 	}
 	name := parts[4]
@@ -60,9 +65,9 @@ func (s *Server) handleScalingGroupActions(w http.ResponseWriter, r *http.Reques
 	group := &finopsv1.ScalingGroup{}
 	if err := s.Client.Get(ctx, client.ObjectKey{Name: name, Namespace: operatorNs}, group); err != nil {
 		if errors.IsNotFound(err) {
-			http.Error(w, "Group not found", http.StatusNotFound)
+			writeJSONError(w, "Group not found", http.StatusNotFound)
 		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
 		}
 		return
 	}
@@ -74,7 +79,7 @@ func (s *Server) handleScalingGroupActions(w http.ResponseWriter, r *http.Reques
 			return
 		}
 		if parts[5] == "events" {
-			s.handleScalingGroupEvents(w, r, group)
+			s.handleScalingEvents(w, r, "ScalingGroup", group)
 			return
 		}
 	}
@@ -82,45 +87,82 @@ func (s *Server) handleScalingGroupActions(w http.ResponseWriter, r *http.Reques
 	switch r.Method {
 	case http.MethodGet:
 		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", etag(group))
 		json.NewEncoder(w).Encode(group)
 
 	case http.MethodPut:
 		var updated finopsv1.ScalingGroup
 		if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !s.checkGroupNamespaces(w, r, updated.Spec) {
 			return
 		}
 
-		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-			current := &finopsv1.ScalingGroup{}
-			if err := s.Client.Get(ctx, client.ObjectKey{Name: name, Namespace: operatorNs}, current); err != nil {
-				return err
-			}
-			current.Spec = updated.Spec
-			return s.Client.Update(ctx, current)
-		})
-
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		current := &finopsv1.ScalingGroup{}
+		current.Name, current.Namespace = name, operatorNs
+		if err := s.updateSpec(r, current, func() { current.Spec = updated.Spec }); err != nil {
+			writeUpdateError(w, r, "ScalingGroup "+name, err)
 			return
 		}
-		json.NewEncoder(w).Encode(updated)
+		s.audit(r, auditUpdateGroup, operatorNs, name, group)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", etag(current))
+		json.NewEncoder(w).Encode(current)
 
 	case http.MethodDelete:
 		if err := s.Client.Delete(ctx, group); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		s.audit(r, auditDeleteGroup, operatorNs, name, group)
 		w.WriteHeader(http.StatusNoContent)
 
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// checkGroupNamespaces rejects a group spec listing namespaces that do not exist, which the
+// controller would otherwise silently skip, or carrying a selector that does not parse.
+func (s *Server) checkGroupNamespaces(w http.ResponseWriter, r *http.Request, spec finopsv1.ScalingGroupSpec) bool {
+	if spec.NamespaceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(spec.NamespaceSelector); err != nil {
+			writeJSONError(w, "Invalid namespaceSelector: "+err.Error(), http.StatusBadRequest)
+			return false
+		}
+	}
+
+	missing := []string{}
+	for _, ns := range spec.Namespaces {
+		err := s.Client.Get(r.Context(), client.ObjectKey{Name: ns}, &corev1.Namespace{})
+		if errors.IsNotFound(err) {
+			missing = append(missing, ns)
+		} else if err != nil {
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
+			return false
+		}
+	}
+	if len(missing) == 0 {
+		return true
 	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(struct {
+		apiError
+		Missing []string `json:"missing"`
+	}{
+		apiError: apiError{Error: "Namespaces not found: " + strings.Join(missing, ", "), Code: http.StatusBadRequest},
+		Missing:  missing,
+	})
+	return false
 }
 
 func (s *Server) handleScalingGroupManual(w http.ResponseWriter, r *http.Request, group *finopsv1.ScalingGroup) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -128,50 +170,41 @@ func (s *Server) handleScalingGroupManual(w http.ResponseWriter, r *http.Request
 		Active *bool `json:"active"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	group.Spec.Active = req.Active
 	if err := s.Client.Update(r.Context(), group); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	s.audit(r, auditManualOverride, group.Namespace, group.Name, group)
 	json.NewEncoder(w).Encode(group)
 }
 
-func (s *Server) handleScalingGroupEvents(w http.ResponseWriter, r *http.Request, group *finopsv1.ScalingGroup) {
+// handleScalingEvents lists the Kubernetes events involving a ScalingGroup or ScalingConfig.
+func (s *Server) handleScalingEvents(w http.ResponseWriter, r *http.Request, kind string, obj client.Object) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	ctx := r.Context()
 	var events corev1.EventList
 
-	// Filter events targeting this specific ScalingGroup
-	err := s.Client.List(ctx, &events, client.InNamespace(group.Namespace), client.MatchingFields{"involvedObject.name": group.Name})
+	// Filter events targeting this specific object, served by the indexes from SetupFieldIndexes
+	err := s.Client.List(ctx, &events, client.InNamespace(obj.GetNamespace()), client.MatchingFields{
+		eventInvolvedKindField: kind,
+		eventInvolvedNameField: obj.GetName(),
+	})
 	if err != nil {
-		// Log the error but return empty array if field selector fails
-		// In some setups, field selectors might require index setup, try fallback filtering if needed.
-		// For now, if exact field matching is strict, we fetch all in namespace and filter in memory.
-		err = s.Client.List(ctx, &events, client.InNamespace(group.Namespace))
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-	}
-
-	// Filter in memory to ensure we only return ScalingGroup events
-	var filtered []corev1.Event
-	for _, e := range events.Items {
-		if e.InvolvedObject.Kind == "ScalingGroup" && e.InvolvedObject.Name == group.Name {
-			filtered = append(filtered, e)
-		}
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(filtered)
+	json.NewEncoder(w).Encode(events.Items)
 }
 
 func (s *Server) handleScalingConfigs(w http.ResponseWriter, r *http.Request) {
@@ -182,7 +215,7 @@ func (s *Server) handleScalingConfigs(w http.ResponseWriter, r *http.Request) {
 	case http.MethodGet:
 		var list finopsv1.ScalingConfigList
 		if err := s.Client.List(ctx, &list, client.InNamespace(operatorNs)); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
@@ -191,27 +224,67 @@ func (s *Server) handleScalingConfigs(w http.ResponseWriter, r *http.Request) {
 	case http.MethodPost:
 		var config finopsv1.ScalingConfig
 		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 		config.Namespace = operatorNs
-		if err := s.Client.Create(ctx, &config); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		current := &finopsv1.ScalingConfig{}
+		s.createScalingObject(w, r, "ScalingConfig", &config, current, func() { current.Spec = config.Spec })
+
+	default:
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// createScalingObject creates obj and replies 201 Created. When an object of the same name
+// exists it replies 409 Conflict, unless ?upsert=true is set: the spec is then copied onto
+// the existing object by copySpec, which reads it into current, and 200 OK is returned.
+func (s *Server) createScalingObject(w http.ResponseWriter, r *http.Request, kind string, obj, current client.Object, copySpec func()) {
+	ctx := r.Context()
+	upsert := false
+	if v := r.URL.Query().Get("upsert"); v != "" {
+		var err error
+		if upsert, err = strconv.ParseBool(v); err != nil {
+			writeJSONError(w, "Invalid upsert, expected true or false", http.StatusBadRequest)
 			return
 		}
+	}
+
+	err := s.Client.Create(ctx, obj)
+	if err == nil {
 		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(config)
+		json.NewEncoder(w).Encode(obj)
+		return
+	}
+	if !errors.IsAlreadyExists(err) {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !upsert {
+		writeJSONError(w, kind+" "+obj.GetName()+" already exists, add ?upsert=true to replace its spec", http.StatusConflict)
+		return
+	}
 
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := s.Client.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+			return err
+		}
+		copySpec()
+		return s.Client.Update(ctx, current)
+	})
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(current)
 }
 
 func (s *Server) handleScalingConfigActions(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	parts := strings.Split(r.URL.Path, "/")
 	if len(parts) < 5 {
-		http.Error(w, "Invalid path", http.StatusBadRequest)
+		writeJSONError(w, "Invalid path", http.StatusBadRequest)
 		return
 	}
 	name := parts[4]
@@ -220,60 +293,65 @@ func (s *Server) handleScalingConfigActions(w http.ResponseWriter, r *http.Reque
 	config := &finopsv1.ScalingConfig{}
 	if err := s.Client.Get(ctx, client.ObjectKey{Name: name, Namespace: operatorNs}, config); err != nil {
 		if errors.IsNotFound(err) {
-			http.Error(w, "Config not found", http.StatusNotFound)
+			writeJSONError(w, "Config not found", http.StatusNotFound)
 		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
 		}
 		return
 	}
 
-	if len(parts) > 5 && parts[5] == "manual" {
-		s.handleScalingConfigManual(w, r, config)
-		return
+	// Sub-actions like /api/scaling/configs/{name}/manual or /events
+	if len(parts) > 5 {
+		if parts[5] == "manual" {
+			s.handleScalingConfigManual(w, r, config)
+			return
+		}
+		if parts[5] == "events" {
+			s.handleScalingEvents(w, r, "ScalingConfig", config)
+			return
+		}
 	}
 
 	switch r.Method {
 	case http.MethodGet:
 		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", etag(config))
 		json.NewEncoder(w).Encode(config)
 
 	case http.MethodPut:
 		var updated finopsv1.ScalingConfig
 		if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-			current := &finopsv1.ScalingConfig{}
-			if err := s.Client.Get(ctx, client.ObjectKey{Name: name, Namespace: operatorNs}, current); err != nil {
-				return err
-			}
-			current.Spec = updated.Spec
-			return s.Client.Update(ctx, current)
-		})
-
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		current := &finopsv1.ScalingConfig{}
+		current.Name, current.Namespace = name, operatorNs
+		if err := s.updateSpec(r, current, func() { current.Spec = updated.Spec }); err != nil {
+			writeUpdateError(w, r, "ScalingConfig "+name, err)
 			return
 		}
-		json.NewEncoder(w).Encode(updated)
+		s.audit(r, auditUpdateConfig, operatorNs, name, config)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", etag(current))
+		json.NewEncoder(w).Encode(current)
 
 	case http.MethodDelete:
 		if err := s.Client.Delete(ctx, config); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		s.audit(r, auditDeleteConfig, operatorNs, name, config)
 		w.WriteHeader(http.StatusNoContent)
 
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
 }
 
 func (s *Server) handleScalingConfigManual(w http.ResponseWriter, r *http.Request, config *finopsv1.ScalingConfig) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -281,22 +359,276 @@ func (s *Server) handleScalingConfigManual(w http.ResponseWriter, r *http.Reques
 		Active *bool `json:"active"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	config.Spec.Active = req.Active
 	if err := s.Client.Update(r.Context(), config); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	s.audit(r, auditManualOverride, config.Namespace, config.Name, config)
 	json.NewEncoder(w).Encode(config)
 }
 
+// handleScalingValidate resolves the sequence of a ScalingConfig or ScalingGroup spec
+// against the cluster without saving it.
+func (s *Server) handleScalingValidate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Kind string          `json:"kind"`
+		Spec json.RawMessage `json:"spec"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	engine := &scaling.Engine{Client: s.Client}
+	var result *scaling.ValidationResult
+	var err error
+
+	switch req.Kind {
+	case "ScalingConfig":
+		var spec finopsv1.ScalingConfigSpec
+		if err := json.Unmarshal(req.Spec, &spec); err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err = engine.ValidateConfig(r.Context(), spec)
+	case "ScalingGroup":
+		var spec finopsv1.ScalingGroupSpec
+		if err := json.Unmarshal(req.Spec, &spec); err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err = engine.ValidateGroup(r.Context(), spec)
+	default:
+		writeJSONError(w, "Unknown kind, expected ScalingConfig or ScalingGroup", http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// ScalingConflict is returned by GET /api/scaling/conflicts for every ScalingConfig
+type ScalingConflict struct {
+	Config          string `json:"config"`
+	TargetNamespace string `json:"targetNamespace"`
+	// Overridden is true when a ScalingGroup manages the target namespace, in which case
+	// the config is ignored and reports the OverriddenByGroup phase
+	Overridden bool   `json:"overridden"`
+	Group      string `json:"group,omitempty"`
+}
+
+// handleScalingConflicts reports which ScalingConfigs are overridden by a ScalingGroup, using
+// the same matching as the ScalingConfig controller.
+func (s *Server) handleScalingConflicts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := r.Context()
+
+	var configs finopsv1.ScalingConfigList
+	if err := s.Client.List(ctx, &configs, client.InNamespace(getOperatorNamespace())); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// The controller considers groups from every namespace, so do the same here
+	var groups finopsv1.ScalingGroupList
+	if err := s.Client.List(ctx, &groups); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]ScalingConflict, 0, len(configs.Items))
+	for _, cfg := range configs.Items {
+		c := ScalingConflict{Config: cfg.Name, TargetNamespace: cfg.Spec.TargetNamespace}
+		if g := scaling.ManagingGroup(groups.Items, cfg.Spec.TargetNamespace); g != nil {
+			c.Overridden = true
+			c.Group = g.Name
+		}
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Config < result[j].Config })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// ScheduleSimulation is returned by POST /api/scaling/simulate
+type ScheduleSimulation struct {
+	At     time.Time `json:"at"`
+	Active bool      `json:"active"`
+	// NextTransition is when the schedules next flip the state, omitted when they never do
+	NextTransition      *time.Time `json:"nextTransition,omitempty"`
+	NextTransitionState string     `json:"nextTransitionState,omitempty"`
+}
+
+// handleScalingSimulate evaluates schedules and a manual override at a given time, exactly
+// as the controllers do, to debug timezones and windows without waiting for them.
+func (s *Server) handleScalingSimulate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Schedules    []finopsv1.ScalingSchedule `json:"schedules"`
+		ManualActive *bool                      `json:"manualActive"`
+		At           *time.Time                 `json:"at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := webhookv1.ValidateSchedules(req.Schedules); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	at := time.Now()
+	if req.At != nil {
+		at = *req.At
+	}
+
+	engine := &scaling.Engine{}
+	result := ScheduleSimulation{
+		At:     at,
+		Active: engine.IsActiveAt(req.Schedules, req.ManualActive, at),
+	}
+	if next, active, ok := engine.NextTransition(req.Schedules, req.ManualActive, at); ok {
+		result.NextTransition = &next
+		result.NextTransitionState = "Inactive"
+		if active {
+			result.NextTransitionState = "Active"
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleEmergencyRestore forces every ScalingGroup and ScalingConfig active, ignoring their
+// schedules. Objects already forced active are left untouched, so repeated calls are safe.
+func (s *Server) handleEmergencyRestore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	operatorNs := getOperatorNamespace()
+	active := true
+
+	var groups finopsv1.ScalingGroupList
+	if err := s.Client.List(ctx, &groups, client.InNamespace(operatorNs)); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var configs finopsv1.ScalingConfigList
+	if err := s.Client.List(ctx, &configs, client.InNamespace(operatorNs)); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	summary := struct {
+		Groups  int `json:"groups"`
+		Configs int `json:"configs"`
+	}{}
+
+	// The spec update itself triggers the reconcile of each flipped object
+	for i := range groups.Items {
+		group := &groups.Items[i]
+		if group.Spec.Active != nil && *group.Spec.Active {
+			continue
+		}
+		group.Spec.Active = &active
+		if err := s.Client.Update(ctx, group); err != nil {
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.audit(r, auditEmergency, group.Namespace, group.Name, group)
+		summary.Groups++
+	}
+	for i := range configs.Items {
+		config := &configs.Items[i]
+		if config.Spec.Active != nil && *config.Spec.Active {
+			continue
+		}
+		config.Spec.Active = &active
+		if err := s.Client.Update(ctx, config); err != nil {
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.audit(r, auditEmergency, config.Namespace, config.Name, config)
+		summary.Configs++
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
 func getOperatorNamespace() string {
-	ns := os.Getenv("POD_NAMESPACE")
-	if ns == "" {
-		return "kubex"
+	return operatorns.Namespace()
+}
+
+// etag is the ETag of obj, its resourceVersion, which PUT accepts back in If-Match
+func etag(obj client.Object) string {
+	return strconv.Quote(obj.GetResourceVersion())
+}
+
+// ifMatchVersion returns the resourceVersion sent in the If-Match header, or "" when the
+// header is absent or "*"
+func ifMatchVersion(r *http.Request) string {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	v = strings.TrimPrefix(v, "W/")
+	if unquoted, err := strconv.Unquote(v); err == nil {
+		v = unquoted
+	}
+	if v == "*" {
+		return ""
+	}
+	return v
+}
+
+// updateSpec reads obj, applies mutate and updates it. Without an If-Match header conflicts
+// are retried so the last write wins. With one, the update is made once against that
+// resourceVersion and fails with a Conflict error if the object changed in the meantime.
+func (s *Server) updateSpec(r *http.Request, obj client.Object, mutate func()) error {
+	key := client.ObjectKeyFromObject(obj)
+	version := ifMatchVersion(r)
+	update := func() error {
+		if err := s.Client.Get(r.Context(), key, obj); err != nil {
+			return err
+		}
+		mutate()
+		if version != "" {
+			obj.SetResourceVersion(version)
+		}
+		return s.Client.Update(r.Context(), obj)
+	}
+	if version != "" {
+		return update()
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, update)
+}
+
+// writeUpdateError reports a failed updateSpec, a stale If-Match as 409 Conflict
+func writeUpdateError(w http.ResponseWriter, r *http.Request, what string, err error) {
+	if version := ifMatchVersion(r); version != "" && errors.IsConflict(err) {
+		writeJSONError(w, what+" was modified since resourceVersion "+version+", reload it and apply your changes again", http.StatusConflict)
+		return
 	}
-	return ns
+	writeJSONError(w, err.Error(), http.StatusInternalServerError)
 }
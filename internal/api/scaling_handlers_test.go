@@ -4,16 +4,22 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -74,6 +80,7 @@ func TestHandleScalingGroupsPOST(t *testing.T) {
 	defer os.Unsetenv("POD_NAMESPACE")
 
 	server := buildMockServer()
+	server.Client.Create(context.Background(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test"}})
 
 	body := []byte(`{"metadata":{"name":"new-group"},"spec":{"namespaces":["test"]}}`)
 	req, err := http.NewRequest("POST", "/api/scaling/groups", bytes.NewBuffer(body))
@@ -97,6 +104,83 @@ func TestHandleScalingGroupsPOST(t *testing.T) {
 	}
 }
 
+func TestHandleScalingGroupsMissingNamespaces(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServer()
+	server.Client.Create(context.Background(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}})
+
+	tests := []struct {
+		name    string
+		spec    string
+		code    int
+		missing []string
+	}{
+		{"existing namespaces", `{"namespaces":["shop"]}`, http.StatusCreated, nil},
+		{"typos", `{"namespaces":["shop","shpo","cart"]}`, http.StatusBadRequest, []string{"shpo", "cart"}},
+		{"bad selector", `{"namespaceSelector":{"matchExpressions":[{"key":"solution","operator":"Near"}]}}`, http.StatusBadRequest, nil},
+		{"selector", `{"namespaceSelector":{"matchLabels":{"solution":"shop"}}}`, http.StatusCreated, nil},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := fmt.Sprintf(`{"metadata":{"name":"group-%d"},"spec":%s}`, i, tt.spec)
+			rr := httptest.NewRecorder()
+			server.handleScalingGroups(rr, httptest.NewRequest("POST", "/api/scaling/groups", strings.NewReader(body)))
+			if rr.Code != tt.code {
+				t.Fatalf("expected %d, got %d: %s", tt.code, rr.Code, rr.Body.String())
+			}
+
+			var resp struct {
+				Missing []string `json:"missing"`
+			}
+			json.NewDecoder(rr.Body).Decode(&resp)
+			if fmt.Sprint(resp.Missing) != fmt.Sprint(tt.missing) {
+				t.Errorf("expected missing %v, got %v", tt.missing, resp.Missing)
+			}
+		})
+	}
+}
+
+func TestHandleScalingCreateExisting(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServer()
+	server.Client.Create(context.Background(), &finopsv1.ScalingConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "shop-hours", Namespace: "kubex"},
+		Spec:       finopsv1.ScalingConfigSpec{TargetNamespace: "shop"},
+	})
+	body := `{"metadata":{"name":"shop-hours"},"spec":{"targetNamespace":"shop","scaleDownReplicaPercent":50}}`
+
+	tests := []struct {
+		name    string
+		query   string
+		code    int
+		percent int32
+	}{
+		{"conflict", "", http.StatusConflict, 0},
+		{"upsert disabled", "?upsert=false", http.StatusConflict, 0},
+		{"invalid upsert", "?upsert=maybe", http.StatusBadRequest, 0},
+		{"upsert", "?upsert=true", http.StatusOK, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			server.handleScalingConfigs(rr, httptest.NewRequest("POST", "/api/scaling/configs"+tt.query, strings.NewReader(body)))
+			if rr.Code != tt.code {
+				t.Fatalf("expected %d, got %d: %s", tt.code, rr.Code, rr.Body.String())
+			}
+
+			var config finopsv1.ScalingConfig
+			server.Client.Get(context.Background(), client.ObjectKey{Name: "shop-hours", Namespace: "kubex"}, &config)
+			if config.Spec.ScaleDownReplicaPercent != tt.percent {
+				t.Errorf("expected scaleDownReplicaPercent %d, got %d", tt.percent, config.Spec.ScaleDownReplicaPercent)
+			}
+		})
+	}
+}
+
 func TestHandleScalingConfigsGET(t *testing.T) {
 	os.Setenv("POD_NAMESPACE", "kubex")
 	defer os.Unsetenv("POD_NAMESPACE")
@@ -170,3 +254,372 @@ func TestHandleScalingConfigActionsGETAndDELETE(t *testing.T) {
 		t.Errorf("DELETE returned wrong status code: got %v want %v", status, http.StatusNoContent)
 	}
 }
+
+func TestHandleScalingConfigPUTIfMatch(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServer()
+	server.Client.Create(context.Background(), &finopsv1.ScalingConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-config-put", Namespace: "kubex"},
+		Spec:       finopsv1.ScalingConfigSpec{TargetNamespace: "test-ns"},
+	})
+	handler := http.HandlerFunc(server.handleScalingConfigActions)
+
+	put := func(ifMatch string, exclusions ...string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(finopsv1.ScalingConfig{
+			Spec: finopsv1.ScalingConfigSpec{TargetNamespace: "test-ns", Exclusions: exclusions},
+		})
+		req, _ := http.NewRequest("PUT", "/api/scaling/configs/test-config-put", bytes.NewReader(body))
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	reqGet, _ := http.NewRequest("GET", "/api/scaling/configs/test-config-put", nil)
+	rrGet := httptest.NewRecorder()
+	handler.ServeHTTP(rrGet, reqGet)
+	loaded := rrGet.Header().Get("ETag")
+	if loaded == "" {
+		t.Fatal("GET did not return an ETag")
+	}
+
+	// The first editor saves with the version they loaded
+	first := put(loaded, "db")
+	if first.Code != http.StatusOK {
+		t.Fatalf("PUT with current If-Match returned %d: %s", first.Code, first.Body.String())
+	}
+	if first.Header().Get("ETag") == loaded {
+		t.Errorf("PUT should return the new ETag, got the loaded one %s", loaded)
+	}
+
+	// The second editor loaded the same version and is now stale
+	stale := put(loaded, "cache")
+	if stale.Code != http.StatusConflict {
+		t.Fatalf("PUT with stale If-Match returned %d, want 409", stale.Code)
+	}
+
+	current := &finopsv1.ScalingConfig{}
+	server.Client.Get(context.Background(), client.ObjectKey{Name: "test-config-put", Namespace: "kubex"}, current)
+	if len(current.Spec.Exclusions) != 1 || current.Spec.Exclusions[0] != "db" {
+		t.Errorf("stale PUT overwrote the spec: %v", current.Spec.Exclusions)
+	}
+
+	// Without If-Match the last write still wins
+	if rr := put("", "cache"); rr.Code != http.StatusOK {
+		t.Fatalf("PUT without If-Match returned %d: %s", rr.Code, rr.Body.String())
+	}
+	server.Client.Get(context.Background(), client.ObjectKey{Name: "test-config-put", Namespace: "kubex"}, current)
+	if len(current.Spec.Exclusions) != 1 || current.Spec.Exclusions[0] != "cache" {
+		t.Errorf("PUT without If-Match was not applied: %v", current.Spec.Exclusions)
+	}
+}
+
+func TestHandleScalingGroupManualAudit(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServer()
+	recorder := record.NewFakeRecorder(10)
+	server.Recorder = recorder
+
+	group := &finopsv1.ScalingGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-group", Namespace: "kubex"},
+		Spec:       finopsv1.ScalingGroupSpec{Namespaces: []string{"default"}},
+	}
+	server.Client.Create(context.Background(), group)
+
+	req, _ := http.NewRequest("POST", "/api/scaling/groups/test-group/manual", bytes.NewBufferString(`{"active": false}`))
+	req = req.WithContext(withUser(req.Context(), "alice"))
+	rr := httptest.NewRecorder()
+	server.handleScalingGroupActions(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %v", rr.Code)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, auditManualOverride) || !strings.Contains(event, "alice") {
+			t.Errorf("expected manual override event attributed to alice, got %q", event)
+		}
+	default:
+		t.Errorf("expected an audit event to be recorded")
+	}
+}
+
+func TestHandleScalingValidate(t *testing.T) {
+	server := buildMockServer()
+
+	body := `{"kind": "ScalingConfig", "spec": {"targetNamespace": "missing", "sequence": ["db"]}}`
+	req, _ := http.NewRequest("POST", "/api/scaling/validate", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+	server.handleScalingValidate(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %v", rr.Code)
+	}
+	var result struct {
+		Warnings []string `json:"warnings"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Warnings) == 0 {
+		t.Errorf("expected warnings for a missing namespace")
+	}
+
+	req, _ = http.NewRequest("POST", "/api/scaling/validate", bytes.NewBufferString(`{"kind": "Unknown", "spec": {}}`))
+	rr = httptest.NewRecorder()
+	server.handleScalingValidate(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown kind, got %v", rr.Code)
+	}
+}
+
+func TestHandleScalingSimulate(t *testing.T) {
+	server := buildMockServer()
+	schedules := `[{"days": [1, 2, 3, 4, 5], "startTime": "09:00", "endTime": "18:00", "timezone": "Europe/Madrid"}]`
+
+	tests := []struct {
+		name      string
+		body      string
+		code      int
+		active    bool
+		nextState string
+		nextAtUTC string
+	}{
+		{
+			// Monday 08:30 in Madrid
+			name: "before the window", code: http.StatusOK,
+			body:   `{"schedules": ` + schedules + `, "at": "2026-06-01T06:30:00Z"}`,
+			active: false, nextState: "Active", nextAtUTC: "2026-06-01T07:00:00Z",
+		},
+		{
+			name: "inside the window", code: http.StatusOK,
+			body:   `{"schedules": ` + schedules + `, "at": "2026-06-01T10:00:00Z"}`,
+			active: true, nextState: "Inactive", nextAtUTC: "2026-06-01T16:01:00Z",
+		},
+		{
+			name: "manual override", code: http.StatusOK,
+			body:   `{"schedules": ` + schedules + `, "manualActive": false, "at": "2026-06-01T10:00:00Z"}`,
+			active: false,
+		},
+		{
+			name: "unknown timezone", code: http.StatusBadRequest,
+			body: `{"schedules": [{"days": [1], "startTime": "09:00", "endTime": "18:00", "timezone": "Mars/Olympus"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			server.handleScalingSimulate(rr, httptest.NewRequest("POST", "/api/scaling/simulate", bytes.NewBufferString(tt.body)))
+			if rr.Code != tt.code {
+				t.Fatalf("expected %d, got %d: %s", tt.code, rr.Code, rr.Body.String())
+			}
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			var result ScheduleSimulation
+			if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+				t.Fatal(err)
+			}
+			if result.Active != tt.active || result.NextTransitionState != tt.nextState {
+				t.Errorf("expected active=%v next=%q, got %+v", tt.active, tt.nextState, result)
+			}
+			if tt.nextAtUTC != "" && (result.NextTransition == nil || result.NextTransition.UTC().Format(time.RFC3339) != tt.nextAtUTC) {
+				t.Errorf("expected next transition at %s, got %v", tt.nextAtUTC, result.NextTransition)
+			}
+		})
+	}
+}
+
+func TestHandleEmergencyRestore(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServer()
+	recorder := record.NewFakeRecorder(10)
+	server.Recorder = recorder
+
+	inactive := false
+	active := true
+	server.Client.Create(context.Background(), &finopsv1.ScalingGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "group-a", Namespace: "kubex"},
+		Spec:       finopsv1.ScalingGroupSpec{Namespaces: []string{"default"}, Active: &inactive},
+	})
+	server.Client.Create(context.Background(), &finopsv1.ScalingConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config-a", Namespace: "kubex"},
+		Spec:       finopsv1.ScalingConfigSpec{TargetNamespace: "default"},
+	})
+	server.Client.Create(context.Background(), &finopsv1.ScalingConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "config-b", Namespace: "kubex"},
+		Spec:       finopsv1.ScalingConfigSpec{TargetNamespace: "other", Active: &active},
+	})
+
+	for i, want := range []string{`{"groups":1,"configs":1}`, `{"groups":0,"configs":0}`} {
+		req, _ := http.NewRequest("POST", "/api/scaling/emergency-restore", nil)
+		rr := httptest.NewRecorder()
+		server.handleEmergencyRestore(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("call %d: expected 200 OK, got %v", i, rr.Code)
+		}
+		if got := strings.TrimSpace(rr.Body.String()); got != want {
+			t.Errorf("call %d: expected %s, got %s", i, want, got)
+		}
+	}
+
+	if len(recorder.Events) != 2 {
+		t.Errorf("expected 2 emergency events, got %d", len(recorder.Events))
+	}
+}
+
+func TestHandleScalingConfigEvents(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(finopsv1.AddToScheme(scheme))
+	server := &Server{
+		Client: fake.NewClientBuilder().WithScheme(scheme).
+			WithIndex(&corev1.Event{}, eventInvolvedNameField, eventInvolvedName).
+			WithIndex(&corev1.Event{}, eventInvolvedKindField, eventInvolvedKind).
+			Build(),
+	}
+
+	ctx := context.Background()
+	server.Client.Create(ctx, &finopsv1.ScalingConfig{ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "kubex"}})
+	for i, target := range []corev1.ObjectReference{
+		{Kind: "ScalingConfig", Name: "backend"},
+		{Kind: "ScalingGroup", Name: "backend"},
+		{Kind: "ScalingConfig", Name: "frontend"},
+	} {
+		server.Client.Create(ctx, &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: fmt.Sprintf("event-%d", i), Namespace: "kubex"},
+			InvolvedObject: target,
+			Reason:         "PhaseTransition",
+		})
+	}
+
+	rr := httptest.NewRecorder()
+	server.handleScalingConfigActions(rr, httptest.NewRequest("GET", "/api/scaling/configs/backend/events", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %v: %s", rr.Code, rr.Body.String())
+	}
+	var events []corev1.Event
+	if err := json.NewDecoder(rr.Body).Decode(&events); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Name != "event-0" {
+		t.Fatalf("expected only the event of the backend config, got %+v", events)
+	}
+
+	rr = httptest.NewRecorder()
+	server.handleScalingConfigActions(rr, httptest.NewRequest("GET", "/api/scaling/configs/missing/events", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown config, got %v", rr.Code)
+	}
+}
+
+func TestHandleScalingGroupEvents(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(finopsv1.AddToScheme(scheme))
+	server := &Server{
+		Client: fake.NewClientBuilder().WithScheme(scheme).
+			WithIndex(&corev1.Event{}, eventInvolvedNameField, eventInvolvedName).
+			WithIndex(&corev1.Event{}, eventInvolvedKindField, eventInvolvedKind).
+			Build(),
+	}
+
+	ctx := context.Background()
+	group := &finopsv1.ScalingGroup{ObjectMeta: metav1.ObjectMeta{Name: "group-a", Namespace: "kubex"}}
+	server.Client.Create(ctx, group)
+	server.Client.Create(ctx, &finopsv1.ScalingGroup{ObjectMeta: metav1.ObjectMeta{Name: "group-b", Namespace: "kubex"}})
+
+	for i, target := range []corev1.ObjectReference{
+		{Kind: "ScalingGroup", Name: "group-a"},
+		{Kind: "ScalingGroup", Name: "group-a"},
+		{Kind: "ScalingGroup", Name: "group-b"},
+		{Kind: "ScalingConfig", Name: "group-a"},
+	} {
+		server.Client.Create(ctx, &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: fmt.Sprintf("event-%d", i), Namespace: "kubex"},
+			InvolvedObject: target,
+		})
+	}
+
+	req, _ := http.NewRequest("GET", "/api/scaling/groups/group-a/events", nil)
+	rr := httptest.NewRecorder()
+	server.handleScalingGroupActions(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %v: %s", rr.Code, rr.Body.String())
+	}
+	var events []corev1.Event
+	if err := json.NewDecoder(rr.Body).Decode(&events); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected the 2 events of group-a, got %d", len(events))
+	}
+	for _, e := range events {
+		if e.InvolvedObject.Kind != "ScalingGroup" || e.InvolvedObject.Name != "group-a" {
+			t.Errorf("unexpected event for %s/%s", e.InvolvedObject.Kind, e.InvolvedObject.Name)
+		}
+	}
+}
+
+func TestHandleScalingConflicts(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServer()
+	ctx := context.Background()
+	server.Client.Create(ctx, &finopsv1.ScalingGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "kubex"},
+		Spec:       finopsv1.ScalingGroupSpec{Namespaces: []string{"frontend", "backend"}},
+	})
+	server.Client.Create(ctx, &finopsv1.ScalingConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "frontend-hours", Namespace: "kubex"},
+		Spec:       finopsv1.ScalingConfigSpec{TargetNamespace: "frontend"},
+	})
+	server.Client.Create(ctx, &finopsv1.ScalingConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "billing-hours", Namespace: "kubex"},
+		Spec:       finopsv1.ScalingConfigSpec{TargetNamespace: "billing"},
+	})
+
+	rr := httptest.NewRecorder()
+	server.handleScalingConflicts(rr, httptest.NewRequest("GET", "/api/scaling/conflicts", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var conflicts []ScalingConflict
+	if err := json.NewDecoder(rr.Body).Decode(&conflicts); err != nil {
+		t.Fatal(err)
+	}
+	want := []ScalingConflict{
+		{Config: "billing-hours", TargetNamespace: "billing"},
+		{Config: "frontend-hours", TargetNamespace: "frontend", Overridden: true, Group: "shop"},
+	}
+	if fmt.Sprint(conflicts) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, conflicts)
+	}
+
+	rr = httptest.NewRecorder()
+	server.handleScalingConflicts(rr, httptest.NewRequest("POST", "/api/scaling/conflicts", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for POST, got %d", rr.Code)
+	}
+}
@@ -5,35 +5,178 @@ import (
 	"embed"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/fs"
+	"math"
 	"net/http"
+	"net/url"
 	"os"
 	"runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"go.uber.org/zap"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/duration"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+	"github.com/migalsp/kubex-operator/internal/podqos"
+	"github.com/migalsp/kubex-operator/internal/reconcilestats"
 	"github.com/migalsp/kubex-operator/internal/scaling"
+	"github.com/migalsp/kubex-operator/internal/watchns"
 )
 
-// Version is set at build time via ldflags
-var Version = "dev"
+// Build information, set at build time via ldflags
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)
+
+// Optimization strategies accepted by POST /api/namespaces/{ns}/optimize
+const (
+	strategyAverage = "average"
+	strategyP95     = "p95"
+)
+
+// Resource dimensions accepted by POST /api/namespaces/{ns}/optimize?resources=
+const (
+	resourcesBoth   = "both"
+	resourcesCPU    = "cpu"
+	resourcesMemory = "memory"
+)
+
+// optimizeOptions controls how containers are right-sized
+type optimizeOptions struct {
+	// resources is the dimension tuned, one of the resources* constants
+	resources string
+	// reqFactor and limitFactor multiply the observed usage into requests and limits
+	reqFactor, limitFactor float64
+	// cpuFloor and memFloor are the lowest requests ever set, limits are floored at the same
+	// values times limitFactor
+	cpuFloor, memFloor resource.Quantity
+	// bounds are the LimitRange constraints of the namespace computed values are clamped into
+	bounds containerBounds
+}
+
+// Default safety floors of the optimized requests
+const (
+	defaultCPUFloor    = "20m"
+	defaultMemoryFloor = "64Mi"
+)
+
+// defaultOptimizeOptions size requests at 1.3x and limits at 1.5x usage, never below the
+// default floors
+var defaultOptimizeOptions = optimizeOptions{
+	resources:   resourcesBoth,
+	reqFactor:   1.3,
+	limitFactor: 1.5,
+	cpuFloor:    resource.MustParse(defaultCPUFloor),
+	memFloor:    resource.MustParse(defaultMemoryFloor),
+}
+
+// parseOptimizeOptions reads ?resources=, ?reqFactor=, ?limitFactor=, ?cpuFloor= and
+// ?memFloor=, keeping the defaults for omitted parameters.
+func parseOptimizeOptions(q url.Values) (optimizeOptions, error) {
+	opts := defaultOptimizeOptions
+	if v := q.Get("resources"); v != "" {
+		if v != resourcesBoth && v != resourcesCPU && v != resourcesMemory {
+			return opts, fmt.Errorf("invalid resources, expected cpu, memory or both")
+		}
+		opts.resources = v
+	}
+
+	for _, f := range []struct {
+		name string
+		dst  *float64
+	}{{"reqFactor", &opts.reqFactor}, {"limitFactor", &opts.limitFactor}} {
+		v := q.Get(f.name)
+		if v == "" {
+			continue
+		}
+		factor, err := strconv.ParseFloat(v, 64)
+		if err != nil || factor <= 0 {
+			return opts, fmt.Errorf("invalid %s %q, expected a positive number", f.name, v)
+		}
+		*f.dst = factor
+	}
+	if opts.limitFactor < opts.reqFactor {
+		return opts, fmt.Errorf("limitFactor %g must not be lower than reqFactor %g", opts.limitFactor, opts.reqFactor)
+	}
+
+	for _, f := range []struct {
+		name string
+		dst  *resource.Quantity
+	}{{"cpuFloor", &opts.cpuFloor}, {"memFloor", &opts.memFloor}} {
+		v := q.Get(f.name)
+		if v == "" {
+			continue
+		}
+		floor, err := resource.ParseQuantity(v)
+		if err != nil || floor.Sign() < 0 {
+			return opts, fmt.Errorf("invalid %s %q, expected a quantity such as 20m or 64Mi", f.name, v)
+		}
+		*f.dst = floor
+	}
+	return opts, nil
+}
+
+// maxOptimizationHistory caps the optimization runs kept in NamespaceOptimization status
+const maxOptimizationHistory = 10
+
+// OptimizedByAnnotation is set on every optimized workload to the NamespaceOptimization
+// holding its original resources, as "<namespace>/<name>". Owner references cannot cross
+// namespaces, so this is the link back from the workload. Revert removes it.
+const OptimizedByAnnotation = "finops.kubex.io/optimized-by"
+
+// ExcludeContainersAnnotation lists, comma separated, the containers of a workload whose
+// resources optimizations never change, such as fixed-size sidecars.
+const ExcludeContainersAnnotation = "finops.kubex.io/optimize-exclude-containers"
 
 type Server struct {
 	Client        client.Client
 	K8sClient     kubernetes.Interface
 	MetricsClient metricsv.Interface
+	Recorder      record.EventRecorder
+	Cache         cache.Informers          // manager cache checked by /readyz, may be nil
+	LogLevel      *zap.AtomicLevel         // level of the operator logger, nil when it cannot be changed
+	Reconcilers   *reconcilestats.Recorder // reconcile stats of the controllers, may be nil
 	Port          string
-	history       []map[string]interface{}
+	// OptimizeCooldown is how long a namespace must wait between two applied optimizations,
+	// 0 for no cooldown
+	OptimizeCooldown time.Duration
+	// WatchNamespaces are the namespaces the API serves, every namespace when empty
+	WatchNamespaces watchns.Namespaces
+	history         []map[string]interface{}
+
+	// k8sVersion caches the Kubernetes server version reported by /api/version
+	k8sVersionMu sync.Mutex
+	k8sVersion   string
+
+	// nodeSummaries caches the responses of /api/cluster/nodes for nodeSummaryTTL, keyed by
+	// label selector
+	nodeSummaryMu sync.Mutex
+	nodeSummaries map[string]cachedNodeSummary
+}
+
+type cachedNodeSummary struct {
+	summary map[string]interface{}
+	at      time.Time
 }
 
 //go:embed ui/*
@@ -42,39 +185,69 @@ var uiFS embed.FS
 //go:embed openapi.yaml
 var openapiSpec []byte
 
-func (s *Server) Start(ctx context.Context) error {
-	log := logf.FromContext(ctx).WithName("api-server")
-
+// routes registers every API endpoint, with the embedded UI served for any other path.
+func (s *Server) routes() (*http.ServeMux, error) {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/api/namespaces", s.handleNamespaces)
 	mux.HandleFunc("/api/namespaces/", s.handleNamespaceRouting)
+	mux.HandleFunc("/api/namespaces/history.csv", s.handleHistoryCSV)
+	mux.HandleFunc("/api/optimization/summary", s.handleOptimizationSummary)
 	mux.HandleFunc("/api/cluster-info", s.handleClusterInfo)
 	mux.HandleFunc("/api/operator/health", s.handleOperatorHealth)
 	mux.HandleFunc("/api/operator/logs", s.handleOperatorLogs)
 	mux.HandleFunc("/api/operator/logs/download", s.handleOperatorLogsDownload)
+	mux.HandleFunc("/api/operator/logs/stream", s.handleOperatorLogsStream)
+	mux.HandleFunc("/api/operator/loglevel", s.handleLogLevel)
+	mux.HandleFunc("/api/operator/reconcilers", s.handleOperatorReconcilers)
 	mux.HandleFunc("/api/scaling/groups", s.handleScalingGroups)
 	mux.HandleFunc("/api/scaling/groups/", s.handleScalingGroupActions)
 	mux.HandleFunc("/api/scaling/configs", s.handleScalingConfigs)
 	mux.HandleFunc("/api/scaling/configs/", s.handleScalingConfigActions)
+	mux.HandleFunc("/api/scaling/validate", s.handleScalingValidate)
+	mux.HandleFunc("/api/scaling/simulate", s.handleScalingSimulate)
+	mux.HandleFunc("/api/scaling/conflicts", s.handleScalingConflicts)
+	mux.HandleFunc("/api/scaling/emergency-restore", s.handleEmergencyRestore)
+	mux.HandleFunc("/api/scaling/freeze", s.handleScalingFreeze)
+	mux.HandleFunc("/api/scaling/export", s.handleScalingExport)
+	mux.HandleFunc("/api/scaling/import", s.handleScalingImport)
 	mux.HandleFunc("/api/discovery/", s.handleDiscovery)
 	mux.HandleFunc("/api/version", s.handleVersion)
 	mux.HandleFunc("/api/cluster/nodes", s.handleClusterNodes)
+	mux.HandleFunc("/api/cluster/finops-score", s.handleClusterFinOpsScore)
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
 	mux.HandleFunc("/api/login", HandleLogin)
 	mux.HandleFunc("/api/logout", HandleLogout)
+	mux.HandleFunc("/api/sessions/revoke", s.handleRevokeSessions)
 	mux.HandleFunc("/api/openapi.yaml", handleOpenAPISpec)
 	mux.HandleFunc("/api/docs", handleSwaggerUI)
 
 	// Setup embedded filesystem for React UI
 	sub, err := fs.Sub(uiFS, "ui")
 	if err != nil {
-		return err
+		return nil, err
 	}
 	fileServer := http.FileServer(http.FS(sub))
 	mux.Handle("/", fileServer)
+	return mux, nil
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("api-server")
+
+	if err := s.loadSessionEpoch(ctx); err != nil {
+		log.Error(err, "Failed to load the session epoch")
+	}
+
+	mux, err := s.routes()
+	if err != nil {
+		return err
+	}
 
-	// Wrap with auth middleware
-	handler := AuthMiddleware(mux)
+	// Wrap with auth middleware, CORS preflights are answered before authentication and
+	// API responses are compressed for clients accepting gzip
+	handler := GzipMiddleware(CORSMiddleware(AuthMiddleware(mux)))
 
 	addr := ":" + s.Port
 	if s.Port == "" {
@@ -103,32 +276,33 @@ func (s *Server) Start(ctx context.Context) error {
 
 func (s *Server) handleNamespaces(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	var list finopsv1.NamespaceFinOpsList
 	if err := s.Client.List(r.Context(), &list); err != nil {
 		logf.Log.Error(err, "Failed to list NamespaceFinOps")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	logf.Log.Info("Found NamespaceFinOps", "count", len(list.Items))
+	items := s.watchedNamespaceFinOps(list.Items)
+	logf.Log.Info("Found NamespaceFinOps", "count", len(items))
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(list.Items)
+	json.NewEncoder(w).Encode(items)
 }
 
 func (s *Server) handleDiscovery(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	parts := strings.Split(r.URL.Path, "/")
 	// Expected path: /api/discovery/{provider}/{resourceType}
 	if len(parts) < 5 {
-		http.Error(w, "Invalid path format. Expected /api/discovery/{provider}/{type}", http.StatusBadRequest)
+		writeJSONError(w, "Invalid path format. Expected /api/discovery/{provider}/{type}", http.StatusBadRequest)
 		return
 	}
 
@@ -137,7 +311,7 @@ func (s *Server) handleDiscovery(w http.ResponseWriter, r *http.Request) {
 
 	// Currently only "aws" is implemented, but we design for extension
 	if providerName != "aws" {
-		http.Error(w, fmt.Sprintf("Provider '%s' not supported yet", providerName), http.StatusNotImplemented)
+		writeJSONError(w, fmt.Sprintf("Provider '%s' not supported yet", providerName), http.StatusNotImplemented)
 		return
 	}
 
@@ -151,14 +325,14 @@ func (s *Server) handleDiscovery(w http.ResponseWriter, r *http.Request) {
 	awsProv, err := scaling.NewAWSProvider(r.Context())
 	if err != nil {
 		logf.Log.Error(err, "Failed to initialize AWS Discovery provider")
-		http.Error(w, "Cloud provider configuration error", http.StatusInternalServerError)
+		writeJSONError(w, "Cloud provider configuration error", http.StatusInternalServerError)
 		return
 	}
 
 	targets, err := awsProv.Discover(r.Context(), resourceType)
 	if err != nil {
 		logf.Log.Error(err, "Failed to discover resources", "provider", providerName, "type", resourceType)
-		http.Error(w, "Failed to discover external resources", http.StatusInternalServerError)
+		writeJSONError(w, "Failed to discover external resources", http.StatusInternalServerError)
 		return
 	}
 
@@ -167,75 +341,114 @@ func (s *Server) handleDiscovery(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleNamespaceRouting(w http.ResponseWriter, r *http.Request) {
-	parts := strings.Split(r.URL.Path, "/")
 	// Expected paths:
 	// /api/namespaces/{ns}/history
 	// /api/namespaces/{ns}/pods
-	if len(parts) < 5 {
-		http.Error(w, "Invalid path", http.StatusBadRequest)
+	// /api/namespaces/{ns}/workloads/metrics
+	segments, ok := pathSegments(r.URL.Path, "/api/namespaces/")
+	if !ok || len(segments) < 2 {
+		writeJSONError(w, "Invalid path, expected /api/namespaces/{ns}/{action}", http.StatusBadRequest)
 		return
 	}
 
-	nsName := parts[3]
-	action := parts[4]
-
-	switch action {
-	case "history":
-		s.serveHistory(w, r, nsName)
-	case "pods":
-		s.servePods(w, r, nsName)
-	case "workloads":
-		if len(parts) >= 6 {
-			s.serveWorkloadAction(w, r, nsName, parts[5])
-		} else {
-			s.serveWorkloads(w, r, nsName)
-		}
-	case "optimize":
-		s.handleNamespaceOptimize(w, r, nsName)
-	case "revert":
-		s.handleNamespaceRevert(w, r, nsName)
-	case "optimization":
-		s.handleNamespaceOptimizationInfo(w, r, nsName)
-	default:
-		http.Error(w, "Invalid action", http.StatusBadRequest)
+	nsName, action := segments[0], segments[1]
+	if !s.WatchNamespaces.Allows(nsName) {
+		writeJSONError(w, "Namespace "+nsName+" is not watched by the operator", http.StatusForbidden)
+		return
+	}
+	handler, found := s.namespaceRoute(r, action, segments[2:])
+	if !found {
+		writeJSONError(w, "Unknown namespace action: "+strings.Join(segments[1:], "/"), http.StatusNotFound)
+		return
 	}
+	handler(w, r, nsName)
 }
 
 func (s *Server) serveHistory(w http.ResponseWriter, r *http.Request, nsName string) {
-	operatorNs := os.Getenv("POD_NAMESPACE")
-	if operatorNs == "" {
-		operatorNs = "kubex"
+	history, ok := s.namespaceHistory(w, r, nsName)
+	if !ok {
+		return
 	}
 
-	var nsFinOps finopsv1.NamespaceFinOps
-	if err := s.Client.Get(r.Context(), client.ObjectKey{Name: nsName, Namespace: operatorNs}, &nsFinOps); err != nil {
-		if errors.IsNotFound(err) {
-			// Fallback: try to find by targetNamespace field
-			var list finopsv1.NamespaceFinOpsList
-			if err := s.Client.List(r.Context(), &list); err == nil {
-				found := false
-				for _, item := range list.Items {
-					if item.Spec.TargetNamespace == nsName {
-						nsFinOps = item
-						found = true
-						break
-					}
-				}
-				if !found {
-					http.Error(w, "Not found", http.StatusNotFound)
-					return
-				}
-			} else {
-				http.Error(w, "Not found", http.StatusNotFound)
-				return
-			}
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// namespaceHistory returns the usage history of a namespace at the resolution requested by
+// ?resolution=, writing the error response when it fails.
+func (s *Server) namespaceHistory(w http.ResponseWriter, r *http.Request, nsName string) ([]finopsv1.MetricDataPoint, bool) {
+	resolution, ok := historyResolution(w, r)
+	if !ok {
+		return nil, false
+	}
+	from, to, ok := historyRange(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	nsFinOps, err := s.resolveNamespaceFinOps(r.Context(), nsName)
+	if errors.IsNotFound(err) {
+		writeJSONError(w, "Not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	return downsampleHistory(filterHistory(nsFinOps.Status.History, from, to), resolution), true
+}
+
+// historyResolution parses ?resolution=, zero meaning the raw per-minute points.
+func historyResolution(w http.ResponseWriter, r *http.Request) (time.Duration, bool) {
+	res := r.URL.Query().Get("resolution")
+	if res == "" {
+		return 0, true
+	}
+	resolution, err := time.ParseDuration(res)
+	if err != nil || resolution < time.Minute {
+		writeJSONError(w, "Invalid resolution, expected a duration of at least 1m such as 5m", http.StatusBadRequest)
+		return 0, false
+	}
+	return resolution, true
+}
+
+// historyRange parses the ?from= and ?to= RFC 3339 bounds, or ?last= as a duration ending
+// now. A zero bound leaves that side of the window open.
+func historyRange(w http.ResponseWriter, r *http.Request) (from, to time.Time, ok bool) {
+	q := r.URL.Query()
+	if last := q.Get("last"); last != "" {
+		if q.Get("from") != "" {
+			writeJSONError(w, "Use either last or from, not both", http.StatusBadRequest)
+			return time.Time{}, time.Time{}, false
+		}
+		d, err := time.ParseDuration(last)
+		if err != nil || d <= 0 {
+			writeJSONError(w, "Invalid last, expected a positive duration such as 30m", http.StatusBadRequest)
+			return time.Time{}, time.Time{}, false
 		}
+		from = time.Now().Add(-d)
+	}
+	for _, bound := range []struct {
+		name string
+		t    *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		v := q.Get(bound.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeJSONError(w, "Invalid "+bound.name+", expected an RFC 3339 timestamp such as 2024-05-01T10:00:00Z", http.StatusBadRequest)
+			return time.Time{}, time.Time{}, false
+		}
+		*bound.t = t
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(nsFinOps.Status.History)
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		writeJSONError(w, "Invalid range, to is before from", http.StatusBadRequest)
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
 }
 
 type PodDetail struct {
@@ -243,6 +456,13 @@ type PodDetail struct {
 	Status string                   `json:"status"`
 	CPU    finopsv1.ResourceMetrics `json:"cpu"`
 	Memory finopsv1.ResourceMetrics `json:"memory"`
+	// RestartCount is summed over the container statuses
+	RestartCount int32 `json:"restartCount"`
+	// Age is how long ago the pod was created, formatted like kubectl (e.g. 5d3h)
+	Age      string `json:"age"`
+	NodeName string `json:"nodeName,omitempty"`
+	// QOSClass is Guaranteed, Burstable or BestEffort
+	QOSClass string `json:"qosClass"`
 }
 
 func (s *Server) servePods(w http.ResponseWriter, r *http.Request, nsName string) {
@@ -268,7 +488,7 @@ func (s *Server) servePods(w http.ResponseWriter, r *http.Request, nsName string
 
 	var podList corev1.PodList
 	if err := s.Client.List(ctx, &podList, client.InNamespace(nsName)); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -291,9 +511,18 @@ func (s *Server) servePods(w http.ResponseWriter, r *http.Request, nsName string
 			memU = "0"
 		}
 
+		var restarts int32
+		for _, cs := range p.Status.ContainerStatuses {
+			restarts += cs.RestartCount
+		}
+
 		details = append(details, PodDetail{
-			Name:   p.Name,
-			Status: string(p.Status.Phase),
+			Name:         p.Name,
+			Status:       string(p.Status.Phase),
+			RestartCount: restarts,
+			Age:          duration.HumanDuration(time.Since(p.CreationTimestamp.Time)),
+			NodeName:     p.Spec.NodeName,
+			QOSClass:     string(podqos.Class(&p)),
 			CPU: finopsv1.ResourceMetrics{
 				Usage:    cpuU,
 				Requests: cpuReq.String(),
@@ -311,22 +540,51 @@ func (s *Server) servePods(w http.ResponseWriter, r *http.Request, nsName string
 	json.NewEncoder(w).Encode(details)
 }
 
+// nodeSummaryTTL is how long the cluster node summary is shared between requests, listing
+// every pod of a large cluster on each dashboard refresh is expensive
+const nodeSummaryTTL = 15 * time.Second
+
 func (s *Server) handleClusterNodes(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	ctx := r.Context()
+	selector, err := labels.Parse(r.URL.Query().Get("labelSelector"))
+	if err != nil {
+		writeJSONError(w, "Invalid labelSelector: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response, err := s.clusterNodeSummary(r.Context(), selector)
+	if err != nil {
+		writeJSONError(w, "Failed to list nodes: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// clusterNodeSummary returns the summary of the nodes matching selector computed within
+// the last nodeSummaryTTL, or computes it. Totals only cover the matching nodes.
+// Concurrent requests wait for a single computation instead of each listing the cluster.
+func (s *Server) clusterNodeSummary(ctx context.Context, selector labels.Selector) (map[string]interface{}, error) {
+	key := selector.String()
+	s.nodeSummaryMu.Lock()
+	defer s.nodeSummaryMu.Unlock()
+	if cached, ok := s.nodeSummaries[key]; ok && time.Since(cached.at) < nodeSummaryTTL {
+		return cached.summary, nil
+	}
+
 	version, err := s.K8sClient.Discovery().ServerVersion()
 	if err != nil {
 		logf.Log.Error(err, "Failed to get k8s version")
 	}
 
-	nodes, err := s.K8sClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	nodes, err := s.K8sClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: key})
 	if err != nil {
-		http.Error(w, "Failed to list nodes: "+err.Error(), http.StatusInternalServerError)
-		return
+		return nil, err
 	}
 
 	nodeMetricsMap := make(map[string]corev1.ResourceList)
@@ -341,61 +599,107 @@ func (s *Server) handleClusterNodes(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	var podItems []corev1.Pod
 	pods, err := s.K8sClient.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
 	if err != nil {
 		logf.Log.Error(err, "Failed to list pods for calculating node capacity requests")
+	} else {
+		podItems = pods.Items
 	}
 
-	nodeReqCPU := make(map[string]*resource.Quantity)
-	nodeReqMem := make(map[string]*resource.Quantity)
+	response := buildNodeSummary(nodes.Items, nodeMetricsMap, podItems)
+	response["k8sVersion"] = "unknown"
+	if version != nil {
+		response["k8sVersion"] = version.GitVersion
+	}
 
-	if pods != nil {
-		for _, pod := range pods.Items {
-			if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
-				continue
-			}
+	if s.nodeSummaries == nil {
+		s.nodeSummaries = make(map[string]cachedNodeSummary)
+	}
+	// Drop expired selectors so one-off filters do not accumulate
+	for k, cached := range s.nodeSummaries {
+		if time.Since(cached.at) >= nodeSummaryTTL {
+			delete(s.nodeSummaries, k)
+		}
+	}
+	s.nodeSummaries[key] = cachedNodeSummary{summary: response, at: time.Now()}
+	return response, nil
+}
 
-			reqCPU := resource.NewQuantity(0, resource.DecimalSI)
-			reqMem := resource.NewQuantity(0, resource.BinarySI)
+// nodeRequests are the summed pod requests scheduled on a node
+type nodeRequests struct {
+	cpu, mem resource.Quantity
+}
 
-			for _, container := range pod.Spec.Containers {
-				if q, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
-					reqCPU.Add(q)
-				}
-				if q, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
-					reqMem.Add(q)
-				}
-			}
+// requestsByNode sums the requests of the running pods per node in a single pass
+func requestsByNode(pods []corev1.Pod) map[string]*nodeRequests {
+	byNode := make(map[string]*nodeRequests)
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
 
-			// Pod request is max of any init container request vs sum of app container requests
-			for _, container := range pod.Spec.InitContainers {
-				if q, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
-					if q.Cmp(*reqCPU) > 0 {
-						reqCPU = &q // use copy to prevent pointer sharing issues, actually q is by value in range, safe
-					}
-				}
-				if q, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
-					if q.Cmp(*reqMem) > 0 {
-						reqMem = &q
-					}
-				}
-			}
+		reqCPU, reqMem := podRequests(pod)
 
-			if _, ok := nodeReqCPU[pod.Spec.NodeName]; !ok {
-				nodeReqCPU[pod.Spec.NodeName] = resource.NewQuantity(0, resource.DecimalSI)
-				nodeReqMem[pod.Spec.NodeName] = resource.NewQuantity(0, resource.BinarySI)
-			}
-			nodeReqCPU[pod.Spec.NodeName].Add(*reqCPU)
-			nodeReqMem[pod.Spec.NodeName].Add(*reqMem)
+		req, ok := byNode[pod.Spec.NodeName]
+		if !ok {
+			req = &nodeRequests{}
+			byNode[pod.Spec.NodeName] = req
+		}
+		req.cpu.Add(reqCPU)
+		req.mem.Add(reqMem)
+	}
+	return byNode
+}
+
+// podRequests returns the effective requests the scheduler reserves for a pod: the sum of
+// its app containers, or the largest init container when that is higher. Sidecars, init
+// containers with restartPolicy Always, keep running next to the init containers started
+// after them and the app containers, so they count towards both.
+func podRequests(pod *corev1.Pod) (cpu, mem resource.Quantity) {
+	var sidecarCPU, sidecarMem, initCPU, initMem resource.Quantity
+	for _, c := range pod.Spec.InitContainers {
+		reqCPU := c.Resources.Requests.Cpu().DeepCopy()
+		reqMem := c.Resources.Requests.Memory().DeepCopy()
+		reqCPU.Add(sidecarCPU)
+		reqMem.Add(sidecarMem)
+		if reqCPU.Cmp(initCPU) > 0 {
+			initCPU = reqCPU
+		}
+		if reqMem.Cmp(initMem) > 0 {
+			initMem = reqMem
 		}
+		if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			sidecarCPU.Add(*c.Resources.Requests.Cpu())
+			sidecarMem.Add(*c.Resources.Requests.Memory())
+		}
+	}
+
+	cpu, mem = sidecarCPU, sidecarMem
+	for _, c := range pod.Spec.Containers {
+		cpu.Add(*c.Resources.Requests.Cpu())
+		mem.Add(*c.Resources.Requests.Memory())
+	}
+	if initCPU.Cmp(cpu) > 0 {
+		cpu = initCPU
 	}
+	if initMem.Cmp(mem) > 0 {
+		mem = initMem
+	}
+	return cpu, mem
+}
+
+// buildNodeSummary computes the per-node and cluster totals of GET /api/cluster/nodes
+func buildNodeSummary(nodes []corev1.Node, nodeMetricsMap map[string]corev1.ResourceList, pods []corev1.Pod) map[string]interface{} {
+	nodeReqs := requestsByNode(pods)
 
 	var totalCapacityCPU, totalCapacityMem resource.Quantity
 	var totalUsageCPU, totalUsageMem resource.Quantity
 	var totalRequestedCPU, totalRequestedMem resource.Quantity
-	var nodeInfos []map[string]interface{}
+	nodeInfos := make([]map[string]interface{}, 0, len(nodes))
 
-	for _, n := range nodes.Items {
+	for _, n := range nodes {
 		capacity := n.Status.Allocatable // Use Allocatable instead of absolute Capacity for true limits
 		totalCapacityCPU.Add(*capacity.Cpu())
 		totalCapacityMem.Add(*capacity.Memory())
@@ -407,11 +711,9 @@ func (s *Server) handleClusterNodes(w http.ResponseWriter, r *http.Request) {
 		}
 
 		var rCPU, rMem resource.Quantity
-		if q, ok := nodeReqCPU[n.Name]; ok {
-			rCPU = *q
-		}
-		if q, ok := nodeReqMem[n.Name]; ok {
-			rMem = *q
+		if req, ok := nodeReqs[n.Name]; ok {
+			rCPU = req.cpu
+			rMem = req.mem
 		}
 
 		totalUsageCPU.Add(uCPU)
@@ -453,13 +755,7 @@ func (s *Server) handleClusterNodes(w http.ResponseWriter, r *http.Request) {
 		nodeInfos = append(nodeInfos, nodeInfo)
 	}
 
-	k8sVer := "unknown"
-	if version != nil {
-		k8sVer = version.GitVersion
-	}
-
-	response := map[string]interface{}{
-		"k8sVersion": k8sVer,
+	return map[string]interface{}{
 		"totalCapacity": map[string]interface{}{
 			"cpu": totalCapacityCPU.AsApproximateFloat64(),
 			"mem": totalCapacityMem.Value(),
@@ -474,20 +770,17 @@ func (s *Server) handleClusterNodes(w http.ResponseWriter, r *http.Request) {
 		},
 		"nodes": nodeInfos,
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
 }
 
 func (s *Server) handleClusterInfo(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	version, err := s.K8sClient.Discovery().ServerVersion()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -499,6 +792,18 @@ func (s *Server) handleClusterInfo(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(info)
 }
+
+// handleOperatorReconcilers reports the reconcile count, errors and durations of every
+// controller since the operator started.
+func (s *Server) handleOperatorReconcilers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.Reconcilers.Snapshot())
+}
+
 func (s *Server) handleOperatorHealth(w http.ResponseWriter, r *http.Request) {
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
@@ -571,27 +876,77 @@ func (s *Server) handleOperatorHealth(w http.ResponseWriter, r *http.Request) {
 		"current": health,
 		"history": s.history,
 	}
+	// The freeze is not part of the samples, the UI shows it as a banner
+	if freeze, err := s.loadScalingFreeze(r.Context()); err == nil {
+		response["scalingFreeze"] = freeze
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-func (s *Server) handleOperatorLogs(w http.ResponseWriter, r *http.Request) {
-	podName := os.Getenv("HOSTNAME")
-	podNs := os.Getenv("POD_NAMESPACE")
+// operatorLogOptions builds the log options for the operator pod from the request: the
+// ?container= query parameter, defaulting to the first container of the pod, and, when
+// withTail is set, ?tailLines= defaulting to 100. It writes the error response itself
+// and returns ok=false when the request is invalid.
+func (s *Server) operatorLogOptions(w http.ResponseWriter, r *http.Request, withTail bool) (podNs, podName string, opts *corev1.PodLogOptions, ok bool) {
+	podName = os.Getenv("HOSTNAME")
+	podNs = os.Getenv("POD_NAMESPACE")
 	if podName == "" || podNs == "" {
-		http.Error(w, "Operator environment not detected (HOSTNAME/POD_NAMESPACE missing)", http.StatusInternalServerError)
+		writeJSONError(w, "Operator environment not detected (HOSTNAME/POD_NAMESPACE missing)", http.StatusInternalServerError)
+		return "", "", nil, false
+	}
+
+	opts = &corev1.PodLogOptions{}
+	if withTail {
+		tailLines := int64(100)
+		if v := r.URL.Query().Get("tailLines"); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n < 0 {
+				writeJSONError(w, "Invalid tailLines: "+v, http.StatusBadRequest)
+				return "", "", nil, false
+			}
+			tailLines = n
+		}
+		opts.TailLines = &tailLines
+	}
+
+	container := r.URL.Query().Get("container")
+	pod, err := s.K8sClient.CoreV1().Pods(podNs).Get(r.Context(), podName, metav1.GetOptions{})
+	if err != nil {
+		// Without the pod spec the container cannot be checked; leave it to the API server
+		logf.Log.Error(err, "Failed to read operator pod", "pod", podName)
+		opts.Container = container
+		return podNs, podName, opts, true
+	}
+
+	var names []string
+	for _, c := range pod.Spec.Containers {
+		names = append(names, c.Name)
+	}
+	if container == "" {
+		if len(names) > 0 {
+			container = names[0]
+		}
+	} else if !slices.Contains(names, container) {
+		writeJSONError(w, fmt.Sprintf("Unknown container %q, available containers: %s", container, strings.Join(names, ", ")), http.StatusBadRequest)
+		return "", "", nil, false
+	}
+	opts.Container = container
+	return podNs, podName, opts, true
+}
+
+func (s *Server) handleOperatorLogs(w http.ResponseWriter, r *http.Request) {
+	podNs, podName, opts, ok := s.operatorLogOptions(w, r, true)
+	if !ok {
 		return
 	}
 
-	tailLines := int64(100)
-	req := s.K8sClient.CoreV1().Pods(podNs).GetLogs(podName, &corev1.PodLogOptions{
-		TailLines: &tailLines,
-	})
+	req := s.K8sClient.CoreV1().Pods(podNs).GetLogs(podName, opts)
 
 	logs, err := req.DoRaw(r.Context())
 	if err != nil {
-		http.Error(w, "Failed to fetch logs: "+err.Error(), http.StatusInternalServerError)
+		writeJSONError(w, "Failed to fetch logs: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -600,17 +955,15 @@ func (s *Server) handleOperatorLogs(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleOperatorLogsDownload(w http.ResponseWriter, r *http.Request) {
-	podName := os.Getenv("HOSTNAME")
-	podNs := os.Getenv("POD_NAMESPACE")
-	if podName == "" || podNs == "" {
-		http.Error(w, "Operator environment not detected", http.StatusInternalServerError)
+	podNs, podName, opts, ok := s.operatorLogOptions(w, r, false)
+	if !ok {
 		return
 	}
 
-	req := s.K8sClient.CoreV1().Pods(podNs).GetLogs(podName, &corev1.PodLogOptions{})
+	req := s.K8sClient.CoreV1().Pods(podNs).GetLogs(podName, opts)
 	logs, err := req.DoRaw(r.Context())
 	if err != nil {
-		http.Error(w, "Failed to fetch logs", http.StatusInternalServerError)
+		writeJSONError(w, "Failed to fetch logs", http.StatusInternalServerError)
 		return
 	}
 
@@ -619,6 +972,55 @@ func (s *Server) handleOperatorLogsDownload(w http.ResponseWriter, r *http.Reque
 	w.Write(logs)
 }
 
+// handleOperatorLogsStream follows the operator logs, flushing every chunk to the client
+// as it arrives. The log request is bound to the request context, so it is cancelled as
+// soon as the client disconnects.
+func (s *Server) handleOperatorLogsStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	podNs, podName, opts, ok := s.operatorLogOptions(w, r, true)
+	if !ok {
+		return
+	}
+	opts.Follow = true
+
+	stream, err := s.K8sClient.CoreV1().Pods(podNs).GetLogs(podName, opts).Stream(r.Context())
+	if err != nil {
+		writeJSONError(w, "Failed to stream logs: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer stream.Close()
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Keeps browsers from buffering the response to sniff its type
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	buf := make([]byte, 4096)
+	for {
+		n, err := stream.Read(buf)
+		if n > 0 {
+			if _, writeErr := w.Write(buf[:n]); writeErr != nil {
+				return
+			}
+			if flushErr := rc.Flush(); flushErr != nil {
+				return
+			}
+		}
+		if err != nil {
+			if err != io.EOF && r.Context().Err() == nil {
+				logf.Log.Error(err, "Operator log stream interrupted")
+			}
+			return
+		}
+	}
+}
+
 type WorkloadDetail struct {
 	Name          string `json:"name"`
 	Kind          string `json:"kind"`
@@ -679,7 +1081,7 @@ func (s *Server) serveWorkloads(w http.ResponseWriter, r *http.Request, nsName s
 
 func (s *Server) serveWorkloadAction(w http.ResponseWriter, r *http.Request, nsName string, workloadName string) {
 	if r.Method != http.MethodPut {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -689,445 +1091,1037 @@ func (s *Server) serveWorkloadAction(w http.ResponseWriter, r *http.Request, nsN
 		Replicas int32  `json:"replicas"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	switch req.Kind {
-	case "Deployment":
-		deploy := &appsv1.Deployment{}
-		if err := s.Client.Get(ctx, client.ObjectKey{Name: workloadName, Namespace: nsName}, deploy); err != nil {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		deploy.Spec.Replicas = &req.Replicas
-		if err := s.Client.Update(ctx, deploy); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-	case "StatefulSet":
-		ss := &appsv1.StatefulSet{}
-		if err := s.Client.Get(ctx, client.ObjectKey{Name: workloadName, Namespace: nsName}, ss); err != nil {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		ss.Spec.Replicas = &req.Replicas
-		if err := s.Client.Update(ctx, ss); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-	default:
-		http.Error(w, "Unknown kind", http.StatusBadRequest)
+	if newScalableWorkload(req.Kind) == nil {
+		writeJSONError(w, "Unknown kind", http.StatusBadRequest)
+		return
+	}
+	obj, err := s.scaleWorkload(ctx, nsName, req.Kind, workloadName, req.Replicas)
+	if errors.IsNotFound(err) {
+		writeJSONError(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.audit(r, auditScaleWorkload, nsName, workloadName, obj)
 
 	w.WriteHeader(http.StatusOK)
 }
 
 func (s *Server) handleNamespaceOptimize(w http.ResponseWriter, r *http.Request, nsName string) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	strategy := r.URL.Query().Get("strategy")
+	if strategy == "" {
+		strategy = strategyAverage
+	}
+	if strategy != strategyAverage && strategy != strategyP95 {
+		writeJSONError(w, "Unknown optimization strategy: "+strategy, http.StatusBadRequest)
+		return
+	}
+	opts, err := parseOptimizeOptions(r.URL.Query())
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	dryRun := r.URL.Query().Get("dryRun") == "true"
+	propose := r.URL.Query().Get("propose") == "true"
 
 	ctx := r.Context()
 	operatorNs := getOperatorNamespace()
 
-	// 1. Calculate Average Usage from NamespaceFinOps (last 60 mins)
-	var finOps finopsv1.NamespaceFinOps
-	if err := s.Client.Get(ctx, client.ObjectKey{Name: nsName, Namespace: operatorNs}, &finOps); err != nil {
-		http.Error(w, "NamespaceFinOps not found: "+err.Error(), http.StatusNotFound)
+	// Every applied run restarts the workloads, back-to-back runs are refused
+	if !dryRun && !propose && s.OptimizeCooldown > 0 {
+		opt, err := s.resolveNamespaceOptimization(ctx, nsName)
+		if err != nil && !errors.IsNotFound(err) {
+			writeJSONError(w, "Failed to read optimization record: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err == nil {
+			if remaining := s.optimizeCooldownRemaining(opt.Status.OptimizedAt); remaining > 0 {
+				writeOptimizeCooldown(w, nsName, remaining)
+				return
+			}
+		}
+	}
+
+	// Usage read while workloads are scaled down would size everything to the floor
+	phase, err := s.namespaceScalingPhase(ctx, operatorNs, nsName)
+	if err != nil {
+		writeJSONError(w, "Failed to read scaling state: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if phase != "" && phase != "ScaledUp" {
+		writeScalingConflict(w, nsName, phase)
+		return
+	}
+
+	// 1. Calculate baseline usage from NamespaceFinOps history (whole retained window)
+	finOps, err := s.resolveNamespaceFinOps(ctx, nsName)
+	if errors.IsNotFound(err) {
+		writeJSONError(w, "NamespaceFinOps not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	if len(finOps.Status.History) == 0 {
-		http.Error(w, "No history available for optimization", http.StatusBadRequest)
+		writeJSONError(w, "No history available for optimization", http.StatusBadRequest)
 		return
 	}
 
-	var totalCpuAv, totalMemAv float64
+	cpuSamples := make([]float64, 0, len(finOps.Status.History))
+	memSamples := make([]float64, 0, len(finOps.Status.History))
 	for _, dp := range finOps.Status.History {
 		cpuQ, _ := resource.ParseQuantity(dp.CPU.Usage)
 		memQ, _ := resource.ParseQuantity(dp.Memory.Usage)
-		totalCpuAv += cpuQ.AsApproximateFloat64()
-		totalMemAv += float64(memQ.Value())
+		cpuSamples = append(cpuSamples, cpuQ.AsApproximateFloat64())
+		memSamples = append(memSamples, float64(memQ.Value()))
 	}
-	avgCpuNs := totalCpuAv / float64(len(finOps.Status.History))
-	avgMemNs := totalMemAv / float64(len(finOps.Status.History))
+	baselineCpuNs := aggregateUsage(cpuSamples, strategy)
+	baselineMemNs := aggregateUsage(memSamples, strategy)
 
 	// 2. Get current individual usage from Metrics API
 	if s.MetricsClient == nil {
-		http.Error(w, "Metrics API is not available", http.StatusInternalServerError)
+		writeJSONError(w, "Metrics server not available", http.StatusServiceUnavailable)
 		return
 	}
 	podMetricsList, err := s.MetricsClient.MetricsV1beta1().PodMetricses(nsName).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		http.Error(w, "Failed to get metrics: "+err.Error(), http.StatusInternalServerError)
+		writeJSONError(w, "Failed to get metrics: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	var currentCpuNs, currentMemNs float64
-	workloadUsage := make(map[string]float64) // key: KIND/NAME
-	workloadMemUsage := make(map[string]float64)
+	unownedPods := 0
+	workloadUsage := make(map[string]map[string]float64) // key: KIND/NAME -> container name
+	workloadMemUsage := make(map[string]map[string]float64)
 
 	for _, pm := range podMetricsList.Items {
 		// Find owner
-		var workloadName, workloadKind string
-		for _, or := range pm.OwnerReferences {
-			if or.Kind == "ReplicaSet" {
-				// Get RS to find Deployment
-				var rs appsv1.ReplicaSet
-				if err := s.Client.Get(ctx, client.ObjectKey{Name: or.Name, Namespace: nsName}, &rs); err == nil {
-					for _, rsor := range rs.OwnerReferences {
-						if rsor.Kind == "Deployment" {
-							workloadName = rsor.Name
-							workloadKind = "Deployment"
-						}
-					}
-				}
-			} else if or.Kind == "StatefulSet" {
-				workloadName = or.Name
-				workloadKind = "StatefulSet"
-			}
-		}
-
+		workloadKind, workloadName := s.workloadOwner(ctx, nsName, pm.OwnerReferences)
 		if workloadName == "" {
+			unownedPods++
 			continue
 		}
 
 		key := workloadKind + "/" + workloadName
+		if workloadUsage[key] == nil {
+			workloadUsage[key] = make(map[string]float64)
+			workloadMemUsage[key] = make(map[string]float64)
+		}
 		for _, c := range pm.Containers {
 			cpu := c.Usage.Cpu().AsApproximateFloat64()
 			mem := float64(c.Usage.Memory().Value())
 			currentCpuNs += cpu
 			currentMemNs += mem
-			workloadUsage[key] += cpu
-			workloadMemUsage[key] += mem
+			workloadUsage[key][c.Name] += cpu
+			workloadMemUsage[key][c.Name] += mem
 		}
 	}
 
 	// 3. Compute Correction Factor
 	cpuFactor := 1.0
 	if currentCpuNs > 0 {
-		cpuFactor = avgCpuNs / currentCpuNs
+		cpuFactor = baselineCpuNs / currentCpuNs
 	}
 	memFactor := 1.0
 	if currentMemNs > 0 {
-		memFactor = avgMemNs / currentMemNs
+		memFactor = baselineMemNs / currentMemNs
+	}
+
+	// 4. Compute the optimized workloads, clamped into the namespace LimitRanges
+	opts.bounds, err = s.namespaceContainerBounds(ctx, nsName)
+	if err != nil {
+		writeJSONError(w, "Failed to read LimitRanges: "+err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	// 4. Update Workloads and Store Optimization Info
-	optimizedWorkloads := []finopsv1.WorkloadOptimization{}
+	run := newOptimizationRun(workloadUsage, workloadMemUsage, cpuFactor, memFactor, opts)
 
-	// Process Deployments
+	// Lists come back in no particular order, sizing by kind then name keeps the optimized
+	// and skipped workloads in a stable order
 	deploys := &appsv1.DeploymentList{}
 	s.Client.List(ctx, deploys, client.InNamespace(nsName))
-	for _, d := range deploys.Items {
-		key := "Deployment/" + d.Name
-		replicas := int32(1)
-		if d.Spec.Replicas != nil {
-			replicas = *d.Spec.Replicas
-		}
-		if replicas == 0 {
-			continue
+	slices.SortFunc(deploys.Items, func(a, b appsv1.Deployment) int { return strings.Compare(a.Name, b.Name) })
+	for i := range deploys.Items {
+		d := &deploys.Items[i]
+		run.add(d, "Deployment", d.Spec.Replicas, &d.Spec.Template.Spec)
+	}
+
+	stss := &appsv1.StatefulSetList{}
+	s.Client.List(ctx, stss, client.InNamespace(nsName))
+	slices.SortFunc(stss.Items, func(a, b appsv1.StatefulSet) int { return strings.Compare(a.Name, b.Name) })
+	for i := range stss.Items {
+		ss := &stss.Items[i]
+		run.add(ss, "StatefulSet", ss.Spec.Replicas, &ss.Spec.Template.Spec)
+	}
+	optimizedWorkloads, skippedWorkloads := run.optimized, run.skipped
+	if s.Recorder != nil {
+		for _, obj := range run.optedOut {
+			s.Recorder.Eventf(obj, corev1.EventTypeNormal, scaling.OptedOutReason, "Not optimized, %s is set to false", scaling.ManagedAnnotation)
 		}
+	}
 
-		// Calc new values
-		usageCPU := workloadUsage[key] * cpuFactor
-		usageMem := workloadMemUsage[key] * memFactor
-
-		newReqCPU := usageCPU * 1.3 / float64(replicas)
-		newLimCPU := usageCPU * 1.5 / float64(replicas)
-		newReqMem := usageMem * 1.3 / float64(replicas)
-		newLimMem := usageMem * 1.5 / float64(replicas)
-
-		// Sanity mimimums & protection
-		currentReqCPU := d.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu().AsApproximateFloat64()
-		currentReqMem := float64(d.Spec.Template.Spec.Containers[0].Resources.Requests.Memory().Value())
-		currentLimCPU := d.Spec.Template.Spec.Containers[0].Resources.Limits.Cpu().AsApproximateFloat64()
-		currentLimMem := float64(d.Spec.Template.Spec.Containers[0].Resources.Limits.Memory().Value())
-
-		// Safety floor: 20m CPU, 64Mi RAM
-		cpuFloor := 0.02
-		memFloor := 64.0 * 1024 * 1024
-
-		if newReqCPU < cpuFloor {
-			if currentReqCPU >= cpuFloor {
-				newReqCPU = cpuFloor
-			} else {
-				// Already manually tuned below floor, keep it
-				newReqCPU = currentReqCPU
-			}
+	// 5. Reject the whole run up front when the namespace quotas cannot absorb it
+	exceeded, err := s.checkQuota(ctx, nsName, run.quotaDelta)
+	if err != nil {
+		writeJSONError(w, "Failed to read ResourceQuotas: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if exceeded != "" {
+		writeJSONError(w, exceeded, http.StatusConflict)
+		return
+	}
+
+	// Dry-run: report what would change without touching workloads or the CR
+	if dryRun {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(optimizedWorkloads)
+		return
+	}
+
+	if len(optimizedWorkloads) == 0 {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(OptimizeNoopResult{
+			Reason:  run.noopReason(unownedPods, len(skippedWorkloads)),
+			Skipped: skippedWorkloads,
+		})
+		return
+	}
+
+	// Propose: store the run for review, POST .../optimize/apply writes it
+	if propose {
+		s.proposeOptimization(w, r, nsName, finopsv1.OptimizationProposal{
+			ProposedAt: metav1.Now(),
+			Strategy:   strategy,
+			Workloads:  optimizedWorkloads,
+			Skipped:    skippedWorkloads,
+		})
+		return
+	}
+
+	// 6. Apply, anything the API server refuses is reported as skipped
+	optimizedWorkloads, failed := s.applyOptimization(ctx, nsName, run.updates, optimizedWorkloads)
+	skippedWorkloads = append(skippedWorkloads, failed...)
+
+	// Nothing could be applied: leave the optimization record alone rather than marking an
+	// empty optimization as applied
+	if len(optimizedWorkloads) == 0 {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(OptimizeNoopResult{
+			Reason:  run.noopReason(unownedPods, len(skippedWorkloads)),
+			Skipped: skippedWorkloads,
+		})
+		return
+	}
+
+	// 7. Store/Update NamespaceOptimization CR
+	s.recordOptimization(w, r, auditOptimize, nsName, strategy, optimizedWorkloads, skippedWorkloads)
+}
+
+// applyOptimization updates the optimized workloads, aligned with updates, and returns
+// those the API server accepted along with a skipped entry for each one it refused.
+func (s *Server) applyOptimization(ctx context.Context, nsName string, updates []client.Object, optimized []finopsv1.WorkloadOptimization) ([]finopsv1.WorkloadOptimization, []finopsv1.SkippedWorkload) {
+	var applied []finopsv1.WorkloadOptimization
+	var skipped []finopsv1.SkippedWorkload
+	for i, wo := range optimized {
+		annotations := updates[i].GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
 		}
-		if newLimCPU < cpuFloor*1.5 {
-			if currentLimCPU >= cpuFloor*1.5 {
-				newLimCPU = cpuFloor * 1.5
-			} else {
-				newLimCPU = currentLimCPU
-			}
+		annotations[OptimizedByAnnotation] = getOperatorNamespace() + "/" + nsName
+		updates[i].SetAnnotations(annotations)
+		if err := s.Client.Update(ctx, updates[i]); err != nil {
+			logf.Log.Error(err, "Failed to apply optimization", "namespace", nsName, "kind", wo.Kind, "name", wo.Name)
+			skipped = append(skipped, finopsv1.SkippedWorkload{Name: wo.Name, Kind: wo.Kind, Reason: "Update failed: " + err.Error()})
+			continue
 		}
+		applied = append(applied, wo)
+	}
+	return applied, skipped
+}
 
-		if newReqMem < memFloor {
-			if currentReqMem >= memFloor {
-				newReqMem = memFloor
-			} else {
-				// Already manually tuned below floor, keep it
-				newReqMem = currentReqMem
-			}
+// optimizationRecord returns the NamespaceOptimization of a namespace, creating it when
+// missing so that its status can be updated.
+func (s *Server) optimizationRecord(ctx context.Context, nsName string) (*finopsv1.NamespaceOptimization, error) {
+	opt, err := s.resolveNamespaceOptimization(ctx, nsName)
+	if err == nil {
+		return opt, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	// CR doesn't exist yet — create it first (status is stripped on Create)
+	opt = &finopsv1.NamespaceOptimization{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      nsName,
+			Namespace: getOperatorNamespace(),
+		},
+		Spec: finopsv1.NamespaceOptimizationSpec{TargetNamespace: nsName},
+	}
+	if err := s.Client.Create(ctx, opt); err != nil {
+		return nil, err
+	}
+	return opt, nil
+}
+
+// recordOptimization stores an applied run in the NamespaceOptimization of the namespace,
+// clearing any pending proposal, and writes the resulting status as the response.
+func (s *Server) recordOptimization(w http.ResponseWriter, r *http.Request, action, nsName, strategy string, optimizedWorkloads []finopsv1.WorkloadOptimization, skippedWorkloads []finopsv1.SkippedWorkload) {
+	opt, err := s.optimizationRecord(r.Context(), nsName)
+	if err != nil {
+		logf.Log.Error(err, "Failed to create NamespaceOptimization", "namespace", nsName)
+		writeJSONError(w, "Failed to create optimization record: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Now update the status subresource separately (this is required because
+	// +kubebuilder:subresource:status means status is stripped on Create)
+	now := metav1.Now()
+	opt.Status.History = append(opt.Status.History, finopsv1.OptimizationSnapshot{
+		OptimizedAt: now,
+		Strategy:    strategy,
+		Workloads:   optimizedWorkloads,
+	})
+	if len(opt.Status.History) > maxOptimizationHistory {
+		opt.Status.History = opt.Status.History[len(opt.Status.History)-maxOptimizationHistory:]
+	}
+	if opt.Status.Active {
+		optimizedWorkloads = mergeOptimizations(opt.Status.Workloads, optimizedWorkloads)
+	}
+	sortWorkloads(optimizedWorkloads)
+	opt.Status.Active = true
+	opt.Status.OptimizedAt = now
+	opt.Status.Strategy = strategy
+	opt.Status.Workloads = optimizedWorkloads
+	opt.Status.Skipped = skippedWorkloads
+	opt.Status.Proposal = nil
+
+	if statusErr := s.Client.Status().Update(r.Context(), opt); statusErr != nil {
+		logf.Log.Error(statusErr, "Failed to update NamespaceOptimization status", "namespace", nsName)
+		writeJSONError(w, "Failed to update optimization status: "+statusErr.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.audit(r, action, nsName, opt.Name, opt)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(opt.Status)
+}
+
+// proposeOptimization stores a run for review without touching the workloads, replacing
+// any earlier proposal, and writes the resulting status as the response.
+func (s *Server) proposeOptimization(w http.ResponseWriter, r *http.Request, nsName string, proposal finopsv1.OptimizationProposal) {
+	opt, err := s.optimizationRecord(r.Context(), nsName)
+	if err != nil {
+		logf.Log.Error(err, "Failed to create NamespaceOptimization", "namespace", nsName)
+		writeJSONError(w, "Failed to create optimization record: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	opt.Status.Proposal = &proposal
+	if err := s.Client.Status().Update(r.Context(), opt); err != nil {
+		logf.Log.Error(err, "Failed to update NamespaceOptimization status", "namespace", nsName)
+		writeJSONError(w, "Failed to update optimization status: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.audit(r, auditPropose, nsName, opt.Name, opt)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(opt.Status)
+}
+
+// handleNamespaceOptimizeApply writes the proposal stored by POST .../optimize?propose=true.
+// Workloads whose resources changed since the proposal are skipped rather than overwritten.
+func (s *Server) handleNamespaceOptimizeApply(w http.ResponseWriter, r *http.Request, nsName string) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	operatorNs := getOperatorNamespace()
+
+	phase, err := s.namespaceScalingPhase(ctx, operatorNs, nsName)
+	if err != nil {
+		writeJSONError(w, "Failed to read scaling state: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if phase != "" && phase != "ScaledUp" {
+		writeScalingConflict(w, nsName, phase)
+		return
+	}
+
+	opt, err := s.resolveNamespaceOptimization(ctx, nsName)
+	if err != nil && !errors.IsNotFound(err) {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if opt == nil || opt.Status.Proposal == nil {
+		writeJSONError(w, "No optimization proposed for namespace "+nsName+", POST .../optimize?propose=true first", http.StatusNotFound)
+		return
+	}
+	proposal := opt.Status.Proposal
+	if remaining := s.optimizeCooldownRemaining(opt.Status.OptimizedAt); remaining > 0 {
+		writeOptimizeCooldown(w, nsName, remaining)
+		return
+	}
+
+	var updates []client.Object
+	var optimized []finopsv1.WorkloadOptimization
+	skipped := slices.Clone(proposal.Skipped)
+	delta := corev1.ResourceList{}
+	for _, wo := range proposal.Workloads {
+		obj, spec, replicas, err := s.getWorkload(ctx, nsName, wo.Kind, wo.Name)
+		if errors.IsNotFound(err) {
+			skipped = append(skipped, finopsv1.SkippedWorkload{Name: wo.Name, Kind: wo.Kind, Reason: "Workload no longer exists"})
+			continue
 		}
-		if newLimMem < memFloor*1.5 {
-			if currentLimMem >= memFloor*1.5 {
-				newLimMem = memFloor * 1.5
-			} else {
-				newLimMem = currentLimMem
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if scaling.Unmanaged(obj) {
+			skipped = append(skipped, finopsv1.SkippedWorkload{Name: wo.Name, Kind: wo.Kind, Reason: "Opted out of Kubex management"})
+			continue
+		}
+		if !proposalCurrent(spec, wo) {
+			skipped = append(skipped, finopsv1.SkippedWorkload{Name: wo.Name, Kind: wo.Kind, Reason: "Resources changed since the proposal"})
+			continue
+		}
+
+		before := quotaUsage(spec, replicas)
+		for _, co := range wo.Containers {
+			if c := podContainer(spec, co.Name); c != nil && !co.Skipped {
+				setContainerResources(c, co.Optimized)
 			}
 		}
+		addQuotaDelta(delta, before, quotaUsage(spec, replicas))
+		updates = append(updates, obj)
+		optimized = append(optimized, wo)
+	}
 
-		// Guarantee limits are always >= requests
-		if newLimCPU < newReqCPU {
-			newLimCPU = newReqCPU
+	exceeded, err := s.checkQuota(ctx, nsName, delta)
+	if err != nil {
+		writeJSONError(w, "Failed to read ResourceQuotas: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if exceeded != "" {
+		writeJSONError(w, exceeded, http.StatusConflict)
+		return
+	}
+
+	optimized, failed := s.applyOptimization(ctx, nsName, updates, optimized)
+	skipped = append(skipped, failed...)
+	if len(optimized) == 0 {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(OptimizeNoopResult{
+			Reason:  fmt.Sprintf("No proposed workload could be applied, %d skipped", len(skipped)),
+			Skipped: skipped,
+		})
+		return
+	}
+	s.recordOptimization(w, r, auditApplyProposal, nsName, proposal.Strategy, optimized, skipped)
+}
+
+// proposalCurrent reports whether every container of a proposed workload still has the
+// values the proposal was computed from.
+func proposalCurrent(spec *corev1.PodSpec, wo finopsv1.WorkloadOptimization) bool {
+	for _, co := range wo.Containers {
+		if co.Skipped {
+			continue
 		}
-		if newLimMem < newReqMem {
-			newLimMem = newReqMem
+		c := podContainer(spec, co.Name)
+		if c == nil {
+			return false
 		}
+		current := containerResourceValues(*c)
+		if overlayResources(current, co.Original) != current {
+			return false
+		}
+	}
+	return true
+}
 
-		orig := finopsv1.ResourceValues{}
-		if len(d.Spec.Template.Spec.Containers) > 0 {
-			c := d.Spec.Template.Spec.Containers[0]
-			orig.CPURequest = c.Resources.Requests.Cpu().String()
-			orig.CPULimit = c.Resources.Limits.Cpu().String()
-			orig.MemoryRequest = c.Resources.Requests.Memory().String()
-			orig.MemoryLimit = c.Resources.Limits.Memory().String()
+// namespaceScalingPhase returns the phase of the ScalingGroup managing a namespace, or of
+// the ScalingConfig targeting it, and an empty string when it is not scheduled. Groups are
+// checked first since they override individual configs.
+func (s *Server) namespaceScalingPhase(ctx context.Context, operatorNs, nsName string) (string, error) {
+	groups := &finopsv1.ScalingGroupList{}
+	if err := s.Client.List(ctx, groups, client.InNamespace(operatorNs)); err != nil {
+		return "", err
+	}
+	if g := scaling.ManagingGroup(groups.Items, nsName); g != nil {
+		return g.Status.Phase, nil
+	}
 
-			// Update
-			d.Spec.Template.Spec.Containers[0].Resources.Requests = corev1.ResourceList{
-				corev1.ResourceCPU:    resource.MustParse(fmt.Sprintf("%dm", int64(newReqCPU*1000))),
-				corev1.ResourceMemory: resource.MustParse(fmt.Sprintf("%dMi", int64(newReqMem/1024/1024))),
-			}
-			d.Spec.Template.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
-				corev1.ResourceCPU:    resource.MustParse(fmt.Sprintf("%dm", int64(newLimCPU*1000))),
-				corev1.ResourceMemory: resource.MustParse(fmt.Sprintf("%dMi", int64(newLimMem/1024/1024))),
+	configs := &finopsv1.ScalingConfigList{}
+	if err := s.Client.List(ctx, configs, client.InNamespace(operatorNs)); err != nil {
+		return "", err
+	}
+	for _, cfg := range configs.Items {
+		if cfg.Spec.TargetNamespace == nsName {
+			return cfg.Status.Phase, nil
+		}
+	}
+	return "", nil
+}
+
+// writeScalingConflict rejects an optimization of a namespace that is not fully scaled up.
+func writeScalingConflict(w http.ResponseWriter, nsName, phase string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(struct {
+		apiError
+		Phase string `json:"phase"`
+	}{
+		apiError: apiError{
+			Error: fmt.Sprintf("Namespace %s is %s, optimize it once it is fully scaled up", nsName, phase),
+			Code:  http.StatusConflict,
+		},
+		Phase: phase,
+	})
+}
+
+// sortWorkloads orders optimized workloads by kind then name
+func sortWorkloads(workloads []finopsv1.WorkloadOptimization) {
+	slices.SortFunc(workloads, func(a, b finopsv1.WorkloadOptimization) int {
+		if c := strings.Compare(a.Kind, b.Kind); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+}
+
+// mergeOptimizations combines a new optimization run with the one still applied. Workloads
+// optimized again keep the original values of the earlier run, and workloads the new run
+// left out are kept, so that a revert restores every workload to its true baseline. Values
+// are merged per dimension, since either run may have tuned only CPU or only memory.
+func mergeOptimizations(previous, current []finopsv1.WorkloadOptimization) []finopsv1.WorkloadOptimization {
+	prevByKey := make(map[string]finopsv1.WorkloadOptimization, len(previous))
+	for _, p := range previous {
+		prevByKey[p.Kind+"/"+p.Name] = p
+	}
+
+	merged := make([]finopsv1.WorkloadOptimization, 0, len(current)+len(previous))
+	seen := make(map[string]bool, len(current))
+	for _, c := range current {
+		key := c.Kind + "/" + c.Name
+		seen[key] = true
+		if p, ok := prevByKey[key]; ok {
+			c.Original = overlayResources(c.Original, p.Original)
+			c.Optimized = overlayResources(p.Optimized, c.Optimized)
+			c.Containers = slices.Clone(c.Containers)
+			for i := range c.Containers {
+				for _, pc := range p.Containers {
+					if pc.Name == c.Containers[i].Name {
+						c.Containers[i].Original = overlayResources(c.Containers[i].Original, pc.Original)
+						c.Containers[i].Optimized = overlayResources(pc.Optimized, c.Containers[i].Optimized)
+						break
+					}
+				}
 			}
-			s.Client.Update(ctx, &d)
-
-			optimizedWorkloads = append(optimizedWorkloads, finopsv1.WorkloadOptimization{
-				Name:     d.Name,
-				Kind:     "Deployment",
-				Original: orig,
-				Optimized: finopsv1.ResourceValues{
-					CPURequest:    d.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu().String(),
-					CPULimit:      d.Spec.Template.Spec.Containers[0].Resources.Limits.Cpu().String(),
-					MemoryRequest: d.Spec.Template.Spec.Containers[0].Resources.Requests.Memory().String(),
-					MemoryLimit:   d.Spec.Template.Spec.Containers[0].Resources.Limits.Memory().String(),
-				},
-			})
 		}
+		merged = append(merged, c)
 	}
+	for _, p := range previous {
+		if !seen[p.Kind+"/"+p.Name] {
+			merged = append(merged, p)
+		}
+	}
+	return merged
+}
 
-	// Process StatefulSets
-	stss := &appsv1.StatefulSetList{}
-	s.Client.List(ctx, stss, client.InNamespace(nsName))
-	for _, d := range stss.Items {
-		key := "StatefulSet/" + d.Name
-		replicas := int32(1)
-		if d.Spec.Replicas != nil {
-			replicas = *d.Spec.Replicas
+// missingUsageReason explains why a workload has no usable usage signal, or returns an
+// empty string when it does. Sizing off a momentary idle reading would push the workload
+// to the safety floor even if it is busy at other times.
+func missingUsageReason(cpuUsage, memUsage map[string]float64) string {
+	if cpuUsage == nil {
+		return "No pod metrics for this workload during the sampling window"
+	}
+	for name := range cpuUsage {
+		if cpuUsage[name] > 0 || memUsage[name] > 0 {
+			return ""
 		}
-		if replicas == 0 {
+	}
+	return "Zero usage reported for all containers"
+}
+
+// optimizationRun sizes the workloads of one namespace optimization, collecting the
+// objects to update and the change they make to the namespace quotas.
+type optimizationRun struct {
+	cpuUsage, memUsage   map[string]map[string]float64 // key: KIND/NAME -> container name
+	cpuFactor, memFactor float64
+	opts                 optimizeOptions
+
+	optimized  []finopsv1.WorkloadOptimization
+	skipped    []finopsv1.SkippedWorkload
+	updates    []client.Object // aligned with optimized
+	quotaDelta corev1.ResourceList
+	scaledDown int             // workloads ignored at zero replicas
+	optedOut   []client.Object // workloads skipped because of scaling.ManagedAnnotation
+}
+
+// OptimizeNoopResult is returned by POST /api/namespaces/{ns}/optimize instead of the
+// optimization status when no workload qualified, in which case nothing is recorded.
+type OptimizeNoopResult struct {
+	Optimized int                        `json:"optimized"`
+	Reason    string                     `json:"reason"`
+	Skipped   []finopsv1.SkippedWorkload `json:"skipped,omitempty"`
+}
+
+// noopReason explains why a run optimized no workload
+func (run *optimizationRun) noopReason(unownedPods, skipped int) string {
+	switch {
+	case skipped > 0:
+		return fmt.Sprintf("No workload could be optimized, %d skipped", skipped)
+	case run.scaledDown > 0:
+		return "All workloads are scaled down"
+	case unownedPods > 0:
+		return fmt.Sprintf("No owned pods, %d pods have no Deployment or StatefulSet owner", unownedPods)
+	default:
+		return "No Deployments or StatefulSets to optimize"
+	}
+}
+
+func newOptimizationRun(cpuUsage, memUsage map[string]map[string]float64, cpuFactor, memFactor float64, opts optimizeOptions) *optimizationRun {
+	return &optimizationRun{
+		cpuUsage:   cpuUsage,
+		memUsage:   memUsage,
+		cpuFactor:  cpuFactor,
+		memFactor:  memFactor,
+		opts:       opts,
+		optimized:  []finopsv1.WorkloadOptimization{},
+		quotaDelta: corev1.ResourceList{},
+	}
+}
+
+// add right-sizes the pod spec of a workload in place. Workloads scaled to zero are
+// ignored and those without observed usage or opted out of Kubex management are recorded
+// as skipped.
+func (run *optimizationRun) add(obj client.Object, kind string, specReplicas *int32, spec *corev1.PodSpec) {
+	if scaling.Unmanaged(obj) {
+		run.optedOut = append(run.optedOut, obj)
+		run.skipped = append(run.skipped, finopsv1.SkippedWorkload{Name: obj.GetName(), Kind: kind, Reason: "Opted out of Kubex management"})
+		return
+	}
+	key := kind + "/" + obj.GetName()
+	replicas := int32(1)
+	if specReplicas != nil {
+		replicas = *specReplicas
+	}
+	if replicas == 0 {
+		run.scaledDown++
+		return
+	}
+	if reason := missingUsageReason(run.cpuUsage[key], run.memUsage[key]); reason != "" {
+		run.skipped = append(run.skipped, finopsv1.SkippedWorkload{Name: obj.GetName(), Kind: kind, Reason: reason})
+		return
+	}
+
+	containers := spec.Containers
+	excluded := excludedContainers(obj)
+	if len(excluded) > 0 && !slices.ContainsFunc(containers, func(c corev1.Container) bool { return !excluded[c.Name] }) {
+		run.skipped = append(run.skipped, finopsv1.SkippedWorkload{Name: obj.GetName(), Kind: kind, Reason: "All containers are excluded from optimization"})
+		return
+	}
+
+	orig := selectResources(podResourceValues(containers), run.opts.resources)
+	before := quotaUsage(spec, replicas)
+	containerOpts := optimizeContainers(containers, run.cpuUsage[key], run.memUsage[key], run.cpuFactor, run.memFactor, replicas, excluded, run.opts)
+	for i := range spec.InitContainers {
+		// metrics-server only reports running containers, so init containers that completed
+		// before the sampling window have no usage and keep their values
+		if _, observed := run.cpuUsage[key][spec.InitContainers[i].Name]; !observed {
 			continue
 		}
+		initOpts := optimizeContainers(spec.InitContainers[i:i+1], run.cpuUsage[key], run.memUsage[key], run.cpuFactor, run.memFactor, replicas, excluded, run.opts)
+		initOpts[0].Init = true
+		containerOpts = append(containerOpts, initOpts...)
+	}
+	addQuotaDelta(run.quotaDelta, before, quotaUsage(spec, replicas))
+
+	run.updates = append(run.updates, obj)
+	run.optimized = append(run.optimized, finopsv1.WorkloadOptimization{
+		Name:       obj.GetName(),
+		Kind:       kind,
+		Original:   orig,
+		Optimized:  selectResources(podResourceValues(containers), run.opts.resources),
+		Containers: containerOpts,
+	})
+}
+
+// excludedContainers returns the container names listed in the ExcludeContainersAnnotation
+// of a workload.
+func excludedContainers(obj client.Object) map[string]bool {
+	excluded := map[string]bool{}
+	for _, name := range strings.Split(obj.GetAnnotations()[ExcludeContainersAnnotation], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			excluded[name] = true
+		}
+	}
+	return excluded
+}
 
-		usageCPU := workloadUsage[key] * cpuFactor
-		usageMem := workloadMemUsage[key] * memFactor
+// optimizeContainers right-sizes the selected resources of every container in place from
+// its own observed usage and returns the before/after values of each one. The values of
+// dimensions left alone are not recorded. Excluded containers keep their resources and are
+// recorded as skipped.
+func optimizeContainers(containers []corev1.Container, cpuUsage, memUsage map[string]float64, cpuFactor, memFactor float64, replicas int32, excluded map[string]bool, opts optimizeOptions) []finopsv1.ContainerOptimization {
+	result := make([]finopsv1.ContainerOptimization, 0, len(containers))
+	for i := range containers {
+		c := &containers[i]
+		if excluded[c.Name] {
+			current := selectResources(containerResourceValues(*c), opts.resources)
+			result = append(result, finopsv1.ContainerOptimization{
+				Name:      c.Name,
+				Original:  current,
+				Optimized: current,
+				Skipped:   true,
+			})
+			continue
+		}
 
-		newReqCPU := usageCPU * 1.3 / float64(replicas)
-		newLimCPU := usageCPU * 1.5 / float64(replicas)
-		newReqMem := usageMem * 1.3 / float64(replicas)
-		newLimMem := usageMem * 1.5 / float64(replicas)
+		// Calc new values
+		usageCPU := cpuUsage[c.Name] * cpuFactor
+		usageMem := memUsage[c.Name] * memFactor
 
-		// Sanity mimimums & protection
-		currentReqCPU := d.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu().AsApproximateFloat64()
-		currentReqMem := float64(d.Spec.Template.Spec.Containers[0].Resources.Requests.Memory().Value())
-		currentLimCPU := d.Spec.Template.Spec.Containers[0].Resources.Limits.Cpu().AsApproximateFloat64()
-		currentLimMem := float64(d.Spec.Template.Spec.Containers[0].Resources.Limits.Memory().Value())
+		newReqCPU := usageCPU * opts.reqFactor / float64(replicas)
+		newLimCPU := usageCPU * opts.limitFactor / float64(replicas)
+		newReqMem := usageMem * opts.reqFactor / float64(replicas)
+		newLimMem := usageMem * opts.limitFactor / float64(replicas)
 
-		// Safety floor: 20m CPU, 64Mi RAM
-		cpuFloor := 0.02
-		memFloor := 64.0 * 1024 * 1024
+		// Safety floor, 20m CPU and 64Mi RAM by default
+		reqCPU := floorQuantity(cpuQuantity(newReqCPU), *c.Resources.Requests.Cpu(), opts.cpuFloor)
+		limCPU := floorQuantity(cpuQuantity(newLimCPU), *c.Resources.Limits.Cpu(), scaleQuantity(opts.cpuFloor, opts.limitFactor))
+		reqMem := floorQuantity(memoryQuantity(newReqMem), *c.Resources.Requests.Memory(), opts.memFloor)
+		limMem := floorQuantity(memoryQuantity(newLimMem), *c.Resources.Limits.Memory(), scaleQuantity(opts.memFloor, opts.limitFactor))
 
-		if newReqCPU < cpuFloor {
-			if currentReqCPU >= cpuFloor {
-				newReqCPU = cpuFloor
-			} else {
-				// Already manually tuned below floor, keep it
-				newReqCPU = currentReqCPU
-			}
+		// Guarantee limits are always >= requests
+		if limCPU.Cmp(reqCPU) < 0 {
+			limCPU = reqCPU
 		}
-		if newLimCPU < cpuFloor*1.5 {
-			if currentLimCPU >= cpuFloor*1.5 {
-				newLimCPU = cpuFloor * 1.5
-			} else {
-				newLimCPU = currentLimCPU
-			}
+		if limMem.Cmp(reqMem) < 0 {
+			limMem = reqMem
 		}
 
-		if newReqMem < memFloor {
-			if currentReqMem >= memFloor {
-				newReqMem = memFloor
-			} else {
-				// Already manually tuned below floor, keep it
-				newReqMem = currentReqMem
-			}
+		// Stay within the LimitRanges, or the API server rejects the new pods
+		var clamped []string
+		if opts.resources != resourcesMemory {
+			clamped = append(clamped, opts.bounds.cpu.clampQuantities("cpu", &reqCPU, &limCPU, 0.001, formatCPU, cpuQuantity)...)
 		}
-		if newLimMem < memFloor*1.5 {
-			if currentLimMem >= memFloor*1.5 {
-				newLimMem = memFloor * 1.5
-			} else {
-				newLimMem = currentLimMem
-			}
+		if opts.resources != resourcesCPU {
+			clamped = append(clamped, opts.bounds.memory.clampQuantities("memory", &reqMem, &limMem, 1024*1024, formatMemory, memoryQuantity)...)
 		}
 
-		// Guarantee limits are always >= requests
-		if newLimCPU < newReqCPU {
-			newLimCPU = newReqCPU
-		}
-		if newLimMem < newReqMem {
-			newLimMem = newReqMem
+		orig := selectResources(containerResourceValues(*c), opts.resources)
+		setContainerResources(c, selectResources(finopsv1.ResourceValues{
+			CPURequest:    reqCPU.String(),
+			CPULimit:      limCPU.String(),
+			MemoryRequest: reqMem.String(),
+			MemoryLimit:   limMem.String(),
+		}, opts.resources))
+
+		result = append(result, finopsv1.ContainerOptimization{
+			Name:      c.Name,
+			Original:  orig,
+			Optimized: selectResources(containerResourceValues(*c), opts.resources),
+			Clamped:   clamped,
+		})
+	}
+	return result
+}
+
+// floorQuantity raises a computed value to the floor, unless the current value was already
+// tuned below the floor by hand, which is kept.
+func floorQuantity(computed, current, floor resource.Quantity) resource.Quantity {
+	switch {
+	case computed.Cmp(floor) >= 0:
+		return computed
+	case current.Cmp(floor) >= 0:
+		return floor
+	default:
+		return current
+	}
+}
+
+// scaleQuantity multiplies q by factor, rounding up to the millis so a scaled floor never
+// ends up below the exact product.
+func scaleQuantity(q resource.Quantity, factor float64) resource.Quantity {
+	return *resource.NewMilliQuantity(int64(math.Ceil(float64(q.MilliValue())*factor-1e-9)), q.Format)
+}
+
+// cpuQuantity converts cores computed from the usage to millicores, rounded down.
+func cpuQuantity(cores float64) resource.Quantity {
+	return *resource.NewMilliQuantity(int64(cores*1000+1e-9), resource.DecimalSI)
+}
+
+// memoryQuantity converts bytes computed from the usage to whole mebibytes, rounded down.
+func memoryQuantity(bytes float64) resource.Quantity {
+	return *resource.NewQuantity(int64(bytes/(1024*1024)+1e-9)*1024*1024, resource.BinarySI)
+}
+
+// restoreContainers puts back the original resources recorded for a workload.
+func restoreContainers(spec *corev1.PodSpec, w finopsv1.WorkloadOptimization) {
+	// Records written before per-container tracking only covered the first container
+	if len(w.Containers) == 0 {
+		if len(spec.Containers) > 0 {
+			setContainerResources(&spec.Containers[0], w.Original)
 		}
+		return
+	}
 
-		orig := finopsv1.ResourceValues{}
-		if len(d.Spec.Template.Spec.Containers) > 0 {
-			c := d.Spec.Template.Spec.Containers[0]
-			orig.CPURequest = c.Resources.Requests.Cpu().String()
-			orig.CPULimit = c.Resources.Limits.Cpu().String()
-			orig.MemoryRequest = c.Resources.Requests.Memory().String()
-			orig.MemoryLimit = c.Resources.Limits.Memory().String()
+	for _, co := range w.Containers {
+		if co.Skipped {
+			continue
+		}
+		if c := podContainer(spec, co.Name); c != nil {
+			setContainerResources(c, co.Original)
+		}
+	}
+}
 
-			d.Spec.Template.Spec.Containers[0].Resources.Requests = corev1.ResourceList{
-				corev1.ResourceCPU:    resource.MustParse(fmt.Sprintf("%dm", int64(newReqCPU*1000))),
-				corev1.ResourceMemory: resource.MustParse(fmt.Sprintf("%dMi", int64(newReqMem/1024/1024))),
+// podContainer returns the container or init container with the given name, names being
+// unique across both in a pod.
+func podContainer(spec *corev1.PodSpec, name string) *corev1.Container {
+	for _, containers := range [][]corev1.Container{spec.Containers, spec.InitContainers} {
+		for i := range containers {
+			if containers[i].Name == name {
+				return &containers[i]
 			}
-			d.Spec.Template.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
-				corev1.ResourceCPU:    resource.MustParse(fmt.Sprintf("%dm", int64(newLimCPU*1000))),
-				corev1.ResourceMemory: resource.MustParse(fmt.Sprintf("%dMi", int64(newLimMem/1024/1024))),
-			}
-			s.Client.Update(ctx, &d)
-
-			optimizedWorkloads = append(optimizedWorkloads, finopsv1.WorkloadOptimization{
-				Name:     d.Name,
-				Kind:     "StatefulSet",
-				Original: orig,
-				Optimized: finopsv1.ResourceValues{
-					CPURequest:    d.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu().String(),
-					CPULimit:      d.Spec.Template.Spec.Containers[0].Resources.Limits.Cpu().String(),
-					MemoryRequest: d.Spec.Template.Spec.Containers[0].Resources.Requests.Memory().String(),
-					MemoryLimit:   d.Spec.Template.Spec.Containers[0].Resources.Limits.Memory().String(),
-				},
-			})
 		}
 	}
+	return nil
+}
 
-	// 5. Store/Update NamespaceOptimization CR
-	opt := &finopsv1.NamespaceOptimization{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      nsName,
-			Namespace: operatorNs,
-		},
+// setContainerResources sets the CPU/memory requests and limits of a container, leaving
+// any other resources untouched. Zero values remove the entry and empty values, such as
+// the dimensions an optimization did not change, leave it as is.
+func setContainerResources(c *corev1.Container, v finopsv1.ResourceValues) {
+	if c.Resources.Requests == nil {
+		c.Resources.Requests = corev1.ResourceList{}
 	}
-	err = s.Client.Get(ctx, client.ObjectKey{Name: nsName, Namespace: operatorNs}, opt)
-	opt.Spec.TargetNamespace = nsName
+	if c.Resources.Limits == nil {
+		c.Resources.Limits = corev1.ResourceList{}
+	}
+	setQuantity(c.Resources.Requests, corev1.ResourceCPU, v.CPURequest)
+	setQuantity(c.Resources.Requests, corev1.ResourceMemory, v.MemoryRequest)
+	setQuantity(c.Resources.Limits, corev1.ResourceCPU, v.CPULimit)
+	setQuantity(c.Resources.Limits, corev1.ResourceMemory, v.MemoryLimit)
+}
 
-	if err != nil {
-		// CR doesn't exist yet — create it first (status is stripped on Create)
-		if createErr := s.Client.Create(ctx, opt); createErr != nil {
-			logf.Log.Error(createErr, "Failed to create NamespaceOptimization", "namespace", nsName)
-			http.Error(w, "Failed to create optimization record: "+createErr.Error(), http.StatusInternalServerError)
-			return
+func setQuantity(list corev1.ResourceList, name corev1.ResourceName, value string) {
+	if value == "" {
+		return
+	}
+	q, err := resource.ParseQuantity(value)
+	if err != nil || q.IsZero() {
+		delete(list, name)
+		return
+	}
+	list[name] = q
+}
+
+func containerResourceValues(c corev1.Container) finopsv1.ResourceValues {
+	return finopsv1.ResourceValues{
+		CPURequest:    c.Resources.Requests.Cpu().String(),
+		CPULimit:      c.Resources.Limits.Cpu().String(),
+		MemoryRequest: c.Resources.Requests.Memory().String(),
+		MemoryLimit:   c.Resources.Limits.Memory().String(),
+	}
+}
+
+// selectResources blanks the dimensions an optimization with the given ?resources= leaves alone.
+func selectResources(v finopsv1.ResourceValues, resources string) finopsv1.ResourceValues {
+	switch resources {
+	case resourcesCPU:
+		v.MemoryRequest, v.MemoryLimit = "", ""
+	case resourcesMemory:
+		v.CPURequest, v.CPULimit = "", ""
+	}
+	return v
+}
+
+// overlayResources returns base with every value recorded in over replacing its own.
+func overlayResources(base, over finopsv1.ResourceValues) finopsv1.ResourceValues {
+	for _, f := range []struct{ dst, src *string }{
+		{&base.CPURequest, &over.CPURequest},
+		{&base.CPULimit, &over.CPULimit},
+		{&base.MemoryRequest, &over.MemoryRequest},
+		{&base.MemoryLimit, &over.MemoryLimit},
+	} {
+		if *f.src != "" {
+			*f.dst = *f.src
 		}
 	}
+	return base
+}
 
-	// Now update the status subresource separately (this is required because
-	// +kubebuilder:subresource:status means status is stripped on Create)
-	opt.Status.Active = true
-	opt.Status.OptimizedAt = metav1.Now()
-	opt.Status.Workloads = optimizedWorkloads
+// podResourceValues sums the requests and limits of all containers of a pod.
+func podResourceValues(containers []corev1.Container) finopsv1.ResourceValues {
+	var cpuReq, cpuLim, memReq, memLim resource.Quantity
+	for _, c := range containers {
+		cpuReq.Add(*c.Resources.Requests.Cpu())
+		cpuLim.Add(*c.Resources.Limits.Cpu())
+		memReq.Add(*c.Resources.Requests.Memory())
+		memLim.Add(*c.Resources.Limits.Memory())
+	}
+	return finopsv1.ResourceValues{
+		CPURequest:    cpuReq.String(),
+		CPULimit:      cpuLim.String(),
+		MemoryRequest: memReq.String(),
+		MemoryLimit:   memLim.String(),
+	}
+}
 
-	if statusErr := s.Client.Status().Update(ctx, opt); statusErr != nil {
-		logf.Log.Error(statusErr, "Failed to update NamespaceOptimization status", "namespace", nsName)
-		http.Error(w, "Failed to update optimization status: "+statusErr.Error(), http.StatusInternalServerError)
-		return
+// aggregateUsage reduces the usage history to the single value workloads are sized from.
+func aggregateUsage(samples []float64, strategy string) float64 {
+	if strategy == strategyP95 {
+		return percentile(samples, 95)
+	}
+	var total float64
+	for _, v := range samples {
+		total += v
 	}
+	return total / float64(len(samples))
+}
 
-	w.WriteHeader(http.StatusOK)
+// percentile returns the p-th percentile of samples using the nearest-rank method.
+func percentile(samples []float64, p float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
 }
 
 func (s *Server) handleNamespaceRevert(w http.ResponseWriter, r *http.Request, nsName string) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	ctx := r.Context()
-	operatorNs := getOperatorNamespace()
-
-	var opt finopsv1.NamespaceOptimization
-	if err := s.Client.Get(ctx, client.ObjectKey{Name: nsName, Namespace: operatorNs}, &opt); err != nil {
-		http.Error(w, "Optimization info not found", http.StatusNotFound)
+	opt, err := s.resolveNamespaceOptimization(ctx, nsName)
+	if errors.IsNotFound(err) {
+		writeJSONError(w, "Optimization info not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	for _, w := range opt.Status.Workloads {
-		if w.Kind == "Deployment" {
-			deploy := &appsv1.Deployment{}
-			if err := s.Client.Get(ctx, client.ObjectKey{Name: w.Name, Namespace: nsName}, deploy); err == nil {
-				if len(deploy.Spec.Template.Spec.Containers) > 0 {
-					deploy.Spec.Template.Spec.Containers[0].Resources.Requests = corev1.ResourceList{
-						corev1.ResourceCPU:    resource.MustParse(w.Original.CPURequest),
-						corev1.ResourceMemory: resource.MustParse(w.Original.MemoryRequest),
-					}
-					deploy.Spec.Template.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
-						corev1.ResourceCPU:    resource.MustParse(w.Original.CPULimit),
-						corev1.ResourceMemory: resource.MustParse(w.Original.MemoryLimit),
-					}
-					s.Client.Update(ctx, deploy)
-				}
-			}
-		} else if w.Kind == "StatefulSet" {
-			sts := &appsv1.StatefulSet{}
-			if err := s.Client.Get(ctx, client.ObjectKey{Name: w.Name, Namespace: nsName}, sts); err == nil {
-				if len(sts.Spec.Template.Spec.Containers) > 0 {
-					sts.Spec.Template.Spec.Containers[0].Resources.Requests = corev1.ResourceList{
-						corev1.ResourceCPU:    resource.MustParse(w.Original.CPURequest),
-						corev1.ResourceMemory: resource.MustParse(w.Original.MemoryRequest),
-					}
-					sts.Spec.Template.Spec.Containers[0].Resources.Limits = corev1.ResourceList{
-						corev1.ResourceCPU:    resource.MustParse(w.Original.CPULimit),
-						corev1.ResourceMemory: resource.MustParse(w.Original.MemoryLimit),
-					}
-					s.Client.Update(ctx, sts)
-				}
-			}
+	result := RevertResult{}
+	var failed []finopsv1.WorkloadOptimization
+	workloads := slices.Clone(opt.Status.Workloads)
+	sortWorkloads(workloads)
+	for _, wo := range workloads {
+		err := s.revertWorkload(ctx, nsName, wo)
+		switch {
+		case err == nil:
+			result.Reverted++
+		case errors.IsNotFound(err):
+			// Deleted since it was optimized, there is nothing left to restore
+			result.Skipped = append(result.Skipped, finopsv1.SkippedWorkload{Name: wo.Name, Kind: wo.Kind, Reason: "Workload no longer exists"})
+		default:
+			logf.Log.Error(err, "Failed to revert optimization", "namespace", nsName, "kind", wo.Kind, "name", wo.Name)
+			result.Skipped = append(result.Skipped, finopsv1.SkippedWorkload{Name: wo.Name, Kind: wo.Kind, Reason: "Update failed: " + err.Error()})
+			failed = append(failed, wo)
 		}
 	}
 
-	opt.Status.Active = false
-	s.Client.Status().Update(ctx, &opt)
+	// Workloads that could not be restored stay recorded so that reverting again retries them
+	opt.Status.Active = len(failed) > 0
+	if opt.Status.Active {
+		opt.Status.Workloads = failed
+	}
+	if err := s.Client.Status().Update(ctx, opt); err != nil {
+		writeJSONError(w, "Failed to update optimization status: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.audit(r, auditRevert, nsName, opt.Name, opt)
 
-	w.WriteHeader(http.StatusOK)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
 }
 
-func (s *Server) handleNamespaceOptimizationInfo(w http.ResponseWriter, r *http.Request, nsName string) {
-	ctx := r.Context()
-	operatorNs := getOperatorNamespace()
+// RevertResult is returned by POST /api/namespaces/{ns}/revert
+type RevertResult struct {
+	Reverted int `json:"reverted"`
+	// Skipped lists the workloads that were deleted since the optimization or could not be
+	// updated, the latter are retried by the next revert
+	Skipped []finopsv1.SkippedWorkload `json:"skipped,omitempty"`
+}
+
+// revertWorkload restores the original resources of an optimized workload and removes its
+// OptimizedByAnnotation. A NotFound error means the workload was deleted.
+func (s *Server) revertWorkload(ctx context.Context, nsName string, wo finopsv1.WorkloadOptimization) error {
+	obj, spec, _, err := s.getWorkload(ctx, nsName, wo.Kind, wo.Name)
+	if err != nil {
+		return err
+	}
+
+	restoreContainers(spec, wo)
+	annotations := obj.GetAnnotations()
+	delete(annotations, OptimizedByAnnotation)
+	obj.SetAnnotations(annotations)
+	return s.Client.Update(ctx, obj)
+}
+
+// getWorkload fetches an optimizable workload along with its pod spec and replicas.
+func (s *Server) getWorkload(ctx context.Context, nsName, kind, name string) (client.Object, *corev1.PodSpec, int32, error) {
+	var obj client.Object
+	var spec *corev1.PodSpec
+	var replicas **int32
+	switch kind {
+	case "Deployment":
+		deploy := &appsv1.Deployment{}
+		obj, spec, replicas = deploy, &deploy.Spec.Template.Spec, &deploy.Spec.Replicas
+	case "StatefulSet":
+		sts := &appsv1.StatefulSet{}
+		obj, spec, replicas = sts, &sts.Spec.Template.Spec, &sts.Spec.Replicas
+	default:
+		return nil, nil, 0, fmt.Errorf("unsupported kind %s", kind)
+	}
+	if err := s.Client.Get(ctx, client.ObjectKey{Name: name, Namespace: nsName}, obj); err != nil {
+		return nil, nil, 0, err
+	}
+	if *replicas == nil {
+		return obj, spec, 1, nil
+	}
+	return obj, spec, **replicas, nil
+}
 
-	var opt finopsv1.NamespaceOptimization
-	if err := s.Client.Get(ctx, client.ObjectKey{Name: nsName, Namespace: operatorNs}, &opt); err != nil {
+func (s *Server) handleNamespaceOptimizationInfo(w http.ResponseWriter, r *http.Request, nsName string) {
+	opt, err := s.resolveNamespaceOptimization(r.Context(), nsName)
+	if err != nil {
 		if errors.IsNotFound(err) {
 			w.Header().Set("Content-Type", "application/json")
 			json.NewEncoder(w).Encode(map[string]interface{}{"active": false})
 			return
 		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -1135,13 +2129,66 @@ func (s *Server) handleNamespaceOptimizationInfo(w http.ResponseWriter, r *http.
 	json.NewEncoder(w).Encode(opt.Status)
 }
 
+// handleNamespaceOptimizationHistory lists the recorded optimization runs, oldest first.
+func (s *Server) handleNamespaceOptimizationHistory(w http.ResponseWriter, r *http.Request, nsName string) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	history := []finopsv1.OptimizationSnapshot{}
+	if opt, err := s.resolveNamespaceOptimization(r.Context(), nsName); err != nil {
+		if !errors.IsNotFound(err) {
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	} else if opt.Status.History != nil {
+		history = opt.Status.History
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// VersionInfo describes the running operator build, returned by /api/version
+type VersionInfo struct {
+	Version          string `json:"version"`
+	Commit           string `json:"commit"`
+	BuildDate        string `json:"buildDate"`
+	GoVersion        string `json:"goVersion"`
+	ServerVersion    string `json:"serverVersion,omitempty"`
+	MetricsAvailable bool   `json:"metricsAvailable"`
+}
+
 func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"version": Version,
+	json.NewEncoder(w).Encode(VersionInfo{
+		Version:          Version,
+		Commit:           Commit,
+		BuildDate:        BuildDate,
+		GoVersion:        runtime.Version(),
+		ServerVersion:    s.serverVersion(),
+		MetricsAvailable: s.MetricsClient != nil,
 	})
 }
 
+// serverVersion returns the Kubernetes server version, asking discovery until it first
+// succeeds. An empty string means it could not be detected yet.
+func (s *Server) serverVersion() string {
+	s.k8sVersionMu.Lock()
+	defer s.k8sVersionMu.Unlock()
+	if s.k8sVersion != "" || s.K8sClient == nil {
+		return s.k8sVersion
+	}
+	version, err := s.K8sClient.Discovery().ServerVersion()
+	if err != nil {
+		logf.Log.Error(err, "Failed to get k8s version")
+		return ""
+	}
+	s.k8sVersion = version.GitVersion
+	return s.k8sVersion
+}
+
 func handleOpenAPISpec(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/x-yaml")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -3,22 +3,40 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+	"github.com/migalsp/kubex-operator/internal/reconcilestats"
+	"github.com/migalsp/kubex-operator/internal/scaling"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/version"
 	fakediscovery "k8s.io/client-go/discovery/fake"
 	"k8s.io/client-go/kubernetes/fake"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 func buildMockServerWithK8s() *Server {
@@ -26,7 +44,7 @@ func buildMockServerWithK8s() *Server {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(finopsv1.AddToScheme(scheme))
 
-	client := fakeclient.NewClientBuilder().WithScheme(scheme).Build()
+	client := fakeclient.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&finopsv1.NamespaceOptimization{}).Build()
 	k8sClient := fake.NewSimpleClientset()
 
 	k8sClient.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{
@@ -40,6 +58,29 @@ func buildMockServerWithK8s() *Server {
 	}
 }
 
+func TestHandleOperatorReconcilers(t *testing.T) {
+	server := buildMockServerWithK8s()
+	server.Reconcilers = reconcilestats.NewRecorder()
+	rec := server.Reconcilers.Wrap("scalingconfig", reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+		return reconcile.Result{}, fmt.Errorf("boom")
+	}))
+	rec.Reconcile(context.Background(), reconcile.Request{})
+
+	rr := httptest.NewRecorder()
+	server.handleOperatorReconcilers(rr, httptest.NewRequest("GET", "/api/operator/reconcilers", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", rr.Code)
+	}
+
+	var stats []reconcilestats.Stats
+	if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 1 || stats[0].Controller != "scalingconfig" || stats[0].Reconciles != 1 || stats[0].Errors != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
 func TestHandleOperatorHealth(t *testing.T) {
 	os.Setenv("HOSTNAME", "kubex-operator-1234")
 	os.Setenv("POD_NAMESPACE", "kubex")
@@ -76,6 +117,89 @@ func TestHandleOperatorHealth(t *testing.T) {
 	}
 }
 
+func TestOperatorLogContainer(t *testing.T) {
+	os.Setenv("HOSTNAME", "kubex-operator-1234")
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("HOSTNAME")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServerWithK8s()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "kubex-operator-1234", Namespace: "kubex"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "manager"},
+			{Name: "metrics-proxy"},
+		}},
+	}
+	if _, err := server.K8sClient.CoreV1().Pods("kubex").Create(context.Background(), pod, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/operator/logs?tailLines=20", nil)
+	rr := httptest.NewRecorder()
+	_, _, opts, ok := server.operatorLogOptions(rr, req, true)
+	if !ok {
+		t.Fatalf("expected valid options, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if opts.Container != "manager" {
+		t.Errorf("expected the first container by default, got %q", opts.Container)
+	}
+	if opts.TailLines == nil || *opts.TailLines != 20 {
+		t.Errorf("expected tailLines 20, got %v", opts.TailLines)
+	}
+
+	req = httptest.NewRequest("GET", "/api/operator/logs/download?container=metrics-proxy", nil)
+	rr = httptest.NewRecorder()
+	_, _, opts, ok = server.operatorLogOptions(rr, req, false)
+	if !ok || opts.Container != "metrics-proxy" || opts.TailLines != nil {
+		t.Errorf("expected full logs of metrics-proxy, got %+v", opts)
+	}
+
+	req = httptest.NewRequest("GET", "/api/operator/logs?container=sidecar", nil)
+	rr = httptest.NewRecorder()
+	server.handleOperatorLogs(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown container, got %d", rr.Code)
+	}
+	var apiErr map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&apiErr); err != nil {
+		t.Fatal(err)
+	}
+	if msg, _ := apiErr["error"].(string); !strings.Contains(msg, "manager, metrics-proxy") {
+		t.Errorf("expected the available containers in the error, got %q", msg)
+	}
+}
+
+func TestHandleOperatorLogsStream(t *testing.T) {
+	os.Setenv("HOSTNAME", "kubex-operator-1234")
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("HOSTNAME")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServerWithK8s()
+
+	req := httptest.NewRequest("GET", "/api/operator/logs/stream?tailLines=10", nil)
+	rr := httptest.NewRecorder()
+	server.handleOperatorLogsStream(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr.Body.String() != "fake logs" {
+		t.Errorf("expected streamed logs, got %q", rr.Body.String())
+	}
+	if !rr.Flushed {
+		t.Error("expected the stream to be flushed")
+	}
+
+	req = httptest.NewRequest("GET", "/api/operator/logs/stream?tailLines=-1", nil)
+	rr = httptest.NewRecorder()
+	server.handleOperatorLogsStream(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid tailLines, got %d", rr.Code)
+	}
+}
+
 func TestHandleClusterInfo(t *testing.T) {
 	server := buildMockServerWithK8s()
 
@@ -102,6 +226,33 @@ func TestHandleClusterInfo(t *testing.T) {
 	}
 }
 
+func TestHandleVersion(t *testing.T) {
+	server := buildMockServerWithK8s()
+
+	rr := httptest.NewRecorder()
+	server.handleVersion(rr, httptest.NewRequest("GET", "/api/version", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	var info VersionInfo
+	if err := json.NewDecoder(rr.Body).Decode(&info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Version != Version || info.Commit != Commit || info.BuildDate != BuildDate {
+		t.Errorf("expected the ldflags build info, got %+v", info)
+	}
+	if info.GoVersion == "" {
+		t.Errorf("expected the Go version to be reported")
+	}
+	if info.ServerVersion != "v1.35.0" {
+		t.Errorf("expected server version 'v1.35.0', got %q", info.ServerVersion)
+	}
+	if info.MetricsAvailable {
+		t.Errorf("expected metrics to be reported unavailable without a metrics client")
+	}
+}
+
 func TestHandleNamespaces(t *testing.T) {
 	server := buildMockServerWithK8s()
 
@@ -198,6 +349,60 @@ func TestServeHistory(t *testing.T) {
 	}
 }
 
+func TestServeHistoryRange(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServerWithK8s()
+	now := time.Now().UTC().Truncate(time.Second)
+	var history []finopsv1.MetricDataPoint
+	for i := 60; i >= 0; i -= 10 {
+		history = append(history, finopsv1.MetricDataPoint{Timestamp: metav1.NewTime(now.Add(-time.Duration(i) * time.Minute))})
+	}
+	server.Client.Create(context.Background(), &finopsv1.NamespaceFinOps{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Namespace: "kubex"},
+		Status:     finopsv1.NamespaceFinOpsStatus{History: history},
+	})
+
+	ts := func(d time.Duration) string { return url.QueryEscape(now.Add(d).Format(time.RFC3339)) }
+	tests := []struct {
+		name  string
+		query string
+		code  int
+		want  int
+	}{
+		{"last", "last=25m", http.StatusOK, 3},
+		{"from and to", "from=" + ts(-50*time.Minute) + "&to=" + ts(-30*time.Minute), http.StatusOK, 3},
+		{"to only", "to=" + ts(-45*time.Minute), http.StatusOK, 2},
+		{"empty range", "from=" + ts(time.Hour), http.StatusOK, 0},
+		{"bad from", "from=yesterday", http.StatusBadRequest, 0},
+		{"bad last", "last=-5m", http.StatusBadRequest, 0},
+		{"reversed", "from=" + ts(0) + "&to=" + ts(-time.Hour), http.StatusBadRequest, 0},
+		{"last and from", "last=5m&from=" + ts(0), http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/api/namespaces/test-ns/history?"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			server.handleNamespaceRouting(rr, req)
+
+			if rr.Code != tt.code {
+				t.Fatalf("expected %d, got %d: %s", tt.code, rr.Code, rr.Body.String())
+			}
+			if tt.code != http.StatusOK {
+				return
+			}
+			var parsed []finopsv1.MetricDataPoint
+			if err := json.NewDecoder(rr.Body).Decode(&parsed); err != nil {
+				t.Fatal(err)
+			}
+			if parsed == nil || len(parsed) != tt.want {
+				t.Errorf("expected %d points, got %v", tt.want, parsed)
+			}
+		})
+	}
+}
+
 func TestServePods(t *testing.T) {
 	server := buildMockServerWithK8s()
 
@@ -218,6 +423,46 @@ func TestServePods(t *testing.T) {
 	}
 }
 
+func TestServePodsRestartsAndAge(t *testing.T) {
+	server := buildMockServerWithK8s()
+	server.Client.Create(context.Background(), &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "web-1",
+			Namespace:         "test-ns",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-3 * time.Hour)),
+		},
+		Spec: corev1.PodSpec{NodeName: "node-1", Containers: []corev1.Container{{Name: "app"}, {Name: "proxy"}}},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "app", RestartCount: 4},
+				{Name: "proxy", RestartCount: 1},
+			},
+		},
+	})
+
+	rr := httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, httptest.NewRequest("GET", "/api/namespaces/test-ns/pods", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %v", rr.Code)
+	}
+
+	var parsed []PodDetail
+	if err := json.NewDecoder(rr.Body).Decode(&parsed); err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != 1 {
+		t.Fatalf("expected 1 pod, got %d", len(parsed))
+	}
+	if p := parsed[0]; p.RestartCount != 5 || p.Age != "3h" || p.NodeName != "node-1" {
+		t.Errorf("expected 5 restarts, age 3h on node-1, got %+v", p)
+	}
+	// Neither container sets requests nor limits
+	if p := parsed[0]; p.QOSClass != "BestEffort" {
+		t.Errorf("expected BestEffort QoS class, got %q", p.QOSClass)
+	}
+}
+
 func TestServeWorkloads(t *testing.T) {
 	server := buildMockServerWithK8s()
 
@@ -259,145 +504,1610 @@ func TestHandleNamespaceOptimize(t *testing.T) {
 	rr := httptest.NewRecorder()
 	server.handleNamespaceRouting(rr, req)
 
-	if rr.Code != http.StatusInternalServerError {
-		t.Errorf("expected 500 InternalsServerError when no metrics client exists, got %v", rr.Code)
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 Service Unavailable when no metrics client exists, got %v", rr.Code)
 	}
 }
 
-func TestHandleNamespaceRevert(t *testing.T) {
+func TestHandleNamespaceOptimizeDryRun(t *testing.T) {
 	os.Setenv("POD_NAMESPACE", "kubex")
 	defer os.Unsetenv("POD_NAMESPACE")
 
 	server := buildMockServerWithK8s()
+	server.MetricsClient = webMetricsClient()
 
-	// Pre-create the required finops object
-	opt := &finopsv1.NamespaceOptimization{
+	nsFinOps := &finopsv1.NamespaceFinOps{
 		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Namespace: "kubex"},
-		Status: finopsv1.NamespaceOptimizationStatus{
-			Workloads: []finopsv1.WorkloadOptimization{
-				{
-					Name: "test-deploy",
-					Kind: "Deployment",
-					Original: finopsv1.ResourceValues{
-						CPURequest: "100m",
-					},
+		Status: finopsv1.NamespaceFinOpsStatus{
+			History: []finopsv1.MetricDataPoint{
+				{Timestamp: metav1.Now(), CPU: finopsv1.ResourceMetrics{Usage: "10m"}},
+			},
+		},
+	}
+	server.Client.Create(context.Background(), nsFinOps)
+	server.Client.Create(context.Background(), &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-abc",
+			Namespace:       "test-ns",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", APIVersion: "apps/v1", UID: "web"}},
+		},
+	})
+
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-ns"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name: "app",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+						},
+					}},
 				},
 			},
 		},
 	}
-	server.Client.Create(context.Background(), opt)
+	server.Client.Create(context.Background(), deploy)
 
-	req, _ := http.NewRequest("POST", "/api/namespaces/test-ns/revert", nil)
+	req, _ := http.NewRequest("POST", "/api/namespaces/test-ns/optimize?dryRun=true", nil)
 	rr := httptest.NewRecorder()
 	server.handleNamespaceRouting(rr, req)
 
-	// Will likely return Ok as finding no deployment gracefully skips
 	if rr.Code != http.StatusOK {
-		t.Errorf("expected 200 OK, got %v", rr.Code)
+		t.Fatalf("expected 200 OK, got %v: %s", rr.Code, rr.Body.String())
+	}
+
+	var workloads []finopsv1.WorkloadOptimization
+	if err := json.NewDecoder(rr.Body).Decode(&workloads); err != nil {
+		t.Fatal(err)
+	}
+	if len(workloads) != 1 || workloads[0].Name != "web" || workloads[0].Optimized.CPURequest != "20m" {
+		t.Errorf("expected preview of web sized to the floor, got %+v", workloads)
+	}
+
+	var current appsv1.Deployment
+	server.Client.Get(context.Background(), client.ObjectKey{Name: "web", Namespace: "test-ns"}, &current)
+	if got := current.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu().String(); got != "1" {
+		t.Errorf("expected deployment to be left untouched on dry-run, got cpu request %s", got)
+	}
+
+	var opt finopsv1.NamespaceOptimization
+	if err := server.Client.Get(context.Background(), client.ObjectKey{Name: "test-ns", Namespace: "kubex"}, &opt); err == nil {
+		t.Errorf("expected no NamespaceOptimization to be stored on dry-run")
 	}
 }
 
-func TestHandleScalingGroups(t *testing.T) {
+func TestHandleNamespaceOptimizePropose(t *testing.T) {
 	os.Setenv("POD_NAMESPACE", "kubex")
 	defer os.Unsetenv("POD_NAMESPACE")
 
 	server := buildMockServerWithK8s()
+	server.MetricsClient = webMetricsClient()
+	ctx := context.Background()
 
-	group := &finopsv1.ScalingGroup{
-		ObjectMeta: metav1.ObjectMeta{Name: "test-group", Namespace: "kubex"},
-		Spec: finopsv1.ScalingGroupSpec{
-			Active: new(bool),
+	server.Client.Create(ctx, &finopsv1.NamespaceFinOps{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Namespace: "kubex"},
+		Status: finopsv1.NamespaceFinOpsStatus{
+			History: []finopsv1.MetricDataPoint{
+				{Timestamp: metav1.Now(), CPU: finopsv1.ResourceMetrics{Usage: "10m"}},
+			},
+		},
+	})
+	server.Client.Create(ctx, &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-abc",
+			Namespace:       "test-ns",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", APIVersion: "apps/v1", UID: "web"}},
+		},
+	})
+	server.Client.Create(ctx, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-ns"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name: "app",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+						},
+					}},
+				},
+			},
 		},
+	})
+
+	post := func(path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		server.handleNamespaceRouting(rr, httptest.NewRequest("POST", path, nil))
+		return rr
+	}
+	cpuRequest := func() string {
+		var current appsv1.Deployment
+		server.Client.Get(ctx, client.ObjectKey{Name: "web", Namespace: "test-ns"}, &current)
+		return current.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu().String()
 	}
-	server.Client.Create(context.Background(), group)
 
-	req, _ := http.NewRequest("GET", "/api/scaling/groups", nil)
-	rr := httptest.NewRecorder()
-	server.handleScalingGroups(rr, req)
+	if rr := post("/api/namespaces/test-ns/optimize/apply"); rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 without a proposal, got %d", rr.Code)
+	}
 
+	rr := post("/api/namespaces/test-ns/optimize?propose=true")
 	if rr.Code != http.StatusOK {
-		t.Errorf("expected 200 OK, got %v", rr.Code)
+		t.Fatalf("expected 200 OK, got %v: %s", rr.Code, rr.Body.String())
 	}
-
-	var parsed []finopsv1.ScalingGroup
-	if err := json.NewDecoder(rr.Body).Decode(&parsed); err != nil {
+	var status finopsv1.NamespaceOptimizationStatus
+	if err := json.NewDecoder(rr.Body).Decode(&status); err != nil {
 		t.Fatal(err)
 	}
-	if len(parsed) != 1 || parsed[0].Name != "test-group" {
-		t.Errorf("expected 1 scaling group, got %v", len(parsed))
+	if status.Active || status.Proposal == nil || len(status.Proposal.Workloads) != 1 || status.Proposal.Workloads[0].Optimized.CPURequest != "20m" {
+		t.Errorf("expected an inactive record holding the proposal, got %+v", status)
+	}
+	if got := cpuRequest(); got != "1" {
+		t.Errorf("expected deployment to be left untouched by the proposal, got cpu request %s", got)
+	}
+
+	rr = post("/api/namespaces/test-ns/optimize/apply")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %v: %s", rr.Code, rr.Body.String())
+	}
+	status = finopsv1.NamespaceOptimizationStatus{}
+	json.NewDecoder(rr.Body).Decode(&status)
+	if !status.Active || status.Proposal != nil || len(status.Workloads) != 1 || status.Workloads[0].Original.CPURequest != "1" {
+		t.Errorf("expected the proposal to be applied and cleared, got %+v", status)
+	}
+	if got := cpuRequest(); got != "20m" {
+		t.Errorf("expected the proposed cpu request 20m, got %s", got)
+	}
+
+	// A workload changed by hand after the proposal is left alone
+	if rr := post("/api/namespaces/test-ns/revert"); rr.Code != http.StatusOK {
+		t.Fatalf("revert failed: %s", rr.Body.String())
+	}
+	post("/api/namespaces/test-ns/optimize?propose=true")
+	var current appsv1.Deployment
+	server.Client.Get(ctx, client.ObjectKey{Name: "web", Namespace: "test-ns"}, &current)
+	current.Spec.Template.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = resource.MustParse("2")
+	server.Client.Update(ctx, &current)
+
+	rr = post("/api/namespaces/test-ns/optimize/apply")
+	var noop OptimizeNoopResult
+	json.NewDecoder(rr.Body).Decode(&noop)
+	if len(noop.Skipped) != 1 || noop.Skipped[0].Reason != "Resources changed since the proposal" {
+		t.Errorf("expected the changed workload to be skipped, got %s", rr.Body.String())
+	}
+	if got := cpuRequest(); got != "2" {
+		t.Errorf("expected the hand-tuned cpu request to be kept, got %s", got)
 	}
 }
 
-func TestHandleScalingConfigs(t *testing.T) {
+func TestHandleNamespaceOptimizeStableOrder(t *testing.T) {
 	os.Setenv("POD_NAMESPACE", "kubex")
 	defer os.Unsetenv("POD_NAMESPACE")
 
-	server := buildMockServerWithK8s()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(finopsv1.AddToScheme(scheme))
 
-	config := &finopsv1.ScalingConfig{
-		ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "kubex"},
-		Spec: finopsv1.ScalingConfigSpec{
-			Active: new(bool),
+	// Every other List comes back reversed, as nothing guarantees the order of a real one
+	reverse := false
+	server := &Server{Client: fakeclient.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&finopsv1.NamespaceOptimization{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				if err := c.List(ctx, list, opts...); err != nil {
+					return err
+				}
+				switch l := list.(type) {
+				case *appsv1.DeploymentList:
+					reverse = !reverse
+					if reverse {
+						slices.Reverse(l.Items)
+					}
+				case *appsv1.StatefulSetList:
+					if reverse {
+						slices.Reverse(l.Items)
+					}
+				}
+				return nil
+			},
+		}).Build()}
+
+	ctx := context.Background()
+	server.Client.Create(ctx, &finopsv1.NamespaceFinOps{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Namespace: "kubex"},
+		Status: finopsv1.NamespaceFinOpsStatus{
+			History: []finopsv1.MetricDataPoint{{Timestamp: metav1.Now(), CPU: finopsv1.ResourceMetrics{Usage: "30m"}}},
 		},
+	})
+	var pods []metricsv1beta1.PodMetrics
+	container := []corev1.Container{{
+		Name:      "app",
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+	}}
+	for _, name := range []string{"web", "api", "worker"} {
+		server.Client.Create(ctx, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns"},
+			Spec:       appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: container}}},
+		})
+		server.Client.Create(ctx, &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name:            name + "-abc",
+			Namespace:       "test-ns",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: name, APIVersion: "apps/v1", UID: types.UID(name)}},
+		}})
+		pods = append(pods, metricsv1beta1.PodMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-abc-1", Namespace: "test-ns", OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: name + "-abc"}}},
+			Containers: []metricsv1beta1.ContainerMetrics{{Name: "app", Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m"), corev1.ResourceMemory: resource.MustParse("10Mi")}}},
+		})
 	}
-	server.Client.Create(context.Background(), config)
+	for _, name := range []string{"db", "cache"} {
+		server.Client.Create(ctx, &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns"},
+			Spec:       appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: container}}},
+		})
+		pods = append(pods, metricsv1beta1.PodMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-0", Namespace: "test-ns", OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: name}}},
+			Containers: []metricsv1beta1.ContainerMetrics{{Name: "app", Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m"), corev1.ResourceMemory: resource.MustParse("10Mi")}}},
+		})
+	}
+	metricsClient := metricsfake.NewSimpleClientset()
+	metricsClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &metricsv1beta1.PodMetricsList{Items: pods}, nil
+	})
+	server.MetricsClient = metricsClient
+
+	want := []string{"Deployment/api", "Deployment/web", "Deployment/worker", "StatefulSet/cache", "StatefulSet/db"}
+	for run := 1; run <= 2; run++ {
+		rr := httptest.NewRecorder()
+		server.handleNamespaceRouting(rr, httptest.NewRequest("POST", "/api/namespaces/test-ns/optimize", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("run %d: expected 200 OK, got %v: %s", run, rr.Code, rr.Body.String())
+		}
+		var status finopsv1.NamespaceOptimizationStatus
+		json.NewDecoder(rr.Body).Decode(&status)
+		var got []string
+		for _, wo := range status.Workloads {
+			got = append(got, wo.Kind+"/"+wo.Name)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("run %d: expected workloads %v, got %v", run, want, got)
+		}
+	}
+}
 
-	req, _ := http.NewRequest("GET", "/api/scaling/configs", nil)
+// webMetricsClient reports a small usage for a pod of the web Deployment in test-ns.
+func webMetricsClient() *metricsfake.Clientset {
+	metricsClient := metricsfake.NewSimpleClientset()
+	metricsClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &metricsv1beta1.PodMetricsList{Items: []metricsv1beta1.PodMetrics{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "web-abc-1",
+				Namespace:       "test-ns",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-abc"}},
+			},
+			Containers: []metricsv1beta1.ContainerMetrics{{
+				Name:  "app",
+				Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m"), corev1.ResourceMemory: resource.MustParse("10Mi")},
+			}},
+		}}}, nil
+	})
+	return metricsClient
+}
+
+func TestHandleNamespaceOptimizeSkipsIdleWorkloads(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServerWithK8s()
+	server.MetricsClient = metricsfake.NewSimpleClientset()
+
+	server.Client.Create(context.Background(), &finopsv1.NamespaceFinOps{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Namespace: "kubex"},
+		Status: finopsv1.NamespaceFinOpsStatus{
+			History: []finopsv1.MetricDataPoint{
+				{Timestamp: metav1.Now(), CPU: finopsv1.ResourceMetrics{Usage: "100m"}},
+			},
+		},
+	})
+	server.Client.Create(context.Background(), &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: "test-ns"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name: "app",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+						},
+					}},
+				},
+			},
+		},
+	})
+
+	req, _ := http.NewRequest("POST", "/api/namespaces/test-ns/optimize", nil)
 	rr := httptest.NewRecorder()
-	server.handleScalingConfigs(rr, req)
+	server.handleNamespaceRouting(rr, req)
 
 	if rr.Code != http.StatusOK {
-		t.Errorf("expected 200 OK, got %v", rr.Code)
+		t.Fatalf("expected 200 OK, got %v: %s", rr.Code, rr.Body.String())
 	}
 
-	var parsed []finopsv1.ScalingConfig
-	if err := json.NewDecoder(rr.Body).Decode(&parsed); err != nil {
+	var result OptimizeNoopResult
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
 		t.Fatal(err)
 	}
-	if len(parsed) != 1 || parsed[0].Name != "test-config" {
-		t.Errorf("expected 1 scaling config, got %v", len(parsed))
+	if result.Optimized != 0 || len(result.Skipped) != 1 || result.Skipped[0].Name != "batch" || result.Skipped[0].Reason == "" {
+		t.Errorf("expected batch to be skipped with a reason, got %+v", result)
+	}
+
+	var current appsv1.Deployment
+	server.Client.Get(context.Background(), client.ObjectKey{Name: "batch", Namespace: "test-ns"}, &current)
+	if got := current.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu().String(); got != "2" {
+		t.Errorf("expected skipped deployment to keep its requests, got cpu request %s", got)
 	}
 }
 
-func TestHandleClusterNodes(t *testing.T) {
+func TestHandleNamespaceOptimizeSkipsUnmanaged(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
 	server := buildMockServerWithK8s()
+	server.MetricsClient = webMetricsClient()
+	recorder := record.NewFakeRecorder(10)
+	server.Recorder = recorder
 
-	// Add a dummy node
-	node := &corev1.Node{
-		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
-		Status: corev1.NodeStatus{
-			Allocatable: corev1.ResourceList{
-				corev1.ResourceCPU:    resource.MustParse("2"),
-				corev1.ResourceMemory: resource.MustParse("4Gi"),
-			},
-			Conditions: []corev1.NodeCondition{
-				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+	server.Client.Create(context.Background(), &finopsv1.NamespaceFinOps{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Namespace: "kubex"},
+		Status: finopsv1.NamespaceFinOpsStatus{
+			History: []finopsv1.MetricDataPoint{
+				{Timestamp: metav1.Now(), CPU: finopsv1.ResourceMetrics{Usage: "100m"}},
 			},
-			NodeInfo: corev1.NodeSystemInfo{
-				OSImage:        "Ubuntu",
-				Architecture:   "amd64",
-				KernelVersion:  "5.15",
-				KubeletVersion: "v1.28.0",
+		},
+	})
+	server.Client.Create(context.Background(), &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "test-ns",
+			Annotations: map[string]string{scaling.ManagedAnnotation: "false"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name: "app",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+						},
+					}},
+				},
 			},
 		},
-	}
-	server.K8sClient.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+	})
 
-	req, _ := http.NewRequest("GET", "/api/cluster/nodes", nil)
+	req, _ := http.NewRequest("POST", "/api/namespaces/test-ns/optimize", nil)
 	rr := httptest.NewRecorder()
-	server.handleClusterNodes(rr, req)
+	server.handleNamespaceRouting(rr, req)
 
 	if rr.Code != http.StatusOK {
-		t.Errorf("expected 200 OK, got %v", rr.Code)
+		t.Fatalf("expected 200 OK, got %v: %s", rr.Code, rr.Body.String())
 	}
-
-	var parsed map[string]interface{}
-	if err := json.NewDecoder(rr.Body).Decode(&parsed); err != nil {
+	var result OptimizeNoopResult
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
 		t.Fatal(err)
 	}
+	if result.Optimized != 0 || len(result.Skipped) != 1 || result.Skipped[0].Reason != "Opted out of Kubex management" {
+		t.Errorf("expected web to be skipped as opted out, got %+v", result)
+	}
 
-	nodes, ok := parsed["nodes"].([]interface{})
-	if !ok || len(nodes) != 1 {
-		t.Errorf("expected 1 node in response, got %v", parsed)
+	var current appsv1.Deployment
+	server.Client.Get(context.Background(), client.ObjectKey{Name: "web", Namespace: "test-ns"}, &current)
+	if got := current.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu().String(); got != "2" {
+		t.Errorf("expected the unmanaged deployment to keep its requests, got cpu request %s", got)
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, scaling.OptedOutReason) {
+			t.Errorf("expected an %s event, got %q", scaling.OptedOutReason, event)
+		}
+	default:
+		t.Error("expected an event confirming the opt-out")
+	}
+}
+
+func TestHandleNamespaceOptimizeNothingQualifies(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	zero := int32(0)
+	tests := []struct {
+		name      string
+		workloads []client.Object
+		pods      []metricsv1beta1.PodMetrics
+		reason    string
+	}{
+		{
+			name: "all workloads scaled down",
+			workloads: []client.Object{&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-ns"},
+				Spec:       appsv1.DeploymentSpec{Replicas: &zero},
+			}},
+			reason: "All workloads are scaled down",
+		},
+		{
+			name:   "only unowned pods",
+			pods:   []metricsv1beta1.PodMetrics{{ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: "test-ns"}}},
+			reason: "No owned pods, 1 pods have no Deployment or StatefulSet owner",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := buildMockServerWithK8s()
+			ctx := context.Background()
+			metricsClient := metricsfake.NewSimpleClientset()
+			metricsClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return true, &metricsv1beta1.PodMetricsList{Items: tt.pods}, nil
+			})
+			server.MetricsClient = metricsClient
+
+			server.Client.Create(ctx, &finopsv1.NamespaceFinOps{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Namespace: "kubex"},
+				Status: finopsv1.NamespaceFinOpsStatus{
+					History: []finopsv1.MetricDataPoint{
+						{Timestamp: metav1.Now(), CPU: finopsv1.ResourceMetrics{Usage: "100m"}},
+					},
+				},
+			})
+			for _, obj := range tt.workloads {
+				server.Client.Create(ctx, obj)
+			}
+
+			rr := httptest.NewRecorder()
+			server.handleNamespaceRouting(rr, httptest.NewRequest("POST", "/api/namespaces/test-ns/optimize", nil))
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+			}
+
+			var result OptimizeNoopResult
+			if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+				t.Fatal(err)
+			}
+			if result.Optimized != 0 || result.Reason != tt.reason {
+				t.Errorf("expected no optimization because %q, got %+v", tt.reason, result)
+			}
+
+			var opt finopsv1.NamespaceOptimization
+			if err := server.Client.Get(ctx, client.ObjectKey{Name: "test-ns", Namespace: "kubex"}, &opt); err == nil {
+				t.Errorf("expected no optimization record, got %+v", opt.Status)
+			}
+		})
+	}
+}
+
+func TestHandleNamespaceOptimizeWhileScaledDown(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServerWithK8s()
+	server.MetricsClient = webMetricsClient()
+	ctx := context.Background()
+
+	server.Client.Create(ctx, &finopsv1.NamespaceFinOps{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Namespace: "kubex"},
+		Status: finopsv1.NamespaceFinOpsStatus{
+			History: []finopsv1.MetricDataPoint{
+				{Timestamp: metav1.Now(), CPU: finopsv1.ResourceMetrics{Usage: "10m"}},
+			},
+		},
+	})
+	server.Client.Create(ctx, &finopsv1.ScalingGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "non-prod", Namespace: "kubex"},
+		Spec:       finopsv1.ScalingGroupSpec{Namespaces: []string{"test-ns"}},
+		Status:     finopsv1.ScalingGroupStatus{Phase: "ScaledDown"},
+	})
+
+	req := httptest.NewRequest("POST", "/api/namespaces/test-ns/optimize", nil)
+	rr := httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var body map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body["phase"] != "ScaledDown" || body["error"] == "" {
+		t.Errorf("expected the phase in the error body, got %v", body)
+	}
+
+	var group finopsv1.ScalingGroup
+	server.Client.Get(ctx, client.ObjectKey{Name: "non-prod", Namespace: "kubex"}, &group)
+	group.Status.Phase = "ScaledUp"
+	server.Client.Update(ctx, &group)
+	rr = httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, httptest.NewRequest("POST", "/api/namespaces/test-ns/optimize?dryRun=true", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected 200 once scaled up, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestHandleNamespaceRevert(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServerWithK8s()
+
+	// Pre-create the required finops object
+	opt := &finopsv1.NamespaceOptimization{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Namespace: "kubex"},
+		Status: finopsv1.NamespaceOptimizationStatus{
+			Workloads: []finopsv1.WorkloadOptimization{
+				{
+					Name: "test-deploy",
+					Kind: "Deployment",
+					Original: finopsv1.ResourceValues{
+						CPURequest: "100m",
+					},
+				},
+			},
+		},
+	}
+	server.Client.Create(context.Background(), opt)
+
+	req, _ := http.NewRequest("POST", "/api/namespaces/test-ns/revert", nil)
+	rr := httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, req)
+
+	// The deployment is gone, it is reported instead of failing the revert
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %v", rr.Code)
+	}
+	var result RevertResult
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Reverted != 0 || len(result.Skipped) != 1 || result.Skipped[0].Name != "test-deploy" || result.Skipped[0].Reason != "Workload no longer exists" {
+		t.Errorf("expected test-deploy to be reported as missing, got %+v", result)
+	}
+	server.Client.Get(context.Background(), client.ObjectKey{Name: "test-ns", Namespace: "kubex"}, opt)
+	if opt.Status.Active {
+		t.Errorf("expected the optimization to be inactive after revert")
+	}
+}
+
+func TestRepeatedOptimizeRevertsToBaseline(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServerWithK8s()
+	server.MetricsClient = webMetricsClient()
+	ctx := context.Background()
+
+	server.Client.Create(ctx, &finopsv1.NamespaceFinOps{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Namespace: "kubex"},
+		Status: finopsv1.NamespaceFinOpsStatus{
+			History: []finopsv1.MetricDataPoint{
+				{Timestamp: metav1.Now(), CPU: finopsv1.ResourceMetrics{Usage: "10m"}},
+			},
+		},
+	})
+	server.Client.Create(ctx, &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-abc",
+			Namespace:       "test-ns",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", APIVersion: "apps/v1", UID: "web"}},
+		},
+	})
+	server.Client.Create(ctx, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-ns"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name: "app",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+						},
+					}},
+				},
+			},
+		},
+	})
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("POST", "/api/namespaces/test-ns/optimize", nil)
+		rr := httptest.NewRecorder()
+		server.handleNamespaceRouting(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("optimize %d: expected 200, got %d: %s", i+1, rr.Code, rr.Body.String())
+		}
+	}
+
+	var deploy appsv1.Deployment
+	server.Client.Get(ctx, client.ObjectKey{Name: "web", Namespace: "test-ns"}, &deploy)
+	if got := deploy.Annotations[OptimizedByAnnotation]; got != "kubex/test-ns" {
+		t.Errorf("expected the workload to point at kubex/test-ns, got %q", got)
+	}
+
+	var opt finopsv1.NamespaceOptimization
+	server.Client.Get(ctx, client.ObjectKey{Name: "test-ns", Namespace: "kubex"}, &opt)
+	if len(opt.Status.Workloads) != 1 || opt.Status.Workloads[0].Original.CPURequest != "1" {
+		t.Errorf("expected the baseline from before the first optimize, got %+v", opt.Status.Workloads)
+	}
+	if len(opt.Status.History) != 2 || opt.Status.History[1].Workloads[0].Original.CPURequest != "20m" {
+		t.Errorf("expected both runs in history, got %+v", opt.Status.History)
+	}
+
+	req := httptest.NewRequest("GET", "/api/namespaces/test-ns/optimization/history", nil)
+	rr := httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, req)
+	var history []finopsv1.OptimizationSnapshot
+	if err := json.NewDecoder(rr.Body).Decode(&history); err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 {
+		t.Errorf("expected 2 history entries, got %d", len(history))
+	}
+
+	req = httptest.NewRequest("POST", "/api/namespaces/test-ns/revert", nil)
+	rr = httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 on revert, got %d", rr.Code)
+	}
+
+	deploy = appsv1.Deployment{}
+	server.Client.Get(ctx, client.ObjectKey{Name: "web", Namespace: "test-ns"}, &deploy)
+	if got := deploy.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu().String(); got != "1" {
+		t.Errorf("expected revert to restore the original cpu request 1, got %s", got)
+	}
+	if _, ok := deploy.Annotations[OptimizedByAnnotation]; ok {
+		t.Errorf("expected revert to remove the %s annotation", OptimizedByAnnotation)
+	}
+}
+
+func TestOptimizeSingleResource(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServerWithK8s()
+	server.MetricsClient = webMetricsClient()
+	ctx := context.Background()
+
+	server.Client.Create(ctx, &finopsv1.NamespaceFinOps{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Namespace: "kubex"},
+		Status: finopsv1.NamespaceFinOpsStatus{
+			History: []finopsv1.MetricDataPoint{
+				{Timestamp: metav1.Now(), CPU: finopsv1.ResourceMetrics{Usage: "10m"}, Memory: finopsv1.ResourceMetrics{Usage: "10Mi"}},
+			},
+		},
+	})
+	server.Client.Create(ctx, &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-abc",
+			Namespace:       "test-ns",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", APIVersion: "apps/v1", UID: "web"}},
+		},
+	})
+	server.Client.Create(ctx, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-ns"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name: "app",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+							Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+						},
+					}},
+				},
+			},
+		},
+	})
+	container := func() corev1.Container {
+		var deploy appsv1.Deployment
+		server.Client.Get(ctx, client.ObjectKey{Name: "web", Namespace: "test-ns"}, &deploy)
+		return deploy.Spec.Template.Spec.Containers[0]
+	}
+	optimize := func(query string) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		server.handleNamespaceRouting(rr, httptest.NewRequest("POST", "/api/namespaces/test-ns/optimize"+query, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("optimize%s: expected 200, got %d: %s", query, rr.Code, rr.Body.String())
+		}
+	}
+
+	optimize("?resources=cpu")
+	c := container()
+	if c.Resources.Requests.Cpu().String() == "1" {
+		t.Errorf("expected the cpu request to be optimized")
+	}
+	if c.Resources.Requests.Memory().String() != "1Gi" || c.Resources.Limits.Memory().String() != "2Gi" {
+		t.Errorf("expected memory to be left untouched, got %v", c.Resources)
+	}
+	var opt finopsv1.NamespaceOptimization
+	server.Client.Get(ctx, client.ObjectKey{Name: "test-ns", Namespace: "kubex"}, &opt)
+	if original := opt.Status.Workloads[0].Containers[0].Original; original.CPURequest != "1" || original.MemoryRequest != "" || original.MemoryLimit != "" {
+		t.Errorf("expected only cpu in the revert record, got %+v", original)
+	}
+
+	optimize("?resources=memory")
+	server.Client.Get(ctx, client.ObjectKey{Name: "test-ns", Namespace: "kubex"}, &opt)
+	if original := opt.Status.Workloads[0].Containers[0].Original; original.CPURequest != "1" || original.MemoryRequest != "1Gi" {
+		t.Errorf("expected the revert record to cover both runs, got %+v", original)
+	}
+
+	rr := httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, httptest.NewRequest("POST", "/api/namespaces/test-ns/revert", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 on revert, got %d", rr.Code)
+	}
+	c = container()
+	if c.Resources.Requests.Cpu().String() != "1" || c.Resources.Requests.Memory().String() != "1Gi" || c.Resources.Limits.Memory().String() != "2Gi" {
+		t.Errorf("expected revert to restore both dimensions, got %v", c.Resources)
+	}
+
+	rr = httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, httptest.NewRequest("POST", "/api/namespaces/test-ns/optimize?resources=gpu", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown resources, got %d", rr.Code)
+	}
+}
+
+func TestHandleScalingGroups(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServerWithK8s()
+
+	group := &finopsv1.ScalingGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-group", Namespace: "kubex"},
+		Spec: finopsv1.ScalingGroupSpec{
+			Active: new(bool),
+		},
+	}
+	server.Client.Create(context.Background(), group)
+
+	req, _ := http.NewRequest("GET", "/api/scaling/groups", nil)
+	rr := httptest.NewRecorder()
+	server.handleScalingGroups(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected 200 OK, got %v", rr.Code)
+	}
+
+	var parsed []finopsv1.ScalingGroup
+	if err := json.NewDecoder(rr.Body).Decode(&parsed); err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != 1 || parsed[0].Name != "test-group" {
+		t.Errorf("expected 1 scaling group, got %v", len(parsed))
+	}
+}
+
+func TestHandleScalingConfigs(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServerWithK8s()
+
+	config := &finopsv1.ScalingConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "kubex"},
+		Spec: finopsv1.ScalingConfigSpec{
+			Active: new(bool),
+		},
+	}
+	server.Client.Create(context.Background(), config)
+
+	req, _ := http.NewRequest("GET", "/api/scaling/configs", nil)
+	rr := httptest.NewRecorder()
+	server.handleScalingConfigs(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected 200 OK, got %v", rr.Code)
+	}
+
+	var parsed []finopsv1.ScalingConfig
+	if err := json.NewDecoder(rr.Body).Decode(&parsed); err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != 1 || parsed[0].Name != "test-config" {
+		t.Errorf("expected 1 scaling config, got %v", len(parsed))
+	}
+}
+
+func TestHandleClusterNodes(t *testing.T) {
+	server := buildMockServerWithK8s()
+
+	// Add a dummy node
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			},
+			NodeInfo: corev1.NodeSystemInfo{
+				OSImage:        "Ubuntu",
+				Architecture:   "amd64",
+				KernelVersion:  "5.15",
+				KubeletVersion: "v1.28.0",
+			},
+		},
+	}
+	server.K8sClient.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+
+	req, _ := http.NewRequest("GET", "/api/cluster/nodes", nil)
+	rr := httptest.NewRecorder()
+	server.handleClusterNodes(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected 200 OK, got %v", rr.Code)
+	}
+
+	var parsed map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&parsed); err != nil {
+		t.Fatal(err)
+	}
+
+	nodes, ok := parsed["nodes"].([]interface{})
+	if !ok || len(nodes) != 1 {
+		t.Errorf("expected 1 node in response, got %v", parsed)
+	}
+}
+
+func TestHandleClusterNodesCached(t *testing.T) {
+	server := buildMockServerWithK8s()
+	ctx := context.Background()
+
+	nodeCount := func() int {
+		rr := httptest.NewRecorder()
+		server.handleClusterNodes(rr, httptest.NewRequest("GET", "/api/cluster/nodes", nil))
+		var parsed struct {
+			Nodes []map[string]interface{} `json:"nodes"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&parsed); err != nil {
+			t.Fatal(err)
+		}
+		return len(parsed.Nodes)
+	}
+
+	server.K8sClient.CoreV1().Nodes().Create(ctx, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}, metav1.CreateOptions{})
+	if n := nodeCount(); n != 1 {
+		t.Fatalf("expected 1 node, got %d", n)
+	}
+
+	// Served from the cache until the TTL expires
+	server.K8sClient.CoreV1().Nodes().Create(ctx, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}}, metav1.CreateOptions{})
+	if n := nodeCount(); n != 1 {
+		t.Errorf("expected the cached summary with 1 node, got %d", n)
+	}
+
+	for k, cached := range server.nodeSummaries {
+		cached.at = cached.at.Add(-nodeSummaryTTL)
+		server.nodeSummaries[k] = cached
+	}
+	if n := nodeCount(); n != 2 {
+		t.Errorf("expected a fresh summary with 2 nodes, got %d", n)
+	}
+}
+
+func TestHandleClusterNodesLabelSelector(t *testing.T) {
+	server := buildMockServerWithK8s()
+	ctx := context.Background()
+
+	for name, nodeLabels := range map[string]map[string]string{
+		"cp-1":     {"node-role.kubernetes.io/control-plane": ""},
+		"worker-1": {"node-role.kubernetes.io/worker": ""},
+		"worker-2": {"node-role.kubernetes.io/worker": ""},
+	} {
+		server.K8sClient.CoreV1().Nodes().Create(ctx, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: nodeLabels},
+			Status:     corev1.NodeStatus{Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")}},
+		}, metav1.CreateOptions{})
+	}
+
+	var parsed struct {
+		TotalCapacity struct {
+			CPU float64 `json:"cpu"`
+		} `json:"totalCapacity"`
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	}
+	rr := httptest.NewRecorder()
+	server.handleClusterNodes(rr, httptest.NewRequest("GET", "/api/cluster/nodes?labelSelector="+url.QueryEscape("!node-role.kubernetes.io/control-plane"), nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&parsed); err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.Nodes) != 2 || parsed.TotalCapacity.CPU != 8 {
+		t.Errorf("expected the 2 workers totalling 8 CPUs, got %+v", parsed)
+	}
+
+	// Unfiltered requests are cached separately
+	rr = httptest.NewRecorder()
+	server.handleClusterNodes(rr, httptest.NewRequest("GET", "/api/cluster/nodes", nil))
+	if err := json.NewDecoder(rr.Body).Decode(&parsed); err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.Nodes) != 3 || parsed.TotalCapacity.CPU != 12 {
+		t.Errorf("expected all 3 nodes totalling 12 CPUs, got %+v", parsed)
+	}
+
+	rr = httptest.NewRecorder()
+	server.handleClusterNodes(rr, httptest.NewRequest("GET", "/api/cluster/nodes?labelSelector="+url.QueryEscape("role in (worker"), nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a malformed selector, got %d", rr.Code)
+	}
+}
+
+func TestPodRequests(t *testing.T) {
+	container := func(name, cpu, mem string) corev1.Container {
+		return corev1.Container{Name: name, Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(mem)},
+		}}
+	}
+	always := corev1.ContainerRestartPolicyAlways
+	sidecar := container("proxy", "100m", "64Mi")
+	sidecar.RestartPolicy = &always
+
+	tests := []struct {
+		name     string
+		spec     corev1.PodSpec
+		cpu, mem string
+	}{
+		{
+			name: "app containers summed",
+			spec: corev1.PodSpec{Containers: []corev1.Container{container("app", "200m", "128Mi"), container("log", "50m", "32Mi")}},
+			cpu:  "250m", mem: "160Mi",
+		},
+		{
+			name: "init container larger than the app containers",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{container("migrate", "1", "64Mi")},
+				Containers:     []corev1.Container{container("app", "200m", "128Mi"), container("log", "50m", "32Mi")},
+			},
+			cpu: "1", mem: "160Mi",
+		},
+		{
+			name: "sidecar counts towards init and app containers",
+			spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{sidecar, container("migrate", "1", "64Mi")},
+				Containers:     []corev1.Container{container("app", "200m", "128Mi")},
+			},
+			cpu: "1100m", mem: "192Mi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{Spec: tt.spec}
+			cpu, mem := podRequests(pod)
+			if cpu.Cmp(resource.MustParse(tt.cpu)) != 0 || mem.Cmp(resource.MustParse(tt.mem)) != 0 {
+				t.Errorf("podRequests() = %s, %s; want %s, %s", cpu.String(), mem.String(), tt.cpu, tt.mem)
+			}
+		})
+	}
+
+	// Node totals add up the effective request of every running pod
+	pods := []corev1.Pod{
+		{Spec: tests[1].spec, Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+		{Spec: tests[0].spec, Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+		{Spec: tests[0].spec, Status: corev1.PodStatus{Phase: corev1.PodSucceeded}},
+	}
+	for i := range pods {
+		pods[i].Spec.NodeName = "node-1"
+	}
+	req := requestsByNode(pods)["node-1"]
+	if req == nil || req.cpu.Cmp(resource.MustParse("1250m")) != 0 {
+		t.Errorf("expected 1250m requested on node-1, got %+v", req)
+	}
+}
+
+func BenchmarkBuildNodeSummary(b *testing.B) {
+	const nodeCount, podsPerNode = 400, 30
+
+	nodes := make([]corev1.Node, 0, nodeCount)
+	nodeMetrics := make(map[string]corev1.ResourceList, nodeCount)
+	pods := make([]corev1.Pod, 0, nodeCount*podsPerNode)
+	for i := range nodeCount {
+		name := fmt.Sprintf("node-%d", i)
+		nodes = append(nodes, corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("8"), corev1.ResourceMemory: resource.MustParse("32Gi")},
+				Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		})
+		nodeMetrics[name] = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3"), corev1.ResourceMemory: resource.MustParse("12Gi")}
+		for j := range podsPerNode {
+			pods = append(pods, corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pod-%d-%d", i, j)},
+				Spec: corev1.PodSpec{
+					NodeName: name,
+					Containers: []corev1.Container{{
+						Name: "app",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("256Mi")},
+						},
+					}},
+				},
+				Status: corev1.PodStatus{Phase: corev1.PodRunning},
+			})
+		}
+	}
+
+	for b.Loop() {
+		buildNodeSummary(nodes, nodeMetrics, pods)
+	}
+}
+
+func TestHandleNamespaceOptimizeUnknownStrategy(t *testing.T) {
+	server := buildMockServerWithK8s()
+
+	req, _ := http.NewRequest("POST", "/api/namespaces/test-ns/optimize?strategy=p42", nil)
+	rr := httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 Bad Request for unknown strategy, got %v", rr.Code)
+	}
+}
+
+func TestOptimizeDeploymentStatefulSetParity(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServerWithK8s()
+	ctx := context.Background()
+
+	usage := []metricsv1beta1.ContainerMetrics{
+		{Name: "app", Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("400m"), corev1.ResourceMemory: resource.MustParse("512Mi")}},
+		{Name: "sidecar", Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1m"), corev1.ResourceMemory: resource.MustParse("1Mi")}},
+	}
+	metricsClient := metricsfake.NewSimpleClientset()
+	metricsClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &metricsv1beta1.PodMetricsList{Items: []metricsv1beta1.PodMetrics{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-abc-1", Namespace: "test-ns", OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-abc"}}},
+				Containers: usage,
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "test-ns", OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "db"}}},
+				Containers: usage,
+			},
+		}}, nil
+	})
+	server.MetricsClient = metricsClient
+
+	server.Client.Create(ctx, &finopsv1.NamespaceFinOps{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Namespace: "kubex"},
+		Status: finopsv1.NamespaceFinOpsStatus{
+			History: []finopsv1.MetricDataPoint{
+				{Timestamp: metav1.Now(), CPU: finopsv1.ResourceMetrics{Usage: "802m"}, Memory: finopsv1.ResourceMetrics{Usage: "1026Mi"}},
+			},
+		},
+	})
+	server.Client.Create(ctx, &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-abc",
+			Namespace:       "test-ns",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", APIVersion: "apps/v1", UID: "web"}},
+		},
+	})
+	podSpec := func() corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("2Gi")},
+					Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4"), corev1.ResourceMemory: resource.MustParse("4Gi")},
+				},
+			},
+			{
+				Name: "sidecar",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("128Mi")},
+				},
+			},
+		}}}
+	}
+	server.Client.Create(ctx, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-ns"},
+		Spec:       appsv1.DeploymentSpec{Template: podSpec()},
+	})
+	server.Client.Create(ctx, &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "test-ns"},
+		Spec:       appsv1.StatefulSetSpec{Template: podSpec()},
+	})
+
+	rr := httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, httptest.NewRequest("POST", "/api/namespaces/test-ns/optimize", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var status finopsv1.NamespaceOptimizationStatus
+	json.NewDecoder(rr.Body).Decode(&status)
+	if len(status.Workloads) != 2 {
+		t.Fatalf("expected both workloads to be optimized, got %+v", status.Workloads)
+	}
+	deploy, sts := status.Workloads[0], status.Workloads[1]
+	if deploy.Kind != "Deployment" || sts.Kind != "StatefulSet" {
+		t.Fatalf("unexpected workload order: %s, %s", deploy.Kind, sts.Kind)
+	}
+	if deploy.Optimized.CPURequest == deploy.Original.CPURequest {
+		t.Errorf("expected the cpu request to be resized, got %+v", deploy)
+	}
+	deploy.Name, deploy.Kind = "", ""
+	sts.Name, sts.Kind = "", ""
+	if !equality.Semantic.DeepEqual(deploy, sts) {
+		t.Errorf("expected identical sizing for both kinds:\nDeployment:  %+v\nStatefulSet: %+v", deploy, sts)
+	}
+
+	var d appsv1.Deployment
+	var ss appsv1.StatefulSet
+	server.Client.Get(ctx, client.ObjectKey{Name: "web", Namespace: "test-ns"}, &d)
+	server.Client.Get(ctx, client.ObjectKey{Name: "db", Namespace: "test-ns"}, &ss)
+	if !equality.Semantic.DeepEqual(d.Spec.Template.Spec.Containers, ss.Spec.Template.Spec.Containers) {
+		t.Errorf("expected identical containers, got %+v and %+v", d.Spec.Template.Spec.Containers, ss.Spec.Template.Spec.Containers)
+	}
+}
+
+func TestAggregateUsage(t *testing.T) {
+	samples := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 100}
+
+	if avg := aggregateUsage(samples, strategyAverage); avg != 14.5 {
+		t.Errorf("expected average 14.5, got %v", avg)
+	}
+	if p95 := aggregateUsage(samples, strategyP95); p95 != 19 {
+		t.Errorf("expected p95 19, got %v", p95)
+	}
+	if p := percentile([]float64{42}, 95); p != 42 {
+		t.Errorf("expected single sample percentile 42, got %v", p)
+	}
+	if p := percentile(nil, 95); p != 0 {
+		t.Errorf("expected empty percentile 0, got %v", p)
+	}
+}
+
+func TestOptimizeContainers(t *testing.T) {
+	containers := []corev1.Container{
+		{
+			Name: "app",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("2Gi")},
+				Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4"), corev1.ResourceMemory: resource.MustParse("4Gi")},
+			},
+		},
+		{
+			Name: "sidecar",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("128Mi")},
+				Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m"), corev1.ResourceMemory: resource.MustParse("256Mi")},
+			},
+		},
+	}
+	cpuUsage := map[string]float64{"app": 1, "sidecar": 0.001}
+	memUsage := map[string]float64{"app": 1024 * 1024 * 1024, "sidecar": 1024 * 1024}
+
+	result := optimizeContainers(containers, cpuUsage, memUsage, 1, 1, 2, nil, defaultOptimizeOptions)
+	if len(result) != 2 {
+		t.Fatalf("expected 2 container results, got %d", len(result))
+	}
+
+	if got := containers[0].Resources.Requests.Cpu().String(); got != "650m" {
+		t.Errorf("expected app cpu request 650m, got %s", got)
+	}
+	if got := containers[0].Resources.Limits.Memory().String(); got != "768Mi" {
+		t.Errorf("expected app memory limit 768Mi, got %s", got)
+	}
+	// The sidecar uses almost nothing and must be clamped to the safety floor, not sized like the app
+	if got := containers[1].Resources.Requests.Cpu().String(); got != "20m" {
+		t.Errorf("expected sidecar cpu request 20m, got %s", got)
+	}
+	if got := containers[1].Resources.Requests.Memory().String(); got != "64Mi" {
+		t.Errorf("expected sidecar memory request 64Mi, got %s", got)
+	}
+	if result[1].Name != "sidecar" || result[1].Original.CPURequest != "100m" {
+		t.Errorf("expected sidecar original values to be recorded, got %+v", result[1])
+	}
+
+	total := podResourceValues(containers)
+	if total.CPURequest != "670m" {
+		t.Errorf("expected pod cpu request 670m, got %s", total.CPURequest)
+	}
+
+	restoreContainers(&corev1.PodSpec{Containers: containers}, finopsv1.WorkloadOptimization{Containers: result})
+	if got := containers[0].Resources.Requests.Cpu().String(); got != "2" {
+		t.Errorf("expected app cpu request restored to 2, got %s", got)
+	}
+	if got := containers[1].Resources.Limits.Memory().String(); got != "256Mi" {
+		t.Errorf("expected sidecar memory limit restored to 256Mi, got %s", got)
+	}
+}
+
+func TestOptimizeContainersExcluded(t *testing.T) {
+	sidecar := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("128Mi")},
+		Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+	containers := []corev1.Container{
+		{
+			Name: "app",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("2Gi")},
+			},
+		},
+		{Name: "istio-proxy", Resources: *sidecar.DeepCopy()},
+	}
+	deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:        "web",
+		Annotations: map[string]string{ExcludeContainersAnnotation: " istio-proxy , ,other"},
+	}}
+	excluded := excludedContainers(deploy)
+	if len(excluded) != 2 || !excluded["istio-proxy"] || !excluded["other"] {
+		t.Fatalf("unexpected excluded containers %v", excluded)
+	}
+
+	result := optimizeContainers(containers, map[string]float64{"app": 1, "istio-proxy": 0.001}, map[string]float64{"app": 1024 * 1024 * 1024, "istio-proxy": 1024 * 1024}, 1, 1, 1, excluded, defaultOptimizeOptions)
+	if result[0].Skipped || containers[0].Resources.Requests.Cpu().String() == "2" {
+		t.Errorf("expected app to be optimized, got %+v", result[0])
+	}
+	if !result[1].Skipped || result[1].Original != result[1].Optimized || result[1].Original.CPULimit != "2" {
+		t.Errorf("expected istio-proxy to be skipped with its values, got %+v", result[1])
+	}
+	if !equality.Semantic.DeepEqual(containers[1].Resources, sidecar) {
+		t.Errorf("expected istio-proxy resources untouched, got %+v", containers[1].Resources)
+	}
+
+	// Revert must not touch the excluded container either, even if it changed meanwhile
+	containers[1].Resources.Limits[corev1.ResourceCPU] = resource.MustParse("3")
+	restoreContainers(&corev1.PodSpec{Containers: containers}, finopsv1.WorkloadOptimization{Containers: result})
+	if got := containers[0].Resources.Requests.Cpu().String(); got != "2" {
+		t.Errorf("expected app cpu request restored to 2, got %s", got)
+	}
+	if got := containers[1].Resources.Limits.Cpu().String(); got != "3" {
+		t.Errorf("expected istio-proxy cpu limit left at 3, got %s", got)
+	}
+
+	run := newOptimizationRun(map[string]map[string]float64{"Deployment/web": {"istio-proxy": 1}}, map[string]map[string]float64{"Deployment/web": {"istio-proxy": 1}}, 1, 1, defaultOptimizeOptions)
+	spec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "istio-proxy"}}}
+	run.add(deploy, "Deployment", nil, spec)
+	if len(run.optimized) != 0 || len(run.skipped) != 1 || run.skipped[0].Reason != "All containers are excluded from optimization" {
+		t.Errorf("expected a workload with only excluded containers to be skipped, got %+v %+v", run.optimized, run.skipped)
+	}
+}
+
+func TestOptimizationRunInitContainers(t *testing.T) {
+	resources := func(cpu, mem string) corev1.ResourceRequirements {
+		return corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(mem)},
+		}
+	}
+	spec := &corev1.PodSpec{
+		InitContainers: []corev1.Container{
+			{Name: "migrate", Resources: resources("4", "4Gi")},
+			{Name: "assets", Resources: resources("2", "2Gi")},
+		},
+		Containers: []corev1.Container{{Name: "app", Resources: resources("1", "1Gi")}},
+	}
+	if got := quotaUsage(spec, 2)[corev1.ResourceRequestsCPU]; got.String() != "8" {
+		t.Errorf("expected the largest init container to count against the quota, got %s", got.String())
+	}
+
+	// Only migrate is reported by the metrics server, assets completed before sampling
+	usage := map[string]map[string]float64{"Deployment/web": {"app": 0.1, "migrate": 0.2}}
+	memUsage := map[string]map[string]float64{"Deployment/web": {"app": 100 * 1024 * 1024, "migrate": 200 * 1024 * 1024}}
+	run := newOptimizationRun(usage, memUsage, 1, 1, defaultOptimizeOptions)
+	deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web"}}
+	run.add(deploy, "Deployment", nil, spec)
+
+	if len(run.optimized) != 1 {
+		t.Fatalf("expected web to be optimized, got %+v", run.skipped)
+	}
+	containers := run.optimized[0].Containers
+	if len(containers) != 2 || containers[1].Name != "migrate" || !containers[1].Init || containers[0].Init {
+		t.Fatalf("expected app and the migrate init container, got %+v", containers)
+	}
+	if got := spec.InitContainers[0].Resources.Requests.Cpu().String(); got != "260m" {
+		t.Errorf("expected migrate cpu request 260m, got %s", got)
+	}
+	if got := spec.InitContainers[1].Resources.Requests.Cpu().String(); got != "2" {
+		t.Errorf("expected assets without usage to keep its cpu request, got %s", got)
+	}
+	// assets is now the largest init container, the pod goes from 4 to 2 CPUs
+	if got := run.quotaDelta[corev1.ResourceRequestsCPU]; got.String() != "-2" {
+		t.Errorf("expected the quota delta to follow the largest init container, got %s", got.String())
+	}
+
+	restoreContainers(spec, run.optimized[0])
+	if got := spec.InitContainers[0].Resources.Requests.Cpu().String(); got != "4" {
+		t.Errorf("expected migrate cpu request restored to 4, got %s", got)
+	}
+}
+
+func TestOptimizeContainersLimitRange(t *testing.T) {
+	containers := []corev1.Container{{
+		Name: "app",
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+			Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("2Gi")},
+		},
+	}}
+	opts := defaultOptimizeOptions
+	opts.bounds = containerBounds{
+		cpu:    resourceBounds{min: 0.1},
+		memory: resourceBounds{max: 256 * 1024 * 1024, maxRatio: 1.1},
+	}
+
+	// 10m CPU and 512Mi memory of usage: CPU falls under the min, memory over the max
+	result := optimizeContainers(containers, map[string]float64{"app": 0.01}, map[string]float64{"app": 512 * 1024 * 1024}, 1, 1, 1, nil, opts)
+
+	c := containers[0]
+	if got := c.Resources.Requests.Cpu().String(); got != "100m" {
+		t.Errorf("expected cpu request raised to 100m, got %s", got)
+	}
+	if got := c.Resources.Requests.Memory().String(); got != "256Mi" {
+		t.Errorf("expected memory request lowered to 256Mi, got %s", got)
+	}
+	if got := c.Resources.Limits.Memory().String(); got != "256Mi" {
+		t.Errorf("expected memory limit lowered to 256Mi, got %s", got)
+	}
+	if len(result[0].Clamped) != 4 {
+		t.Errorf("expected 4 clamping notes, got %v", result[0].Clamped)
+	}
+
+	// The ratio caps the limit once the request is within bounds
+	opts.bounds.memory.max = 0
+	result = optimizeContainers(containers, map[string]float64{"app": 0.01}, map[string]float64{"app": 100 * 1024 * 1024}, 1, 1, 1, nil, opts)
+	if got := containers[0].Resources.Limits.Memory().String(); got != "143Mi" {
+		t.Errorf("expected memory limit capped at 1.1x the request, got %s", got)
+	}
+	if !slices.ContainsFunc(result[0].Clamped, func(n string) bool { return strings.Contains(n, "maxLimitRequestRatio") }) {
+		t.Errorf("expected a ratio clamping note, got %v", result[0].Clamped)
+	}
+}
+
+func TestHandleNamespaceOptimizeQuotaAndLimitRange(t *testing.T) {
+	os.Setenv("POD_NAMESPACE", "kubex")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	server := buildMockServerWithK8s()
+	server.MetricsClient = webMetricsClient()
+	ctx := context.Background()
+
+	server.Client.Create(ctx, &finopsv1.NamespaceFinOps{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ns", Namespace: "kubex"},
+		Status: finopsv1.NamespaceFinOpsStatus{
+			History: []finopsv1.MetricDataPoint{
+				{Timestamp: metav1.Now(), CPU: finopsv1.ResourceMetrics{Usage: "10m"}, Memory: finopsv1.ResourceMetrics{Usage: "10Mi"}},
+			},
+		},
+	})
+	server.Client.Create(ctx, &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-abc",
+			Namespace:       "test-ns",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", APIVersion: "apps/v1", UID: "web"}},
+		},
+	})
+	server.Client.Create(ctx, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-ns"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name: "app",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("5m")},
+						},
+					}},
+				},
+			},
+		},
+	})
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "test-ns"},
+		Spec:       corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("10m")}},
+		Status:     corev1.ResourceQuotaStatus{Used: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("5m")}},
+	}
+	server.Client.Create(ctx, quota)
+
+	// 10m of usage sizes the request at 13m, 8m more than the quota has left
+	rr := httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, httptest.NewRequest("POST", "/api/namespaces/test-ns/optimize?resources=cpu&cpuFloor=0", nil))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected 409 when the quota is exceeded, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var deploy appsv1.Deployment
+	server.Client.Get(ctx, client.ObjectKey{Name: "web", Namespace: "test-ns"}, &deploy)
+	if got := deploy.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu().String(); got != "5m" {
+		t.Errorf("expected the workload to be left untouched, got cpu request %s", got)
+	}
+
+	server.Client.Delete(ctx, quota)
+	server.Client.Create(ctx, &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "limits", Namespace: "test-ns"},
+		Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+			Type: corev1.LimitTypeContainer,
+			Min:  corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("50m")},
+		}}},
+	})
+
+	rr = httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, httptest.NewRequest("POST", "/api/namespaces/test-ns/optimize?resources=cpu&cpuFloor=0&dryRun=true", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var preview []finopsv1.WorkloadOptimization
+	json.NewDecoder(rr.Body).Decode(&preview)
+	if len(preview) != 1 || preview[0].Optimized.CPURequest != "50m" || len(preview[0].Containers[0].Clamped) == 0 {
+		t.Errorf("expected the cpu request clamped to the LimitRange min, got %+v", preview)
+	}
+}
+
+func TestParseOptimizeOptions(t *testing.T) {
+	opts, err := parseOptimizeOptions(url.Values{})
+	if err != nil || opts != defaultOptimizeOptions {
+		t.Fatalf("expected defaults, got %+v, %v", opts, err)
+	}
+
+	opts, err = parseOptimizeOptions(url.Values{"reqFactor": {"1.1"}, "limitFactor": {"2"}, "cpuFloor": {"50m"}, "memFloor": {"128Mi"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.reqFactor != 1.1 || opts.limitFactor != 2 || opts.cpuFloor.String() != "50m" || opts.memFloor.String() != "128Mi" {
+		t.Errorf("unexpected options %+v", opts)
+	}
+
+	for _, q := range []url.Values{
+		{"reqFactor": {"2"}},
+		{"limitFactor": {"abc"}},
+		{"reqFactor": {"0"}},
+		{"cpuFloor": {"lots"}},
+		{"memFloor": {"-64Mi"}},
+		{"resources": {"gpu"}},
+	} {
+		if _, err := parseOptimizeOptions(q); err == nil {
+			t.Errorf("expected %v to be rejected", q)
+		}
+	}
+
+	containers := []corev1.Container{{Name: "app"}}
+	opts = defaultOptimizeOptions
+	opts.reqFactor, opts.limitFactor, opts.cpuFloor = 2, 3, resource.MustParse("100m")
+	optimizeContainers(containers, map[string]float64{"app": 1}, map[string]float64{"app": 1024 * 1024 * 1024}, 1, 1, 1, nil, opts)
+	if got := containers[0].Resources.Requests.Cpu().String(); got != "2" {
+		t.Errorf("expected cpu request 2, got %s", got)
+	}
+	if got := containers[0].Resources.Limits.Memory().String(); got != "3Gi" {
+		t.Errorf("expected memory limit 3Gi, got %s", got)
+	}
+}
+
+func TestOptimizeContainersFloorQuantities(t *testing.T) {
+	containers := []corev1.Container{{
+		Name: "app",
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+			Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("2Gi")},
+		},
+	}}
+	opts, err := parseOptimizeOptions(url.Values{"cpuFloor": {"29m"}, "memFloor": {"100M"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Idle container, every value falls to the floors, which must not be rounded down
+	optimizeContainers(containers, map[string]float64{"app": 0.001}, map[string]float64{"app": 1024 * 1024}, 1, 1, 1, nil, opts)
+	res := containers[0].Resources
+	for _, v := range []struct{ name, got, expected string }{
+		{"cpu request", res.Requests.Cpu().String(), "29m"},
+		{"cpu limit", res.Limits.Cpu().String(), "44m"},
+		{"memory request", res.Requests.Memory().String(), "100M"},
+		{"memory limit", res.Limits.Memory().String(), "150M"},
+	} {
+		if v.got != v.expected {
+			t.Errorf("expected %s %s, got %s", v.name, v.expected, v.got)
+		}
+	}
+}
+
+func TestWriteJSONError(t *testing.T) {
+	server := buildMockServerWithK8s()
+
+	req, _ := http.NewRequest("GET", "/api/namespaces/missing/history", nil)
+	rr := httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %v", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected JSON content type, got %q", ct)
+	}
+	var body apiError
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("expected a JSON error body: %v", err)
+	}
+	if body.Error != "Not found" || body.Code != http.StatusNotFound {
+		t.Errorf("unexpected error body %+v", body)
+	}
+}
+
+func TestHandleNamespaceRouting(t *testing.T) {
+	server := buildMockServerWithK8s()
+
+	tests := []struct {
+		path     string
+		expected int
+	}{
+		{"/api/namespaces/test-ns/pods", http.StatusOK},
+		{"/api/namespaces/test-ns/pods/", http.StatusOK},
+		{"/api/namespaces/test-ns/workloads/metrics", http.StatusOK},
+		{"/api/namespaces/test-ns/unknown", http.StatusNotFound},
+		{"/api/namespaces/test-ns/pods/extra", http.StatusNotFound},
+		{"/api/namespaces/test-ns", http.StatusBadRequest},
+		{"/api/namespaces//pods", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		rr := httptest.NewRecorder()
+		server.handleNamespaceRouting(rr, req)
+
+		if rr.Code != tt.expected {
+			t.Errorf("%s: expected %d, got %d", tt.path, tt.expected, rr.Code)
+		}
+		if rr.Code >= 400 {
+			var apiErr apiError
+			if err := json.NewDecoder(rr.Body).Decode(&apiErr); err != nil || apiErr.Code != rr.Code {
+				t.Errorf("%s: expected a JSON error body, got %q", tt.path, rr.Body.String())
+			}
+		}
+	}
+}
+
+func TestProbes(t *testing.T) {
+	resetAuthConfig(t, map[string]string{"KUBEX_AUTH_USER": "admin", "KUBEX_AUTH_PASSWORD": "secret"})
+
+	server := buildMockServerWithK8s()
+	synced := false
+	server.Cache = &informertest.FakeInformers{Synced: &synced}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", server.handleHealthz)
+	mux.HandleFunc("/readyz", server.handleReadyz)
+	handler := AuthMiddleware(mux)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/healthz", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected unauthenticated /healthz to return 200, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/readyz", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 before the cache synced, got %d", rr.Code)
+	}
+
+	synced = true
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/readyz", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected 200 once ready, got %d: %s", rr.Code, rr.Body.String())
 	}
 }
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// The session epoch is persisted in a ConfigMap of the operator namespace so that a
+// restart does not bring revoked sessions back to life
+const (
+	sessionConfigMapName = "kubex-sessions"
+	sessionEpochKey      = "epoch"
+)
+
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;create;update
+
+// loadSessionEpoch restores the persisted session epoch. A missing ConfigMap leaves the
+// epoch at zero.
+func (s *Server) loadSessionEpoch(ctx context.Context) error {
+	cm, err := s.K8sClient.CoreV1().ConfigMaps(getOperatorNamespace()).Get(ctx, sessionConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	epoch, err := strconv.ParseInt(cm.Data[sessionEpochKey], 10, 64)
+	if err != nil {
+		return err
+	}
+	sessionEpoch.Store(epoch)
+	return nil
+}
+
+// handleRevokeSessions bumps the session epoch, logging out every user including the caller.
+func (s *Server) handleRevokeSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	operatorNs := getOperatorNamespace()
+	configMaps := s.K8sClient.CoreV1().ConfigMaps(operatorNs)
+
+	cm, err := configMaps.Get(ctx, sessionConfigMapName, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		writeJSONError(w, "Failed to read session state: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	exists := err == nil
+
+	epoch := sessionEpoch.Load()
+	if exists {
+		// Another replica may have revoked sessions since this one loaded the epoch
+		if stored, err := strconv.ParseInt(cm.Data[sessionEpochKey], 10, 64); err == nil && stored > epoch {
+			epoch = stored
+		}
+	} else {
+		cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: sessionConfigMapName, Namespace: operatorNs}}
+	}
+	epoch++
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	cm.Data[sessionEpochKey] = strconv.FormatInt(epoch, 10)
+
+	if exists {
+		cm, err = configMaps.Update(ctx, cm, metav1.UpdateOptions{})
+	} else {
+		cm, err = configMaps.Create(ctx, cm, metav1.CreateOptions{})
+	}
+	if err != nil {
+		writeJSONError(w, "Failed to store session state: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sessionEpoch.Store(epoch)
+	logf.Log.Info("Revoked all sessions", "epoch", epoch)
+	s.audit(r, auditRevokeSessions, operatorNs, sessionConfigMapName, cm)
+
+	HandleLogout(w, r)
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WorkloadScaleRequest is an item of PUT /api/namespaces/{ns}/workloads
+type WorkloadScaleRequest struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Replicas int32  `json:"replicas"`
+}
+
+// WorkloadScaleResult reports the outcome of one item of a bulk scale
+type WorkloadScaleResult struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Replicas int32  `json:"replicas"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+}
+
+// newScalableWorkload returns an empty object of a kind the workload endpoints can scale,
+// nil for any other kind.
+func newScalableWorkload(kind string) client.Object {
+	switch kind {
+	case "Deployment":
+		return &appsv1.Deployment{}
+	case "StatefulSet":
+		return &appsv1.StatefulSet{}
+	}
+	return nil
+}
+
+// scaleWorkload sets the replicas of a Deployment or StatefulSet, retrying when the
+// controller updated it in the meantime, and returns the updated workload.
+func (s *Server) scaleWorkload(ctx context.Context, nsName, kind, name string, replicas int32) (client.Object, error) {
+	obj := newScalableWorkload(kind)
+	if obj == nil {
+		return nil, fmt.Errorf("unknown kind %q", kind)
+	}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := s.Client.Get(ctx, client.ObjectKey{Name: name, Namespace: nsName}, obj); err != nil {
+			return err
+		}
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			o.Spec.Replicas = &replicas
+		case *appsv1.StatefulSet:
+			o.Spec.Replicas = &replicas
+		}
+		return s.Client.Update(ctx, obj)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// serveWorkloadsScale scales several workloads of a namespace in one call. Items are
+// applied in order and independently: a failing item is reported in its result and the
+// remaining ones are still applied.
+func (s *Server) serveWorkloadsScale(w http.ResponseWriter, r *http.Request, nsName string) {
+	var items []WorkloadScaleRequest
+	if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	results := make([]WorkloadScaleResult, 0, len(items))
+	for _, item := range items {
+		result := WorkloadScaleResult{Kind: item.Kind, Name: item.Name, Replicas: item.Replicas}
+		switch {
+		case item.Name == "":
+			result.Error = "name is required"
+		case item.Replicas < 0:
+			result.Error = "replicas must not be negative"
+		default:
+			obj, err := s.scaleWorkload(ctx, nsName, item.Kind, item.Name, item.Replicas)
+			if err != nil {
+				result.Error = err.Error()
+				break
+			}
+			result.Success = true
+			s.audit(r, auditScaleWorkload, nsName, item.Name, obj)
+		}
+		results = append(results, result)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+	"github.com/migalsp/kubex-operator/internal/scaling"
+)
+
+// WorkloadMetrics is the resource usage of all pods of a workload
+type WorkloadMetrics struct {
+	// Key identifies the workload as Kind/Name
+	Key    string                   `json:"key"`
+	Kind   string                   `json:"kind"`
+	Name   string                   `json:"name"`
+	Pods   int                      `json:"pods"`
+	CPU    finopsv1.ResourceMetrics `json:"cpu"`
+	Memory finopsv1.ResourceMetrics `json:"memory"`
+}
+
+// workloadOwner resolves the Deployment or StatefulSet owning a pod, see scaling.WorkloadOwner
+func (s *Server) workloadOwner(ctx context.Context, nsName string, owners []metav1.OwnerReference) (kind, name string) {
+	return scaling.WorkloadOwner(ctx, s.Client, nsName, owners)
+}
+
+type workloadTotals struct {
+	kind, name                     string
+	pods                           int
+	cpuUse, memUse                 resource.Quantity
+	cpuReq, memReq, cpuLim, memLim resource.Quantity
+}
+
+// serveWorkloadMetrics aggregates pod usage, requests and limits per owning workload.
+func (s *Server) serveWorkloadMetrics(w http.ResponseWriter, r *http.Request, nsName string) {
+	ctx := r.Context()
+
+	podUsageCPU := make(map[string]resource.Quantity)
+	podUsageMem := make(map[string]resource.Quantity)
+	if s.MetricsClient != nil {
+		pmList, err := s.MetricsClient.MetricsV1beta1().PodMetricses(nsName).List(ctx, metav1.ListOptions{})
+		if err == nil {
+			for _, pm := range pmList.Items {
+				var cpuUsage, memUsage resource.Quantity
+				for _, c := range pm.Containers {
+					cpuUsage.Add(*c.Usage.Cpu())
+					memUsage.Add(*c.Usage.Memory())
+				}
+				podUsageCPU[pm.Name] = cpuUsage
+				podUsageMem[pm.Name] = memUsage
+			}
+		}
+	}
+
+	var podList corev1.PodList
+	if err := s.Client.List(ctx, &podList, client.InNamespace(nsName)); err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	totals := make(map[string]*workloadTotals)
+	for _, p := range podList.Items {
+		kind, name := s.workloadOwner(ctx, nsName, p.OwnerReferences)
+		if name == "" {
+			continue
+		}
+
+		key := kind + "/" + name
+		t := totals[key]
+		if t == nil {
+			t = &workloadTotals{kind: kind, name: name}
+			totals[key] = t
+		}
+		t.pods++
+		for _, c := range p.Spec.Containers {
+			t.cpuReq.Add(*c.Resources.Requests.Cpu())
+			t.memReq.Add(*c.Resources.Requests.Memory())
+			t.cpuLim.Add(*c.Resources.Limits.Cpu())
+			t.memLim.Add(*c.Resources.Limits.Memory())
+		}
+		if u, ok := podUsageCPU[p.Name]; ok {
+			t.cpuUse.Add(u)
+		}
+		if u, ok := podUsageMem[p.Name]; ok {
+			t.memUse.Add(u)
+		}
+	}
+
+	result := make([]WorkloadMetrics, 0, len(totals))
+	for key, t := range totals {
+		result = append(result, WorkloadMetrics{
+			Key:  key,
+			Kind: t.kind,
+			Name: t.name,
+			Pods: t.pods,
+			CPU: finopsv1.ResourceMetrics{
+				Usage:    t.cpuUse.String(),
+				Requests: t.cpuReq.String(),
+				Limits:   t.cpuLim.String(),
+			},
+			Memory: finopsv1.ResourceMetrics{
+				Usage:    t.memUse.String(),
+				Requests: t.memReq.String(),
+				Limits:   t.memLim.String(),
+			},
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestServeWorkloadMetrics(t *testing.T) {
+	server := buildMockServerWithK8s()
+	server.MetricsClient = webMetricsClient()
+	ctx := context.Background()
+
+	server.Client.Create(ctx, &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-abc",
+			Namespace:       "test-ns",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "web", APIVersion: "apps/v1", UID: "web"}},
+		},
+	})
+	podSpec := corev1.PodSpec{Containers: []corev1.Container{{
+		Name: "app",
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("64Mi")},
+			Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")},
+		},
+	}}}
+	for _, name := range []string{"web-abc-1", "web-abc-2"} {
+		server.Client.Create(ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "test-ns",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-abc", APIVersion: "apps/v1", UID: "web-abc"}},
+			},
+			Spec: podSpec,
+		})
+	}
+	server.Client.Create(ctx, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "db-0",
+			Namespace:       "test-ns",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "db", APIVersion: "apps/v1", UID: "db"}},
+		},
+		Spec: podSpec,
+	})
+	server.Client.Create(ctx, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "standalone", Namespace: "test-ns"},
+		Spec:       podSpec,
+	})
+
+	req := httptest.NewRequest("GET", "/api/namespaces/test-ns/workloads/metrics", nil)
+	rr := httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var result []WorkloadMetrics
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("expected 2 workloads, got %+v", result)
+	}
+
+	web := result[0]
+	if web.Key != "Deployment/web" || web.Pods != 2 {
+		t.Errorf("expected Deployment/web with 2 pods, got %+v", web)
+	}
+	if web.CPU.Requests != "200m" || web.CPU.Limits != "400m" || web.Memory.Requests != "128Mi" {
+		t.Errorf("expected requests and limits summed over pods, got cpu %+v memory %+v", web.CPU, web.Memory)
+	}
+	if web.CPU.Usage != "10m" {
+		t.Errorf("expected usage from pod metrics, got %s", web.CPU.Usage)
+	}
+
+	if db := result[1]; db.Key != "StatefulSet/db" || db.Pods != 1 || db.CPU.Usage != "0" {
+		t.Errorf("expected StatefulSet/db with 1 pod and no usage, got %+v", db)
+	}
+}
+
+func TestServeWorkloadsScale(t *testing.T) {
+	server := buildMockServerWithK8s()
+	ctx := context.Background()
+
+	replicas := int32(3)
+	server.Client.Create(ctx, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-ns"}, Spec: appsv1.DeploymentSpec{Replicas: &replicas}})
+	server.Client.Create(ctx, &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "test-ns"}, Spec: appsv1.StatefulSetSpec{Replicas: &replicas}})
+
+	body := `[
+		{"kind": "Deployment", "name": "web", "replicas": 0},
+		{"kind": "Deployment", "name": "missing", "replicas": 0},
+		{"kind": "CronJob", "name": "nightly", "replicas": 0},
+		{"kind": "StatefulSet", "name": "db", "replicas": -1},
+		{"kind": "StatefulSet", "name": "db", "replicas": 1}
+	]`
+	rr := httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, httptest.NewRequest("PUT", "/api/namespaces/test-ns/workloads", strings.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var results []WorkloadScaleResult
+	if err := json.NewDecoder(rr.Body).Decode(&results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("expected a result per item, got %+v", results)
+	}
+	for i, want := range []bool{true, false, false, false, true} {
+		if results[i].Success != want || (results[i].Error == "") != want {
+			t.Errorf("item %d: expected success %v, got %+v", i, want, results[i])
+		}
+	}
+
+	var deploy appsv1.Deployment
+	server.Client.Get(ctx, client.ObjectKey{Name: "web", Namespace: "test-ns"}, &deploy)
+	if *deploy.Spec.Replicas != 0 {
+		t.Errorf("expected web scaled to 0, got %d", *deploy.Spec.Replicas)
+	}
+	var ss appsv1.StatefulSet
+	server.Client.Get(ctx, client.ObjectKey{Name: "db", Namespace: "test-ns"}, &ss)
+	if *ss.Spec.Replicas != 1 {
+		t.Errorf("expected the failed items not to stop db from being scaled to 1, got %d", *ss.Spec.Replicas)
+	}
+
+	rr = httptest.NewRecorder()
+	server.handleNamespaceRouting(rr, httptest.NewRequest("PUT", "/api/namespaces/test-ns/workloads", strings.NewReader(`{"kind": "Deployment"}`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a body that is not an array, got %d", rr.Code)
+	}
+}
@@ -3,6 +3,8 @@ package controller
 import (
 	"context"
 	"os"
+	"path"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -16,12 +18,48 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+	"github.com/migalsp/kubex-operator/internal/operatorns"
+	"github.com/migalsp/kubex-operator/internal/reconcilestats"
+	"github.com/migalsp/kubex-operator/internal/watchns"
 )
 
+// IgnoreNamespaceLabel excludes a namespace from auto-discovery when set to "true"
+const IgnoreNamespaceLabel = "finops.kubex.io/ignore"
+
 // NamespaceDiscoveryReconciler watches namespaces and creates NamespaceFinOps CRs
 type NamespaceDiscoveryReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// IgnorePatterns are glob patterns (e.g. "kube-*") of namespaces that are never tracked
+	IgnorePatterns []string
+	// Watched restricts discovery to some namespaces, every namespace when empty
+	Watched watchns.Namespaces
+	// Stats records the reconciles of the controller, may be nil
+	Stats *reconcilestats.Recorder
+}
+
+// DiscoveryIgnoreFromEnv reads the comma separated glob patterns of KUBEX_DISCOVERY_IGNORE.
+func DiscoveryIgnoreFromEnv() []string {
+	var patterns []string
+	for _, p := range strings.Split(os.Getenv("KUBEX_DISCOVERY_IGNORE"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// isIgnored reports whether a namespace is excluded by its label or an ignore pattern.
+func (r *NamespaceDiscoveryReconciler) isIgnored(ns *corev1.Namespace) bool {
+	if ns.Labels[IgnoreNamespaceLabel] == "true" {
+		return true
+	}
+	for _, pattern := range r.IgnorePatterns {
+		if ok, _ := path.Match(pattern, ns.Name); ok {
+			return true
+		}
+	}
+	return false
 }
 
 // +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
@@ -31,14 +69,38 @@ type NamespaceDiscoveryReconciler struct {
 func (r *NamespaceDiscoveryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	l := log.FromContext(ctx)
 
+	if !r.Watched.Allows(req.Name) {
+		return ctrl.Result{}, nil
+	}
+
+	// NamespaceFinOps CRs live in the operator namespace
+	operatorNs := operatorns.Namespace()
+
 	// Fetch the Namespace
 	var ns corev1.Namespace
 	if err := r.Get(ctx, req.NamespacedName, &ns); err != nil {
-		if apierrors.IsNotFound(err) {
-			return ctrl.Result{}, nil
+		// With namespaced RBAC, the Role granting access goes away with the namespace
+		if apierrors.IsNotFound(err) || r.Watched.Restricted() && apierrors.IsForbidden(err) {
+			// The namespace is gone, drop the metrics tracking it
+			return ctrl.Result{}, r.deleteNamespaceFinOps(ctx, operatorNs, req.Name)
 		}
 		return ctrl.Result{}, err
 	}
+	if !ns.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.deleteNamespaceFinOps(ctx, operatorNs, ns.Name)
+	}
+
+	finOpsName := ns.Name // Use namespace name as CR name
+
+	if r.isIgnored(&ns) {
+		// Garbage-collect the CR of a namespace that was tracked before being ignored
+		existing := &finopsv1.NamespaceFinOps{}
+		if err := r.Get(ctx, client.ObjectKey{Name: finOpsName, Namespace: operatorNs}, existing); err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		l.Info("Removing NamespaceFinOps of ignored namespace", "name", ns.Name)
+		return ctrl.Result{}, client.IgnoreNotFound(r.Delete(ctx, existing))
+	}
 
 	if ns.Name != "default" {
 		// Skip system namespaces if needed, but User wanted them if they have resources.
@@ -53,13 +115,7 @@ func (r *NamespaceDiscoveryReconciler) Reconcile(ctx context.Context, req ctrl.R
 		}
 	}
 
-	// It has pods! Check if NamespaceFinOps already exists for it in the operator namespace.
-	operatorNs := os.Getenv("POD_NAMESPACE")
-	if operatorNs == "" {
-		operatorNs = "kubex"
-	}
-
-	finOpsName := ns.Name // Use namespace name as CR name
+	// It has pods! Check if NamespaceFinOps already exists for it.
 	var existing finopsv1.NamespaceFinOps
 	err := r.Get(ctx, client.ObjectKey{Name: finOpsName, Namespace: operatorNs}, &existing)
 	if err == nil {
@@ -90,9 +146,35 @@ func (r *NamespaceDiscoveryReconciler) Reconcile(ctx context.Context, req ctrl.R
 	return ctrl.Result{}, nil
 }
 
+// deleteNamespaceFinOps removes every NamespaceFinOps of the operator namespace tracking nsName.
+func (r *NamespaceDiscoveryReconciler) deleteNamespaceFinOps(ctx context.Context, operatorNs, nsName string) error {
+	var list finopsv1.NamespaceFinOpsList
+	if err := r.List(ctx, &list, client.InNamespace(operatorNs)); err != nil {
+		return err
+	}
+	for i := range list.Items {
+		item := &list.Items[i]
+		if item.Name != nsName && item.Spec.TargetNamespace != nsName {
+			continue
+		}
+		log.FromContext(ctx).Info("Removing NamespaceFinOps of deleted namespace", "name", nsName)
+		if err := r.Delete(ctx, item); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *NamespaceDiscoveryReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&corev1.Namespace{}).
+	b := ctrl.NewControllerManagedBy(mgr)
+	if r.Watched.Restricted() {
+		// Namespaces can't be watched with namespaced RBAC, the pods of the watched
+		// namespaces drive discovery alone and the namespace of a deleted one goes with them
+		b = b.Named("namespacediscovery")
+	} else {
+		b = b.For(&corev1.Namespace{})
+	}
+	return b.
 		Watches(
 			&corev1.Pod{},
 			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
@@ -101,5 +183,5 @@ func (r *NamespaceDiscoveryReconciler) SetupWithManager(mgr ctrl.Manager) error
 				}
 			}),
 		).
-		Complete(r)
+		Complete(r.Stats.Wrap("namespacediscovery", r))
 }
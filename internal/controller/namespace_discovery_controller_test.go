@@ -0,0 +1,115 @@
+/*
+Copyright 2026 migalsp.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+	"github.com/migalsp/kubex-operator/internal/watchns"
+)
+
+var _ = Describe("NamespaceDiscovery ignore rules", func() {
+	r := &NamespaceDiscoveryReconciler{IgnorePatterns: []string{"kube-*", "cert-manager"}}
+
+	namespace := func(name string, labels map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+
+	It("should ignore namespaces matching a pattern", func() {
+		Expect(r.isIgnored(namespace("kube-system", nil))).To(BeTrue())
+		Expect(r.isIgnored(namespace("cert-manager", nil))).To(BeTrue())
+		Expect(r.isIgnored(namespace("backend", nil))).To(BeFalse())
+	})
+
+	It("should ignore namespaces with the ignore label", func() {
+		Expect(r.isIgnored(namespace("backend", map[string]string{IgnoreNamespaceLabel: "true"}))).To(BeTrue())
+		Expect(r.isIgnored(namespace("backend", map[string]string{IgnoreNamespaceLabel: "false"}))).To(BeFalse())
+	})
+
+	It("should read patterns from the environment", func() {
+		os.Setenv("KUBEX_DISCOVERY_IGNORE", "kube-*, ,monitoring")
+		defer os.Unsetenv("KUBEX_DISCOVERY_IGNORE")
+		Expect(DiscoveryIgnoreFromEnv()).To(Equal([]string{"kube-*", "monitoring"}))
+	})
+})
+
+var _ = Describe("NamespaceDiscovery cleanup", func() {
+	It("should delete the NamespaceFinOps of a deleted namespace", func() {
+		ctx := context.Background()
+		os.Setenv("POD_NAMESPACE", "kubex")
+		defer os.Unsetenv("POD_NAMESPACE")
+
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			&finopsv1.NamespaceFinOps{
+				ObjectMeta: metav1.ObjectMeta{Name: "removed", Namespace: "kubex"},
+				Spec:       finopsv1.NamespaceFinOpsSpec{TargetNamespace: "removed"},
+			},
+			&finopsv1.NamespaceFinOps{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "kubex"},
+				Spec:       finopsv1.NamespaceFinOpsSpec{TargetNamespace: "other"},
+			},
+		).Build()
+		r := &NamespaceDiscoveryReconciler{Client: fakeClient}
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "removed"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = fakeClient.Get(ctx, client.ObjectKey{Name: "removed", Namespace: "kubex"}, &finopsv1.NamespaceFinOps{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "other", Namespace: "kubex"}, &finopsv1.NamespaceFinOps{})).To(Succeed())
+	})
+})
+
+var _ = Describe("NamespaceDiscovery watched namespaces", func() {
+	It("should only track the watched namespaces", func() {
+		ctx := context.Background()
+		os.Setenv("POD_NAMESPACE", "kubex")
+		defer os.Unsetenv("POD_NAMESPACE")
+
+		objects := []client.Object{}
+		for _, name := range []string{"team-a", "team-b"} {
+			objects = append(objects,
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}},
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: name}},
+			)
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build()
+		r := &NamespaceDiscoveryReconciler{Client: fakeClient, Watched: watchns.Namespaces{"team-a"}}
+
+		for _, name := range []string{"team-a", "team-b"} {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: name}})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(fakeClient.Get(ctx, client.ObjectKey{Name: "team-a", Namespace: "kubex"}, &finopsv1.NamespaceFinOps{})).To(Succeed())
+		err := fakeClient.Get(ctx, client.ObjectKey{Name: "team-b", Namespace: "kubex"}, &finopsv1.NamespaceFinOps{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
@@ -18,6 +18,11 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -25,19 +30,69 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
 	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+	"github.com/migalsp/kubex-operator/internal/podqos"
+	"github.com/migalsp/kubex-operator/internal/reconcilestats"
+	"github.com/migalsp/kubex-operator/internal/scaling"
 )
 
+// defaultHistoryRetentionMinutes is used when the spec leaves the retention unset
+const defaultHistoryRetentionMinutes = 60
+
+// metricsStaleAfter is how long metrics may be unavailable before the status is flagged as stale
+const metricsStaleAfter = 5 * time.Minute
+
+// insightMetricsUnavailable tells users that the displayed data is outdated
+const insightMetricsUnavailable = "Metrics Unavailable"
+
+// Usage above these shares of the limits flags a workload as underprovisioned: CPU beyond
+// its limit is throttled and memory beyond it is OOM-killed
+const (
+	cpuThrottleRatio = 0.9
+	memPressureRatio = 0.85
+
+	insightCPUThrottled   = "CPU Throttled Risk"
+	insightMemoryPressure = "Memory Pressure"
+)
+
+// Namespaces with at least this share of BestEffort pods (no requests nor limits at all) are
+// flagged: those pods are the first evicted under node pressure and the scheduler can't place
+// them by size. Distinct from "Missing Requests", raised by a single container lacking one.
+const (
+	bestEffortRatio = 0.25
+
+	insightBestEffort = "BestEffort Pods"
+)
+
+// metricsRetryBackoff retries a failing metrics API call within a single reconcile
+var metricsRetryBackoff = wait.Backoff{
+	Steps:    3,
+	Duration: 500 * time.Millisecond,
+	Factor:   2.0,
+	Jitter:   0.1,
+}
+
 // NamespaceFinOpsReconciler reconciles a NamespaceFinOps object
 type NamespaceFinOpsReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
 	MetricsClient metricsv.Interface
+	Pricing       PricingModel
+	// Recorder receives an event whenever an insight appears or goes away, may be nil
+	Recorder record.EventRecorder
+	// Stats records the reconciles of the controller, may be nil
+	Stats *reconcilestats.Recorder
+	// Requeue sets how often each namespace is sampled
+	Requeue RequeueIntervals
 }
 
 // +kubebuilder:rbac:groups=finops.kubex.io,resources=namespacefinops,verbs=get;list;watch;create;update;patch;delete
@@ -58,127 +113,279 @@ func (r *NamespaceFinOpsReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	}
 
 	targetNs := nsFinOps.Spec.TargetNamespace
+	previousInsights := slices.Clone(nsFinOps.Status.Insights)
 
 	// 1. Get current usage from metrics API
-	podMetricsList, err := r.MetricsClient.MetricsV1beta1().PodMetricses(targetNs).List(ctx, metav1.ListOptions{})
+	podMetricsList, err := r.fetchPodMetrics(ctx, targetNs)
 	if err != nil {
 		log.Error(err, "unable to fetch pod metrics", "namespace", targetNs)
-		return ctrl.Result{RequeueAfter: time.Minute}, nil // Soft fail
+		if err := r.recordMetricsError(ctx, &nsFinOps, err); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.recordInsightChanges(&nsFinOps, previousInsights)
+		return ctrl.Result{RequeueAfter: r.Requeue.base()}, nil // Soft fail
 	}
+	nsFinOps.Status.LastMetricsError = ""
+	nsFinOps.Status.MetricsStale = false
 
-	var totalCpuUsage resource.Quantity
-	var totalMemUsage resource.Quantity
+	var totals usageTotals
+	podUsage := make(map[string]corev1.ResourceList, len(podMetricsList.Items))
 	for _, pm := range podMetricsList.Items {
+		var cpu, mem resource.Quantity
 		for _, c := range pm.Containers {
-			totalCpuUsage.Add(*c.Usage.Cpu())
-			totalMemUsage.Add(*c.Usage.Memory())
+			cpu.Add(*c.Usage.Cpu())
+			mem.Add(*c.Usage.Memory())
 		}
+		totals.cpuUsage.Add(cpu)
+		totals.memUsage.Add(mem)
+		podUsage[pm.Name] = corev1.ResourceList{corev1.ResourceCPU: cpu, corev1.ResourceMemory: mem}
 	}
 
 	// 2. Get current limits and requests from regular pods
 	var podList corev1.PodList
 	if err := r.List(ctx, &podList, client.InNamespace(targetNs)); err != nil {
 		log.Error(err, "unable to list pods", "namespace", targetNs)
-		return ctrl.Result{RequeueAfter: time.Minute}, nil
+		return ctrl.Result{RequeueAfter: r.Requeue.base()}, nil
 	}
 
-	var totalCpuReq, totalMemReq resource.Quantity
-	var totalCpuLim, totalMemLim resource.Quantity
-
-	missingRequests := false
-	missingLimits := false
-
+	workloads := make(map[string]*usageTotals)
+	owners := make(map[string][2]string) // controller owner Kind/Name -> workload kind, name
 	for _, p := range podList.Items {
 		if p.Status.Phase != corev1.PodRunning {
 			continue // Only count running pods
 		}
-		for _, c := range p.Spec.Containers {
-			cpuR := c.Resources.Requests.Cpu()
-			memR := c.Resources.Requests.Memory()
-			cpuL := c.Resources.Limits.Cpu()
-			memL := c.Resources.Limits.Memory()
-
-			totalCpuReq.Add(*cpuR)
-			totalMemReq.Add(*memR)
-			totalCpuLim.Add(*cpuL)
-			totalMemLim.Add(*memL)
+		totals.addPod(&p)
 
-			if cpuR.IsZero() || memR.IsZero() {
-				missingRequests = true
-			}
-			if cpuL.IsZero() || memL.IsZero() {
-				missingLimits = true
-			}
+		// Attribute the pod to its workload, resolving each ReplicaSet once
+		ownerKey := ""
+		if ref := metav1.GetControllerOf(&p); ref != nil {
+			ownerKey = ref.Kind + "/" + ref.Name
+		}
+		owner, ok := owners[ownerKey]
+		if !ok {
+			owner[0], owner[1] = scaling.WorkloadOwner(ctx, r.Client, targetNs, p.OwnerReferences)
+			owners[ownerKey] = owner
+		}
+		if owner[1] == "" {
+			continue
+		}
+		key := owner[0] + "/" + owner[1]
+		if workloads[key] == nil {
+			workloads[key] = &usageTotals{}
+		}
+		workloads[key].addContainers(p.Spec.Containers)
+		if usage, ok := podUsage[p.Name]; ok {
+			workloads[key].cpuUsage.Add(*usage.Cpu())
+			workloads[key].memUsage.Add(*usage.Memory())
 		}
 	}
 
 	// 2.5 Calculate Insights
-	var insights []string
-	if missingRequests {
-		insights = append(insights, "Missing Requests")
-	}
-	if missingLimits {
-		insights = append(insights, "Uncapped")
-	}
-
-	// Overprovisioning check (Usage < 30% of Requests)
-	if !totalCpuReq.IsZero() && totalCpuUsage.AsApproximateFloat64() < totalCpuReq.AsApproximateFloat64()*0.3 {
-		insights = append(insights, "Overprovisioned CPU")
-	}
-	if !totalMemReq.IsZero() && totalMemUsage.AsApproximateFloat64() < totalMemReq.AsApproximateFloat64()*0.3 {
-		insights = append(insights, "Overprovisioned RAM")
-	}
-
+	insights := totals.insights()
 	if len(insights) == 0 && len(podList.Items) > 0 {
 		insights = append(insights, "Optimized")
 	}
+	workloadInsights := []finopsv1.WorkloadInsight{}
+	for key, t := range workloads {
+		if wi := t.insights(); len(wi) > 0 {
+			kind, name, _ := strings.Cut(key, "/")
+			workloadInsights = append(workloadInsights, finopsv1.WorkloadInsight{Kind: kind, Name: name, Insights: wi})
+		}
+	}
+	sort.Slice(workloadInsights, func(i, j int) bool {
+		if workloadInsights[i].Kind != workloadInsights[j].Kind {
+			return workloadInsights[i].Kind < workloadInsights[j].Kind
+		}
+		return workloadInsights[i].Name < workloadInsights[j].Name
+	})
+
+	waste := r.Pricing.MonthlyWaste(totals.cpuReq, totals.cpuUsage, totals.memReq, totals.memUsage)
+	estimatedWaste := fmt.Sprintf("%.2f", waste)
 
 	// 3. Create the data point
 	now := metav1.Now()
 	dp := finopsv1.MetricDataPoint{
 		Timestamp: now,
 		CPU: finopsv1.ResourceMetrics{
-			Usage:    totalCpuUsage.String(),
-			Requests: totalCpuReq.String(),
-			Limits:   totalCpuLim.String(),
+			Usage:    totals.cpuUsage.String(),
+			Requests: totals.cpuReq.String(),
+			Limits:   totals.cpuLim.String(),
 		},
 		Memory: finopsv1.ResourceMetrics{
-			Usage:    totalMemUsage.String(),
-			Requests: totalMemReq.String(),
-			Limits:   totalMemLim.String(),
+			Usage:    totals.memUsage.String(),
+			Requests: totals.memReq.String(),
+			Limits:   totals.memLim.String(),
 		},
 	}
 
-	// 4. Update the history only if at least 1 minute has passed
+	// 4. Update the history only once per base interval, with some slack for early requeues
 	lastPointTime := nsFinOps.Status.LastUpdated.Time
-	if !lastPointTime.IsZero() && time.Since(lastPointTime) < 55*time.Second {
+	if !lastPointTime.IsZero() && time.Since(lastPointTime) < r.Requeue.base()*11/12 {
 		// Just update the insights and current state, but don't add a new history point yet
 		nsFinOps.Status.Insights = insights
+		nsFinOps.Status.WorkloadInsights = workloadInsights
+		nsFinOps.Status.EstimatedMonthlyWaste = estimatedWaste
 		if err := r.Status().Update(ctx, &nsFinOps); err != nil {
 			return ctrl.Result{}, err
 		}
-		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+		r.recordInsightChanges(&nsFinOps, previousInsights)
+		return ctrl.Result{RequeueAfter: r.Requeue.base() / 2}, nil
 	}
 
 	nsFinOps.Status.History = append(nsFinOps.Status.History, dp)
-	if len(nsFinOps.Status.History) > 60 {
-		nsFinOps.Status.History = nsFinOps.Status.History[len(nsFinOps.Status.History)-60:]
+	retention := int(nsFinOps.Spec.HistoryRetentionMinutes)
+	if retention <= 0 {
+		retention = defaultHistoryRetentionMinutes
+	}
+	if len(nsFinOps.Status.History) > retention {
+		nsFinOps.Status.History = nsFinOps.Status.History[len(nsFinOps.Status.History)-retention:]
 	}
 	nsFinOps.Status.LastUpdated = now
 	nsFinOps.Status.Insights = insights
+	nsFinOps.Status.WorkloadInsights = workloadInsights
+	nsFinOps.Status.EstimatedMonthlyWaste = estimatedWaste
 
 	if err := r.Status().Update(ctx, &nsFinOps); err != nil {
 		log.Error(err, "unable to update status")
 		return ctrl.Result{}, err
 	}
+	r.recordInsightChanges(&nsFinOps, previousInsights)
+
+	return ctrl.Result{RequeueAfter: r.Requeue.base()}, nil
+}
 
-	return ctrl.Result{RequeueAfter: time.Minute}, nil
+// usageTotals are the usage, requests and limits of a set of running pods
+type usageTotals struct {
+	cpuUsage, memUsage             resource.Quantity
+	cpuReq, memReq, cpuLim, memLim resource.Quantity
+	missingRequests, missingLimits bool
+	pods, bestEffortPods           int
+}
+
+func (t *usageTotals) addPod(p *corev1.Pod) {
+	t.addContainers(p.Spec.Containers)
+	t.pods++
+	if podqos.Class(p) == corev1.PodQOSBestEffort {
+		t.bestEffortPods++
+	}
+}
+
+func (t *usageTotals) addContainers(containers []corev1.Container) {
+	for _, c := range containers {
+		cpuR := c.Resources.Requests.Cpu()
+		memR := c.Resources.Requests.Memory()
+		cpuL := c.Resources.Limits.Cpu()
+		memL := c.Resources.Limits.Memory()
+
+		t.cpuReq.Add(*cpuR)
+		t.memReq.Add(*memR)
+		t.cpuLim.Add(*cpuL)
+		t.memLim.Add(*memL)
+
+		if cpuR.IsZero() || memR.IsZero() {
+			t.missingRequests = true
+		}
+		if cpuL.IsZero() || memL.IsZero() {
+			t.missingLimits = true
+		}
+	}
+}
+
+// insights returns the labels describing the totals, empty when nothing stands out
+func (t *usageTotals) insights() []string {
+	var insights []string
+	if t.missingRequests {
+		insights = append(insights, "Missing Requests")
+	}
+	if t.missingLimits {
+		insights = append(insights, "Uncapped")
+	}
+	if t.pods > 0 && float64(t.bestEffortPods) >= float64(t.pods)*bestEffortRatio {
+		insights = append(insights, insightBestEffort)
+	}
+
+	// Overprovisioning check (Usage < 30% of Requests)
+	if !t.cpuReq.IsZero() && t.cpuUsage.AsApproximateFloat64() < t.cpuReq.AsApproximateFloat64()*0.3 {
+		insights = append(insights, "Overprovisioned CPU")
+	}
+	if !t.memReq.IsZero() && t.memUsage.AsApproximateFloat64() < t.memReq.AsApproximateFloat64()*0.3 {
+		insights = append(insights, "Overprovisioned RAM")
+	}
+
+	// Underprovisioning checks, only meaningful when every container is capped since the
+	// usage of uncapped containers would count against the limits of the others
+	if !t.missingLimits {
+		if !t.cpuLim.IsZero() && t.cpuUsage.AsApproximateFloat64() > t.cpuLim.AsApproximateFloat64()*cpuThrottleRatio {
+			insights = append(insights, insightCPUThrottled)
+		}
+		if !t.memLim.IsZero() && t.memUsage.AsApproximateFloat64() > t.memLim.AsApproximateFloat64()*memPressureRatio {
+			insights = append(insights, insightMemoryPressure)
+		}
+	}
+	return insights
+}
+
+// recordInsightChanges emits an InsightAdded event for every insight missing from previous
+// and an InsightResolved event for every one that is gone. Call it once the status is
+// stored, so a failed update does not announce a change twice.
+func (r *NamespaceFinOpsReconciler) recordInsightChanges(nsFinOps *finopsv1.NamespaceFinOps, previous []string) {
+	if r.Recorder == nil {
+		return
+	}
+	for _, insight := range nsFinOps.Status.Insights {
+		if !slices.Contains(previous, insight) {
+			r.Recorder.Eventf(nsFinOps, corev1.EventTypeNormal, "InsightAdded", "Namespace %s: %s", nsFinOps.Spec.TargetNamespace, insight)
+		}
+	}
+	for _, insight := range previous {
+		if !slices.Contains(nsFinOps.Status.Insights, insight) {
+			r.Recorder.Eventf(nsFinOps, corev1.EventTypeNormal, "InsightResolved", "Namespace %s: %s", nsFinOps.Spec.TargetNamespace, insight)
+		}
+	}
+}
+
+// errMetricsUnavailable is recorded when the reconciler runs without a metrics client
+var errMetricsUnavailable = errors.New("metrics server not available")
+
+// fetchPodMetrics lists the pod metrics of a namespace, retrying transient failures.
+func (r *NamespaceFinOpsReconciler) fetchPodMetrics(ctx context.Context, ns string) (*metricsv1beta1.PodMetricsList, error) {
+	if r.MetricsClient == nil {
+		return nil, errMetricsUnavailable
+	}
+	var list *metricsv1beta1.PodMetricsList
+	err := retry.OnError(metricsRetryBackoff, func(error) bool { return ctx.Err() == nil }, func() error {
+		var err error
+		list, err = r.MetricsClient.MetricsV1beta1().PodMetricses(ns).List(ctx, metav1.ListOptions{})
+		return err
+	})
+	return list, err
+}
+
+// recordMetricsError keeps the last known data but records the failure, and flags the
+// status as stale once metrics have been missing for longer than metricsStaleAfter.
+func (r *NamespaceFinOpsReconciler) recordMetricsError(ctx context.Context, nsFinOps *finopsv1.NamespaceFinOps, metricsErr error) error {
+	lastSuccess := nsFinOps.Status.LastUpdated.Time
+	stale := lastSuccess.IsZero() || time.Since(lastSuccess) > metricsStaleAfter
+
+	if nsFinOps.Status.LastMetricsError == metricsErr.Error() && nsFinOps.Status.MetricsStale == stale {
+		return nil
+	}
+
+	nsFinOps.Status.LastMetricsError = metricsErr.Error()
+	nsFinOps.Status.MetricsStale = stale
+	if stale && !slices.Contains(nsFinOps.Status.Insights, insightMetricsUnavailable) {
+		nsFinOps.Status.Insights = append(nsFinOps.Status.Insights, insightMetricsUnavailable)
+	}
+	return r.Status().Update(ctx, nsFinOps)
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *NamespaceFinOpsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("namespacefinops-controller")
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&finopsv1.NamespaceFinOps{}).
 		Named("namespacefinops").
-		Complete(r)
+		Complete(r.Stats.Wrap("namespacefinops", r))
 }
@@ -0,0 +1,167 @@
+/*
+Copyright 2026 migalsp.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+)
+
+var _ = Describe("NamespaceFinOps insight events", func() {
+	It("should only emit events for insights that changed", func() {
+		recorder := record.NewFakeRecorder(10)
+		r := &NamespaceFinOpsReconciler{Recorder: recorder}
+		nsFinOps := &finopsv1.NamespaceFinOps{
+			ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "kubex"},
+			Spec:       finopsv1.NamespaceFinOpsSpec{TargetNamespace: "shop"},
+			Status:     finopsv1.NamespaceFinOpsStatus{Insights: []string{"Uncapped", "Overprovisioned CPU"}},
+		}
+
+		r.recordInsightChanges(nsFinOps, []string{"Uncapped", "Optimized"})
+		Expect(recorder.Events).To(HaveLen(2))
+		Expect(<-recorder.Events).To(Equal("Normal InsightAdded Namespace shop: Overprovisioned CPU"))
+		Expect(<-recorder.Events).To(Equal("Normal InsightResolved Namespace shop: Optimized"))
+
+		r.recordInsightChanges(nsFinOps, nsFinOps.Status.Insights)
+		Expect(recorder.Events).To(BeEmpty())
+	})
+})
+
+var _ = Describe("NamespaceFinOps workload insights", func() {
+	It("should point the insights at the offending workload", func() {
+		ctx := context.Background()
+		resources := func(cpu, mem string) corev1.ResourceRequirements {
+			list := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(mem)}
+			return corev1.ResourceRequirements{Requests: list, Limits: list}
+		}
+		isController := true
+		pod := func(name string, owner metav1.OwnerReference, res corev1.ResourceRequirements) *corev1.Pod {
+			owner.Controller = &isController
+			return &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", OwnerReferences: []metav1.OwnerReference{owner}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Resources: res}}},
+				Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+			}
+		}
+		usage := func(name, cpu, mem string) metricsv1beta1.PodMetrics {
+			return metricsv1beta1.PodMetrics{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"},
+				Containers: []metricsv1beta1.ContainerMetrics{{Name: "app", Usage: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(mem),
+				}}},
+			}
+		}
+
+		nsFinOps := &finopsv1.NamespaceFinOps{
+			ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "kubex"},
+			Spec:       finopsv1.NamespaceFinOpsSpec{TargetNamespace: "shop"},
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithStatusSubresource(nsFinOps).WithObjects(
+			nsFinOps,
+			&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+				Name: "api-7d9f", Namespace: "shop",
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "api", UID: "api"}},
+			}},
+			pod("api-7d9f-a", metav1.OwnerReference{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "api-7d9f", UID: "rs"}, resources("1", "128Mi")),
+			pod("api-7d9f-b", metav1.OwnerReference{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "api-7d9f", UID: "rs"}, resources("1", "128Mi")),
+			pod("db-0", metav1.OwnerReference{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "db", UID: "db"}, resources("2", "1Gi")),
+		).Build()
+
+		metricsClient := metricsfake.NewSimpleClientset()
+		metricsClient.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, &metricsv1beta1.PodMetricsList{Items: []metricsv1beta1.PodMetrics{
+				usage("api-7d9f-a", "50m", "100Mi"),
+				usage("api-7d9f-b", "50m", "100Mi"),
+				usage("db-0", "1800m", "900Mi"),
+			}}, nil
+		})
+
+		r := &NamespaceFinOpsReconciler{Client: fakeClient, Scheme: scheme.Scheme, MetricsClient: metricsClient}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "shop", Namespace: "kubex"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		updated := &finopsv1.NamespaceFinOps{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Name: "shop", Namespace: "kubex"}, updated)).To(Succeed())
+		// The namespace as a whole uses enough CPU, only the api Deployment wastes it
+		Expect(updated.Status.Insights).To(Equal([]string{"Optimized"}))
+		Expect(updated.Status.WorkloadInsights).To(Equal([]finopsv1.WorkloadInsight{
+			{Kind: "Deployment", Name: "api", Insights: []string{"Overprovisioned CPU"}},
+		}))
+	})
+})
+
+var _ = Describe("NamespaceFinOps underprovisioning insights", func() {
+	totals := func(usageCPU, usageMem, limCPU, limMem string) *usageTotals {
+		return &usageTotals{
+			cpuUsage: resource.MustParse(usageCPU), memUsage: resource.MustParse(usageMem),
+			cpuReq: resource.MustParse(limCPU), memReq: resource.MustParse(limMem),
+			cpuLim: resource.MustParse(limCPU), memLim: resource.MustParse(limMem),
+		}
+	}
+
+	It("should flag usage close to the limits", func() {
+		Expect(totals("950m", "900Mi", "1", "1Gi").insights()).To(Equal([]string{"CPU Throttled Risk", "Memory Pressure"}))
+		Expect(totals("900m", "880Mi", "1", "1Gi").insights()).To(Equal([]string{"Memory Pressure"}))
+		Expect(totals("500m", "512Mi", "1", "1Gi").insights()).To(BeEmpty())
+	})
+
+	It("should not compare usage to partial limits", func() {
+		t := totals("950m", "900Mi", "1", "1Gi")
+		t.missingLimits = true
+		Expect(t.insights()).To(Equal([]string{"Uncapped"}))
+	})
+})
+
+var _ = Describe("NamespaceFinOps BestEffort insight", func() {
+	It("should flag namespaces with a significant share of BestEffort pods", func() {
+		pod := func(requests corev1.ResourceList) *corev1.Pod {
+			return &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "app", Resources: corev1.ResourceRequirements{Requests: requests}},
+			}}}
+		}
+		burstable := corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}
+
+		t := &usageTotals{}
+		t.addPod(pod(nil))
+		for range 3 {
+			t.addPod(pod(burstable))
+		}
+		Expect(t.insights()).To(ContainElement("BestEffort Pods"))
+
+		t.addPod(pod(burstable))
+		Expect(t.insights()).NotTo(ContainElement("BestEffort Pods"))
+		// A container without a memory request is still reported on its own
+		Expect(t.insights()).To(ContainElement("Missing Requests"))
+	})
+})
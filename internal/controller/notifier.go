@@ -0,0 +1,135 @@
+/*
+Copyright 2026 migalsp.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Notification types sent to the webhook
+const (
+	NotificationPhaseTransition = "PhaseTransition"
+	NotificationScalingTimeout  = "ScalingTimeout"
+)
+
+// PhaseNotification is the JSON payload posted to the webhook
+type PhaseNotification struct {
+	// Text is a human readable summary, which is what Slack incoming webhooks display
+	Text               string    `json:"text"`
+	Type               string    `json:"type"`
+	Kind               string    `json:"kind"`
+	Name               string    `json:"name"`
+	Namespace          string    `json:"namespace"`
+	OldPhase           string    `json:"oldPhase,omitempty"`
+	NewPhase           string    `json:"newPhase"`
+	BlockingNamespaces []string  `json:"blockingNamespaces,omitempty"`
+	Timestamp          time.Time `json:"timestamp"`
+}
+
+// WebhookNotifier posts scaling notifications to an HTTP endpoint. A nil notifier is
+// valid and discards every notification.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+
+	// timeouts remembers the scaling action each object was last notified about,
+	// so a timeout is only reported once and not on every requeue
+	timeouts sync.Map
+}
+
+// NewWebhookNotifier returns a notifier for url, or nil when url is empty.
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	if url == "" {
+		return nil
+	}
+	return &WebhookNotifier{
+		URL:    url,
+		Client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Notify sends n in the background so a slow webhook never stalls reconciliation.
+func (w *WebhookNotifier) Notify(n PhaseNotification) {
+	if w == nil {
+		return
+	}
+	if n.Timestamp.IsZero() {
+		n.Timestamp = time.Now().UTC()
+	}
+	if n.Text == "" {
+		n.Text = notificationText(n)
+	}
+
+	body, err := json.Marshal(n)
+	if err != nil {
+		logf.Log.Error(err, "Failed to encode webhook notification")
+		return
+	}
+
+	go func() {
+		resp, err := w.Client.Post(w.URL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			logf.Log.Error(err, "Failed to deliver webhook notification", "kind", n.Kind, "name", n.Name)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			logf.Log.Info("Webhook rejected notification", "kind", n.Kind, "name", n.Name, "status", resp.StatusCode)
+		}
+	}()
+}
+
+// NotifyTimeout reports a scaling timeout once per scaling action, identified by the
+// time the action started.
+func (w *WebhookNotifier) NotifyTimeout(n PhaseNotification, startedAt time.Time) {
+	if w == nil {
+		return
+	}
+	key := n.Kind + "/" + n.Namespace + "/" + n.Name
+	if prev, ok := w.timeouts.Load(key); ok && prev.(time.Time).Equal(startedAt) {
+		return
+	}
+	w.timeouts.Store(key, startedAt)
+
+	n.Type = NotificationScalingTimeout
+	w.Notify(n)
+}
+
+func notificationText(n PhaseNotification) string {
+	var text string
+	if n.Type == NotificationScalingTimeout {
+		text = fmt.Sprintf("%s %s/%s exceeded the scaling timeout while %s", n.Kind, n.Namespace, n.Name, n.NewPhase)
+	} else {
+		oldPhase := n.OldPhase
+		if oldPhase == "" {
+			oldPhase = "Unknown"
+		}
+		text = fmt.Sprintf("%s %s/%s transitioned from %s to %s", n.Kind, n.Namespace, n.Name, oldPhase, n.NewPhase)
+	}
+	if len(n.BlockingNamespaces) > 0 {
+		text += ". Waiting on: " + strings.Join(n.BlockingNamespaces, ", ")
+	}
+	return text
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2026 migalsp.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WebhookNotifier", func() {
+	var (
+		server   *httptest.Server
+		received chan PhaseNotification
+	)
+
+	BeforeEach(func() {
+		received = make(chan PhaseNotification, 10)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var n PhaseNotification
+			Expect(json.NewDecoder(r.Body).Decode(&n)).To(Succeed())
+			received <- n
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should post phase transitions with a readable summary", func() {
+		notifier := NewWebhookNotifier(server.URL)
+		notifier.Notify(PhaseNotification{
+			Type:               NotificationPhaseTransition,
+			Kind:               "ScalingGroup",
+			Name:               "dev",
+			Namespace:          "kubex",
+			OldPhase:           "ScaledUp",
+			NewPhase:           "ScalingDown",
+			BlockingNamespaces: []string{"api"},
+		})
+
+		var n PhaseNotification
+		Eventually(received).Should(Receive(&n))
+		Expect(n.NewPhase).To(Equal("ScalingDown"))
+		Expect(n.Text).To(ContainSubstring("from ScaledUp to ScalingDown"))
+		Expect(n.Text).To(ContainSubstring("api"))
+	})
+
+	It("should report a timeout only once per scaling action", func() {
+		notifier := NewWebhookNotifier(server.URL)
+		started := time.Now()
+		n := PhaseNotification{Kind: "ScalingConfig", Name: "api", Namespace: "kubex", NewPhase: "ScalingUp"}
+
+		notifier.NotifyTimeout(n, started)
+		notifier.NotifyTimeout(n, started)
+
+		Eventually(received).Should(Receive())
+		Consistently(received, 200*time.Millisecond).ShouldNot(Receive())
+
+		notifier.NotifyTimeout(n, started.Add(time.Minute))
+		Eventually(received).Should(Receive())
+	})
+
+	It("should discard notifications when no URL is configured", func() {
+		notifier := NewWebhookNotifier("")
+		Expect(notifier).To(BeNil())
+		notifier.Notify(PhaseNotification{Kind: "ScalingGroup", Name: "dev"})
+	})
+})
@@ -0,0 +1,76 @@
+/*
+Copyright 2026 migalsp.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Hours in an average month, used to turn hourly prices into a monthly figure
+const hoursPerMonth = 730
+
+// PricingModel holds the unit prices used to estimate the cost of namespace resources
+type PricingModel struct {
+	// CPUHourly is the price of one vCPU for one hour
+	CPUHourly float64
+	// MemoryGiBHourly is the price of one GiB of memory for one hour
+	MemoryGiBHourly float64
+}
+
+// DefaultPricing roughly matches general purpose on-demand instances of the major clouds
+var DefaultPricing = PricingModel{
+	CPUHourly:       0.04,
+	MemoryGiBHourly: 0.005,
+}
+
+// PricingFromEnv reads KUBEX_PRICE_CPU_HOURLY and KUBEX_PRICE_MEMORY_GIB_HOURLY,
+// falling back to DefaultPricing for unset values.
+func PricingFromEnv() (PricingModel, error) {
+	pricing := DefaultPricing
+	if v := os.Getenv("KUBEX_PRICE_CPU_HOURLY"); v != "" {
+		price, err := strconv.ParseFloat(v, 64)
+		if err != nil || price < 0 {
+			return pricing, fmt.Errorf("invalid KUBEX_PRICE_CPU_HOURLY %q", v)
+		}
+		pricing.CPUHourly = price
+	}
+	if v := os.Getenv("KUBEX_PRICE_MEMORY_GIB_HOURLY"); v != "" {
+		price, err := strconv.ParseFloat(v, 64)
+		if err != nil || price < 0 {
+			return pricing, fmt.Errorf("invalid KUBEX_PRICE_MEMORY_GIB_HOURLY %q", v)
+		}
+		pricing.MemoryGiBHourly = price
+	}
+	return pricing, nil
+}
+
+// MonthlyWaste estimates the monthly cost of resources that are requested but not used.
+func (p PricingModel) MonthlyWaste(cpuReq, cpuUsage, memReq, memUsage resource.Quantity) float64 {
+	idleCPU := cpuReq.AsApproximateFloat64() - cpuUsage.AsApproximateFloat64()
+	if idleCPU < 0 {
+		idleCPU = 0
+	}
+	idleMemGiB := (memReq.AsApproximateFloat64() - memUsage.AsApproximateFloat64()) / (1024 * 1024 * 1024)
+	if idleMemGiB < 0 {
+		idleMemGiB = 0
+	}
+	return (idleCPU*p.CPUHourly + idleMemGiB*p.MemoryGiBHourly) * hoursPerMonth
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2026 migalsp.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var _ = Describe("PricingModel", func() {
+	pricing := PricingModel{CPUHourly: 0.1, MemoryGiBHourly: 0.01}
+
+	It("should price idle requests over a month", func() {
+		waste := pricing.MonthlyWaste(
+			resource.MustParse("2"), resource.MustParse("1"),
+			resource.MustParse("4Gi"), resource.MustParse("2Gi"),
+		)
+		// 1 idle vCPU * 0.1 + 2 idle GiB * 0.01 = 0.12 per hour
+		Expect(waste).To(BeNumerically("~", 0.12*hoursPerMonth, 0.001))
+	})
+
+	It("should not report negative waste when usage exceeds requests", func() {
+		waste := pricing.MonthlyWaste(
+			resource.MustParse("100m"), resource.MustParse("500m"),
+			resource.MustParse("128Mi"), resource.MustParse("1Gi"),
+		)
+		Expect(waste).To(BeZero())
+	})
+
+	It("should read prices from the environment", func() {
+		os.Setenv("KUBEX_PRICE_CPU_HOURLY", "0.05")
+		defer os.Unsetenv("KUBEX_PRICE_CPU_HOURLY")
+
+		loaded, err := PricingFromEnv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.CPUHourly).To(Equal(0.05))
+		Expect(loaded.MemoryGiBHourly).To(Equal(DefaultPricing.MemoryGiBHourly))
+
+		os.Setenv("KUBEX_PRICE_CPU_HOURLY", "cheap")
+		_, err = PricingFromEnv()
+		Expect(err).To(HaveOccurred())
+	})
+})
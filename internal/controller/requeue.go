@@ -0,0 +1,94 @@
+/*
+Copyright 2026 migalsp.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// RequeueIntervals are how long the reconcilers wait before looking at a resource again.
+// Zero values fall back to DefaultRequeueIntervals.
+type RequeueIntervals struct {
+	// Base is the periodic requeue of settled resources. NamespaceFinOps record one
+	// history point per Base interval.
+	Base time.Duration
+	// InProgress is the requeue of ScalingGroups and ScalingConfigs while a scaling rolls out
+	InProgress time.Duration
+	// Overridden is the requeue of ScalingConfigs whose namespace a ScalingGroup manages
+	Overridden time.Duration
+}
+
+// DefaultRequeueIntervals are used unless KUBEX_REQUEUE_* or the --requeue-* flags say otherwise
+var DefaultRequeueIntervals = RequeueIntervals{
+	Base:       time.Minute,
+	InProgress: 5 * time.Second,
+	Overridden: 5 * time.Minute,
+}
+
+// RequeueIntervalsFromEnv reads KUBEX_REQUEUE_BASE, KUBEX_REQUEUE_IN_PROGRESS and
+// KUBEX_REQUEUE_OVERRIDDEN as durations, falling back to DefaultRequeueIntervals for unset
+// values.
+func RequeueIntervalsFromEnv() (RequeueIntervals, error) {
+	intervals := DefaultRequeueIntervals
+	for env, dst := range map[string]*time.Duration{
+		"KUBEX_REQUEUE_BASE":        &intervals.Base,
+		"KUBEX_REQUEUE_IN_PROGRESS": &intervals.InProgress,
+		"KUBEX_REQUEUE_OVERRIDDEN":  &intervals.Overridden,
+	} {
+		v := os.Getenv(env)
+		if v == "" {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return intervals, fmt.Errorf("invalid %s %q, expected a positive duration such as 1m", env, v)
+		}
+		*dst = d
+	}
+	return intervals, nil
+}
+
+// Validate rejects intervals that are not positive
+func (i RequeueIntervals) Validate() error {
+	if i.Base <= 0 || i.InProgress <= 0 || i.Overridden <= 0 {
+		return fmt.Errorf("requeue intervals must be positive, got base %s, in progress %s, overridden %s", i.Base, i.InProgress, i.Overridden)
+	}
+	return nil
+}
+
+func (i RequeueIntervals) base() time.Duration {
+	if i.Base > 0 {
+		return i.Base
+	}
+	return DefaultRequeueIntervals.Base
+}
+
+func (i RequeueIntervals) inProgress() time.Duration {
+	if i.InProgress > 0 {
+		return i.InProgress
+	}
+	return DefaultRequeueIntervals.InProgress
+}
+
+func (i RequeueIntervals) overridden() time.Duration {
+	if i.Overridden > 0 {
+		return i.Overridden
+	}
+	return DefaultRequeueIntervals.Overridden
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2026 migalsp.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RequeueIntervals", func() {
+	It("should read intervals from the environment", func() {
+		os.Setenv("KUBEX_REQUEUE_BASE", "10m")
+		defer os.Unsetenv("KUBEX_REQUEUE_BASE")
+
+		loaded, err := RequeueIntervalsFromEnv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.Base).To(Equal(10 * time.Minute))
+		Expect(loaded.InProgress).To(Equal(DefaultRequeueIntervals.InProgress))
+		Expect(loaded.Validate()).To(Succeed())
+
+		os.Setenv("KUBEX_REQUEUE_BASE", "-1m")
+		_, err = RequeueIntervalsFromEnv()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fall back to the defaults for unset intervals", func() {
+		var unset RequeueIntervals
+		Expect(unset.Validate()).NotTo(Succeed())
+		Expect(unset.base()).To(Equal(time.Minute))
+		Expect(unset.inProgress()).To(Equal(5 * time.Second))
+		Expect(unset.overridden()).To(Equal(5 * time.Minute))
+		Expect(RequeueIntervals{Overridden: time.Hour}.overridden()).To(Equal(time.Hour))
+	})
+})
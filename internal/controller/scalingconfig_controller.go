@@ -18,30 +18,139 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+	"github.com/migalsp/kubex-operator/internal/operatorns"
+	"github.com/migalsp/kubex-operator/internal/reconcilestats"
 	"github.com/migalsp/kubex-operator/internal/scaling"
 )
 
+// defaultStageTimeoutSeconds is used when the spec leaves the stage timeout unset
+const defaultStageTimeoutSeconds = 60
+
+// stageTimeout returns how long a stage may block the sequence before it is bypassed.
+func stageTimeout(seconds int32) time.Duration {
+	if seconds <= 0 {
+		seconds = defaultStageTimeoutSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// nextTransition returns the status fields previewing the next schedule change, delayed by
+// the jitter offset of the object.
+func nextTransition(e *scaling.Engine, schedules []finopsv1.ScalingSchedule, manualActive *bool, jitter time.Duration) (*metav1.Time, string) {
+	at, active, ok := e.NextTransitionJittered(schedules, manualActive, e.Now(), jitter)
+	if !ok {
+		return nil, ""
+	}
+	state := "Inactive"
+	if active {
+		state = "Active"
+	}
+	return &metav1.Time{Time: at}, state
+}
+
+// recordAction appends the replica changes of a reconcile to history, keeping the last
+// MaxActionHistory entries. Reconciles that changed nothing are not recorded.
+func recordAction(history []finopsv1.ScalingAction, e *scaling.Engine, active bool, changes []finopsv1.ReplicaChange) []finopsv1.ScalingAction {
+	if len(changes) == 0 {
+		return history
+	}
+	action := finopsv1.ScalingAction{
+		Time:      metav1.NewTime(e.Now()),
+		Direction: "Down",
+		Changes:   changes,
+		Succeeded: true,
+	}
+	if active {
+		action.Direction = "Up"
+	}
+	for _, c := range changes {
+		if c.Error != "" {
+			action.Succeeded = false
+		}
+	}
+	history = append(history, action)
+	if len(history) > finopsv1.MaxActionHistory {
+		history = history[len(history)-finopsv1.MaxActionHistory:]
+	}
+	return history
+}
+
+// scalingFreeze reads the cluster-wide scaling freeze from its ConfigMap, not frozen when
+// the ConfigMap doesn't exist.
+func scalingFreeze(ctx context.Context, reader client.Reader) (scaling.Freeze, error) {
+	cm := &corev1.ConfigMap{}
+	err := reader.Get(ctx, client.ObjectKey{Name: scaling.FreezeConfigMapName, Namespace: operatorns.Namespace()}, cm)
+	if errors.IsNotFound(err) {
+		return scaling.Freeze{}, nil
+	}
+	if err != nil {
+		return scaling.Freeze{}, fmt.Errorf("failed to read the scaling freeze: %w", err)
+	}
+	return scaling.FreezeFromConfigMap(cm), nil
+}
+
+// namespaceHistory returns the usage history recorded by the NamespaceFinOps tracking a
+// namespace, nil when it is not tracked.
+func namespaceHistory(ctx context.Context, reader client.Reader, nsName string) ([]finopsv1.MetricDataPoint, error) {
+	var list finopsv1.NamespaceFinOpsList
+	if err := reader.List(ctx, &list); err != nil {
+		return nil, fmt.Errorf("failed to list NamespaceFinOps: %w", err)
+	}
+	for _, item := range list.Items {
+		if item.Spec.TargetNamespace == nsName && item.DeletionTimestamp.IsZero() {
+			return item.Status.History, nil
+		}
+	}
+	return nil, nil
+}
+
 // ScalingConfigReconciler reconciles a ScalingConfig object
 type ScalingConfigReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
-	Engine *scaling.Engine
+	Scheme   *runtime.Scheme
+	Engine   *scaling.Engine
+	Recorder record.EventRecorder
+	Notifier *WebhookNotifier
+	// APIReader reads the scaling freeze without caching every ConfigMap of the cluster,
+	// the Client is used when nil
+	APIReader client.Reader
+	// Stats records the reconciles of the controller, may be nil
+	Stats *reconcilestats.Recorder
+	// Requeue sets how often configs are checked again
+	Requeue RequeueIntervals
+	// ScaleLimiter caps the replica updates in flight, shared by both scaling reconcilers.
+	// Passed to the Engine created by SetupWithManager, unlimited when nil.
+	ScaleLimiter *scaling.Limiter
 }
 
 // +kubebuilder:rbac:groups=finops.kubex.io,resources=scalingconfigs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=finops.kubex.io,resources=scalingconfigs/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=finops.kubex.io,resources=scalingconfigs/finalizers,verbs=update
 // +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=argoproj.io,resources=rollouts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=argoproj.io,resources=rollouts/scale,verbs=get;update;patch
 
 func (r *ScalingConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	l := logf.FromContext(ctx)
@@ -59,54 +168,106 @@ func (r *ScalingConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	// Check if this namespace is managed by any ScalingGroup
 	groups := &finopsv1.ScalingGroupList{}
 	if err := r.List(ctx, groups); err == nil {
-		for _, g := range groups.Items {
-			for _, managedNs := range g.Spec.Namespaces {
-				if managedNs == config.Spec.TargetNamespace {
-					l.Info("Namespace managed by group, overriding individual config", "namespace", config.Spec.TargetNamespace, "group", g.Name)
-					config.Status.Phase = "OverriddenByGroup"
-					config.Status.LastAction = metav1.Now()
-					if err := r.Status().Update(ctx, config); err != nil {
-						return ctrl.Result{}, err
-					}
-					return ctrl.Result{RequeueAfter: 5 * time.Minute}, nil
-				}
+		if g := scaling.ManagingGroup(groups.Items, config.Spec.TargetNamespace); g != nil {
+			l.Info("Namespace managed by group, overriding individual config", "namespace", config.Spec.TargetNamespace, "group", g.Name)
+			r.recordPhaseTransition(config, config.Status.Phase, "OverriddenByGroup")
+			config.Status.Phase = "OverriddenByGroup"
+			config.Status.LastAction = metav1.Now()
+			if err := r.Status().Update(ctx, config); err != nil {
+				return ctrl.Result{}, err
 			}
+			return ctrl.Result{RequeueAfter: r.Requeue.overridden()}, nil
 		}
 	}
 
 	// 2. Determine desired state
-	targetActive := r.Engine.IsActive(config.Spec.Schedules, config.Spec.Active)
+	jitter := scaling.JitterOffset(config.Name, config.Spec.JitterSeconds)
+	targetActive := r.Engine.IsActiveJittered(config.Spec.Schedules, config.Spec.Active, jitter)
+
+	// 2.1 Auto-idle only scales down within the active windows, the manual override wins
+	idle := false
+	if config.Spec.AutoIdle != nil && config.Spec.Active == nil && targetActive {
+		history, err := namespaceHistory(ctx, r.Client, config.Spec.TargetNamespace)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		idle = scaling.EvaluateIdle(config.Spec.AutoIdle, history, config.Status.Idle)
+		targetActive = !idle
+	}
+	if idle != config.Status.Idle {
+		l.Info("Auto-idle state changed", "targetNamespace", config.Spec.TargetNamespace, "idle", idle)
+		config.Status.Idle = idle
+	}
 
 	l.Info("Reconciling ScalingConfig", "targetNamespace", config.Spec.TargetNamespace, "targetActive", targetActive)
 
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+	freeze, err := scalingFreeze(ctx, reader)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// 2.5 Phase and Timeout Logic
 	currentPhase := config.Status.Phase
-	computedPhase := r.Engine.ComputePhase(ctx, config.Spec.TargetNamespace, targetActive)
+	computedPhase := r.Engine.ComputePhase(ctx, config.Spec.TargetNamespace, targetActive, config.Spec.ScaleKinds, config.Spec.ScaleReplicaSets, config.Spec.ScaleDownReplicaPercent, config.Status.OriginalReplicas)
 
 	if currentPhase != computedPhase {
 		config.Status.Phase = computedPhase
 		config.Status.LastAction = metav1.Now()
+		r.recordPhaseTransition(config, currentPhase, computedPhase)
+		r.Notifier.Notify(PhaseNotification{
+			Type:      NotificationPhaseTransition,
+			Kind:      "ScalingConfig",
+			Name:      config.Name,
+			Namespace: config.Namespace,
+			OldPhase:  currentPhase,
+			NewPhase:  computedPhase,
+		})
 	} else if config.Status.LastAction.IsZero() {
 		config.Status.LastAction = metav1.Now()
 	}
 
+	// A scaling freeze holds every replica count, the status still follows the cluster
+	if freeze.Frozen {
+		l.Info("Scaling is frozen, not scaling", "reason", freeze.Reason)
+		config.Status.NextTransition, config.Status.NextTransitionState = nextTransition(r.Engine, config.Spec.Schedules, config.Spec.Active, jitter)
+		if err := r.Status().Update(ctx, config); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: r.Requeue.base()}, nil
+	}
+
 	timeoutPassed := false
 	if config.Status.Phase == "ScalingUp" || config.Status.Phase == "ScalingDown" {
-		if time.Since(config.Status.LastAction.Time) > time.Minute {
-			l.Info("Scaling timeout exceeded 1 minute. Overriding sequence blocks.", "elapsed", time.Since(config.Status.LastAction.Time))
+		timeout := stageTimeout(config.Spec.StageTimeoutSeconds)
+		if r.Engine.StageTimedOut(config.Status.LastAction.Time, timeout) {
+			l.Info("Scaling timeout exceeded. Overriding sequence blocks.", "timeout", timeout, "elapsed", r.Engine.Now().Sub(config.Status.LastAction.Time))
 			timeoutPassed = true
+			r.Notifier.NotifyTimeout(PhaseNotification{
+				Kind:               "ScalingConfig",
+				Name:               config.Name,
+				Namespace:          config.Namespace,
+				NewPhase:           config.Status.Phase,
+				BlockingNamespaces: []string{config.Spec.TargetNamespace},
+			}, config.Status.LastAction.Time)
 		}
 	}
 
 	// 3. Execute Scaling if needed
-	newReplicas, ready, err := r.Engine.ScaleTarget(ctx, config.Spec.TargetNamespace, targetActive, config.Spec.Sequence, config.Spec.Exclusions, config.Status.OriginalReplicas, timeoutPassed)
+	exclusions := scaling.Exclusions{Names: config.Spec.Exclusions, Conditional: config.Spec.ConditionalExclusions}
+	newReplicas, ready, changes, err := r.Engine.ScaleTargetChanges(ctx, config.Spec.TargetNamespace, targetActive, config.Spec.Sequence, exclusions, config.Spec.ScaleKinds, config.Spec.ScaleReplicaSets, config.Status.OriginalReplicas, config.Spec.ScaleDownReplicaPercent, config.Spec.ScaleUpStepPercent, timeoutPassed)
 	if err != nil {
 		l.Error(err, "failed to execute scaling")
-		return ctrl.Result{RequeueAfter: time.Minute}, err
+		return ctrl.Result{RequeueAfter: r.Requeue.base()}, err
 	}
 
 	// 4. Update Status
 	config.Status.OriginalReplicas = newReplicas
+	config.Status.ActionHistory = recordAction(config.Status.ActionHistory, r.Engine, targetActive, changes)
+	config.Status.NextTransition, config.Status.NextTransitionState = nextTransition(r.Engine, config.Spec.Schedules, config.Spec.Active, jitter)
 	// Phase and LastAction are tracked before ScaleTarget so the timeout window starts immediately.
 
 	if err := r.Status().Update(ctx, config); err != nil {
@@ -115,20 +276,57 @@ func (r *ScalingConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 	// Faster requeue if scaling is in progress
 	if !ready {
-		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		return ctrl.Result{RequeueAfter: r.Requeue.inProgress()}, nil
 	}
 
-	// Check again in 1 minute for schedule changes
-	return ctrl.Result{RequeueAfter: time.Minute}, nil
+	// Check again later for schedule changes
+	return ctrl.Result{RequeueAfter: r.Requeue.base()}, nil
+}
+
+// recordPhaseTransition emits a PhaseTransition event on the config when its phase changes,
+// nothing when the reconciler has no Recorder.
+func (r *ScalingConfigReconciler) recordPhaseTransition(config *finopsv1.ScalingConfig, from, to string) {
+	if r.Recorder == nil || from == to {
+		return
+	}
+	r.Recorder.Eventf(config, corev1.EventTypeNormal, "PhaseTransition", "Config phase transitioned from %s to %s", from, to)
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ScalingConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if r.Engine == nil {
-		r.Engine = &scaling.Engine{Client: r.Client}
+		r.Engine = &scaling.Engine{Client: r.Client, Recorder: mgr.GetEventRecorderFor("kubex-scaling"), Limiter: r.ScaleLimiter}
+	}
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("scalingconfig-controller")
+	}
+	if r.APIReader == nil {
+		r.APIReader = mgr.GetAPIReader()
 	}
+	// Only spec changes and creations of workloads matter, status updates while scaling
+	// are picked up by the requeue
+	workloadChanged := builder.WithPredicates(predicate.GenerationChangedPredicate{})
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&finopsv1.ScalingConfig{}).
+		Watches(&appsv1.Deployment{}, handler.EnqueueRequestsFromMapFunc(r.configsForWorkload), workloadChanged).
+		Watches(&appsv1.StatefulSet{}, handler.EnqueueRequestsFromMapFunc(r.configsForWorkload), workloadChanged).
 		Named("scalingconfig").
-		Complete(r)
+		Complete(r.Stats.Wrap("scalingconfig", r))
+}
+
+// configsForWorkload maps a workload to the ScalingConfigs targeting its namespace.
+func (r *ScalingConfigReconciler) configsForWorkload(ctx context.Context, obj client.Object) []reconcile.Request {
+	configList := &finopsv1.ScalingConfigList{}
+	if err := r.List(ctx, configList); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list ScalingConfigs for workload", "namespace", obj.GetNamespace(), "name", obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, cfg := range configList.Items {
+		if cfg.Spec.TargetNamespace == obj.GetNamespace() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&cfg)})
+		}
+	}
+	return requests
 }
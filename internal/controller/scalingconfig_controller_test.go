@@ -18,11 +18,18 @@ package controller
 
 import (
 	"context"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -82,3 +89,160 @@ var _ = Describe("ScalingConfig Controller", func() {
 		})
 	})
 })
+
+var _ = Describe("stageTimeout", func() {
+	It("should default to one minute when unset", func() {
+		Expect(stageTimeout(0)).To(Equal(time.Minute))
+	})
+
+	It("should use the configured seconds", func() {
+		Expect(stageTimeout(300)).To(Equal(5 * time.Minute))
+	})
+})
+
+var _ = Describe("recordAction", func() {
+	engine := &scaling.Engine{}
+
+	It("should not record reconciles without changes", func() {
+		Expect(recordAction(nil, engine, false, nil)).To(BeEmpty())
+	})
+
+	It("should record the direction and whether every update succeeded", func() {
+		history := recordAction(nil, engine, false, []finopsv1.ReplicaChange{
+			{Namespace: "backend", Kind: "Deployment", Name: "api", From: 2, To: 0},
+		})
+		history = recordAction(history, engine, true, []finopsv1.ReplicaChange{
+			{Namespace: "backend", Kind: "Deployment", Name: "api", From: 0, To: 2},
+			{Namespace: "backend", Kind: "StatefulSet", Name: "db", From: 0, To: 1, Error: "conflict"},
+		})
+		Expect(history).To(HaveLen(2))
+		Expect(history[0].Direction).To(Equal("Down"))
+		Expect(history[0].Succeeded).To(BeTrue())
+		Expect(history[1].Direction).To(Equal("Up"))
+		Expect(history[1].Succeeded).To(BeFalse())
+	})
+
+	It("should keep only the latest actions", func() {
+		var history []finopsv1.ScalingAction
+		for i := range finopsv1.MaxActionHistory + 5 {
+			history = recordAction(history, engine, false, []finopsv1.ReplicaChange{
+				{Namespace: "backend", Kind: "Deployment", Name: "api", From: int32(i + 1), To: 0},
+			})
+		}
+		Expect(history).To(HaveLen(finopsv1.MaxActionHistory))
+		Expect(history[0].Changes[0].From).To(Equal(int32(6)))
+		Expect(history[len(history)-1].Changes[0].From).To(Equal(int32(finopsv1.MaxActionHistory + 5)))
+	})
+})
+
+var _ = Describe("ScalingConfig workload watch", func() {
+	It("should enqueue only the configs targeting the workload's namespace", func() {
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			&finopsv1.ScalingConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "kubex"},
+				Spec:       finopsv1.ScalingConfigSpec{TargetNamespace: "backend"},
+			},
+			&finopsv1.ScalingConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "kubex"},
+				Spec:       finopsv1.ScalingConfigSpec{TargetNamespace: "frontend"},
+			},
+		).Build()
+		r := &ScalingConfigReconciler{Client: fakeClient}
+
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "backend"}}
+		Expect(r.configsForWorkload(context.Background(), deployment)).To(Equal([]reconcile.Request{
+			{NamespacedName: types.NamespacedName{Name: "backend", Namespace: "kubex"}},
+		}))
+
+		unmanaged := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "data"}}
+		Expect(r.configsForWorkload(context.Background(), unmanaged)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("ScalingConfig phase events", func() {
+	It("should record an event on phase transitions only", func() {
+		ctx := context.Background()
+		config := &finopsv1.ScalingConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "kubex"},
+			Spec:       finopsv1.ScalingConfigSpec{TargetNamespace: "backend"},
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(config).WithStatusSubresource(config).Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &ScalingConfigReconciler{Client: fakeClient, Scheme: fakeClient.Scheme(), Engine: &scaling.Engine{Client: fakeClient}, Recorder: recorder}
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(config)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Events).To(Receive(ContainSubstring("PhaseTransition")))
+
+		// Nothing changed, the phase stays the same
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(config)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Events).To(BeEmpty())
+	})
+})
+
+var _ = Describe("ScalingConfig scaling freeze", func() {
+	It("should keep the replicas while frozen and still update the status", func() {
+		ctx := context.Background()
+		inactive := false
+		replicas := int32(2)
+		config := &finopsv1.ScalingConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "kubex"},
+			Spec:       finopsv1.ScalingConfigSpec{TargetNamespace: "backend", Active: &inactive},
+		}
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "backend"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		}
+		freeze := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: scaling.FreezeConfigMapName, Namespace: "kubex"},
+			Data:       map[string]string{"frozen": "true", "reason": "release"},
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(config, deployment, freeze).WithStatusSubresource(config).Build()
+		r := &ScalingConfigReconciler{Client: fakeClient, Scheme: fakeClient.Scheme(), Engine: &scaling.Engine{Client: fakeClient}, Recorder: record.NewFakeRecorder(10)}
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(config)})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+		Expect(*deployment.Spec.Replicas).To(Equal(int32(2)))
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(config), config)).To(Succeed())
+		Expect(config.Status.Phase).NotTo(BeEmpty())
+	})
+})
+
+var _ = Describe("ScalingConfig auto-idle", func() {
+	It("should scale an idle namespace down within its active window", func() {
+		ctx := context.Background()
+		replicas := int32(2)
+		config := &finopsv1.ScalingConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "kubex"},
+			Spec: finopsv1.ScalingConfigSpec{
+				TargetNamespace: "backend",
+				AutoIdle:        &finopsv1.AutoIdlePolicy{IdleBelowMillicores: 50, ActiveAboveMillicores: 200, IdlePoints: 2},
+			},
+		}
+		finOps := &finopsv1.NamespaceFinOps{
+			ObjectMeta: metav1.ObjectMeta{Name: "backend", Namespace: "kubex"},
+			Spec:       finopsv1.NamespaceFinOpsSpec{TargetNamespace: "backend"},
+			Status: finopsv1.NamespaceFinOpsStatus{History: []finopsv1.MetricDataPoint{
+				{CPU: finopsv1.ResourceMetrics{Usage: "5m"}},
+				{CPU: finopsv1.ResourceMetrics{Usage: "3m"}},
+			}},
+		}
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "backend"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(config, finOps, deployment).WithStatusSubresource(config).Build()
+		r := &ScalingConfigReconciler{Client: fakeClient, Scheme: fakeClient.Scheme(), Engine: &scaling.Engine{Client: fakeClient}, Recorder: record.NewFakeRecorder(10)}
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(config)})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+		Expect(*deployment.Spec.Replicas).To(Equal(int32(0)))
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(config), config)).To(Succeed())
+		Expect(config.Status.Idle).To(BeTrue())
+	})
+})
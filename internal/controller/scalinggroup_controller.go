@@ -19,18 +19,28 @@ package controller
 import (
 	"context"
 	"fmt"
+	"math"
+	"slices"
 	"strings"
 	"time"
 
+	"golang.org/x/sync/errgroup"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+	"github.com/migalsp/kubex-operator/internal/reconcilestats"
 	"github.com/migalsp/kubex-operator/internal/scaling"
 )
 
@@ -39,12 +49,24 @@ type ScalingGroupReconciler struct {
 	Scheme   *runtime.Scheme
 	Engine   *scaling.Engine
 	Recorder record.EventRecorder
+	Notifier *WebhookNotifier
+	// APIReader reads the scaling freeze without caching every ConfigMap of the cluster,
+	// the Client is used when nil
+	APIReader client.Reader
+	// Stats records the reconciles of the controller, may be nil
+	Stats *reconcilestats.Recorder
+	// Requeue sets how often groups are checked again
+	Requeue RequeueIntervals
+	// ScaleLimiter caps the replica updates in flight, shared by both scaling reconcilers.
+	// Passed to the Engine created by SetupWithManager, unlimited when nil.
+	ScaleLimiter *scaling.Limiter
 }
 
 // +kubebuilder:rbac:groups=finops.kubex.io,resources=scalinggroups,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=finops.kubex.io,resources=scalinggroups/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=finops.kubex.io,resources=scalinggroups/finalizers,verbs=update
 // +kubebuilder:rbac:groups=finops.kubex.io,resources=scalingpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 
 func (r *ScalingGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	l := logf.FromContext(ctx)
@@ -59,8 +81,26 @@ func (r *ScalingGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	}
 
 	// 2. Determine desired state
-	targetActive := r.Engine.IsActive(group.Spec.Schedules, group.Spec.Active)
-	l.Info("Reconciling ScalingGroup", "category", group.Spec.Category, "namespaces", group.Spec.Namespaces, "targetActive", targetActive)
+	managedNamespaces, err := r.resolveNamespaces(ctx, group)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	jitter := scaling.JitterOffset(group.Name, group.Spec.JitterSeconds)
+	targetActive := r.Engine.IsActiveJittered(group.Spec.Schedules, group.Spec.Active, jitter)
+	l.Info("Reconciling ScalingGroup", "category", group.Spec.Category, "namespaces", managedNamespaces, "targetActive", targetActive)
+
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+	freeze, err := scalingFreeze(ctx, reader)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if freeze.Frozen {
+		// The targets are only checked, the status still follows the cluster
+		l.Info("Scaling is frozen, not scaling", "reason", freeze.Reason)
+	}
 
 	// Initialize status maps if nil
 	if group.Status.OriginalReplicas == nil {
@@ -69,7 +109,6 @@ func (r *ScalingGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request
 
 	// 3. Define stages from group.Spec.Sequence
 	// Default: all namespaces in one stage if no sequence defined
-	managedNamespaces := group.Spec.Namespaces
 	var stages [][]string
 
 	if len(group.Spec.Sequence) > 0 {
@@ -116,11 +155,10 @@ func (r *ScalingGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	allReady := true
 	managedCount := 0
 
+	timeout := stageTimeout(group.Spec.StageTimeoutSeconds)
 	timeoutPassed := false
 	if group.Status.Phase == "ScalingUp" || group.Status.Phase == "ScalingDown" {
-		if time.Since(group.Status.LastAction.Time) > time.Minute {
-			timeoutPassed = true
-		}
+		timeoutPassed = r.Engine.StageTimedOut(group.Status.LastAction.Time, timeout)
 	}
 
 	namespacesReady := 0
@@ -131,155 +169,75 @@ func (r *ScalingGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request
 
 	var blockingNamespaces []string
 	var readyNamespaces []string
+	var changes []finopsv1.ReplicaChange
+	// currentStage stays on the last stage when all of them are ready
+	currentStage := len(stages)
 
 	// 4. Iterate over stages
 	for i, stage := range stages {
 		l.Info("Processing scaling stage", "stageIndex", i, "namespaces", stage)
+		managedCount += len(stage)
 
-		stageReady := true
-		for _, ns := range stage {
-			managedCount++
-
-			// Handle External Targets embedded in the sequence
-			if strings.HasPrefix(ns, "ext:") {
-				extId := strings.TrimPrefix(ns, "ext:")
-				var extTarget *finopsv1.ExternalTarget
-				for i := range group.Spec.ExternalTargets {
-					if group.Spec.ExternalTargets[i].Identifier == extId {
-						extTarget = &group.Spec.ExternalTargets[i]
-						break
-					}
-				}
-
-				if extTarget == nil {
-					l.Info("External target in sequence not found in spec", "target", extId)
-					continue
-				}
-
-				provider, ok := r.Engine.Providers[extTarget.Provider]
-				if !ok {
-					l.Error(fmt.Errorf("provider not found"), "cannot scale external target", "target", extTarget.Identifier)
-					stageReady = false
-					allReady = false
-					// check if not already in blocking string
-					found := false
-					for _, b := range blockingNamespaces {
-					    if b == ns { found = true; break }
-					}
-					if !found { blockingNamespaces = append(blockingNamespaces, ns) }
-					continue
-				}
+		// Try to find the ScalingConfigs that manage the namespaces of this stage
+		configList := &finopsv1.ScalingConfigList{}
+		if err := r.List(ctx, configList, client.InNamespace(group.Namespace)); err != nil {
+			l.Error(err, "failed to list ScalingConfigs for inheritance")
+		}
 
-				if err := provider.Scale(ctx, *extTarget, targetActive); err != nil {
-					l.Error(err, "failed to scale external target", "target", extTarget.Identifier)
-					stageReady = false
-					allReady = false
-					found := false
-					for _, b := range blockingNamespaces {
-					    if b == ns { found = true; break }
-					}
-					if !found { blockingNamespaces = append(blockingNamespaces, ns) }
-					continue
-				}
+		// Targets of a stage scale in parallel, the results are merged in stage order
+		results := make([]stageTargetResult, len(stage))
+		g, gctx := errgroup.WithContext(ctx)
+		g.SetLimit(maxConcurrentStageTargets)
+		for j, ns := range stage {
+			g.Go(func() error {
+				results[j] = r.scaleStageTarget(gctx, group, ns, configList.Items, targetActive, timeoutPassed, freeze.Frozen)
+				return nil
+			})
+		}
+		_ = g.Wait()
 
-				isRdy, err := provider.IsReady(ctx, *extTarget, targetActive)
-				if err != nil {
-					l.Error(err, "failed to check readiness of external target", "target", extTarget.Identifier)
-					stageReady = false
-					allReady = false
-					found := false
-					for _, b := range blockingNamespaces {
-					    if b == ns { found = true; break }
-					}
-					if !found { blockingNamespaces = append(blockingNamespaces, ns) }
-				} else if !isRdy {
-					stageReady = false
-					allReady = false
-					found := false
-					for _, b := range blockingNamespaces {
-					    if b == ns { found = true; break }
-					}
-					if !found { blockingNamespaces = append(blockingNamespaces, ns) }
-				} else {
-					namespacesReady++
-					readyNamespaces = append(readyNamespaces, ns)
-				}
+		stageReady := true
+		for j, ns := range stage {
+			res := results[j]
+			changes = append(changes, res.changes...)
+			if res.skipped {
 				continue
 			}
-
-			// a. Fetch individual ScalingConfig for exclusions and sequence inheritance
-			var exclusions []string
-			var nsSequence []string
-
-			// Try to find a ScalingConfig that manages this target namespace
-			configList := &finopsv1.ScalingConfigList{}
-			if err := r.List(ctx, configList, client.InNamespace(group.Namespace)); err == nil {
-				for _, cfg := range configList.Items {
-					if cfg.Spec.TargetNamespace == ns {
-						exclusions = cfg.Spec.Exclusions
-						nsSequence = cfg.Spec.Sequence
-						l.Info("Found ScalingConfig for inheritance", "namespace", ns, "config", cfg.Name)
-						break
-					}
-				}
-			}
-
-			// b. Scale Target
-			nsKeyPrefix := ns + "/"
-			nsReplicas := make(map[string]int32)
-			if group.Status.OriginalReplicas != nil {
-				for k, v := range group.Status.OriginalReplicas {
-					if strings.HasPrefix(k, nsKeyPrefix) {
-						nsReplicas[strings.TrimPrefix(k, nsKeyPrefix)] = v
-					}
-				}
-			}
-
-			updatedOriginals, nsReady, err := r.Engine.ScaleTarget(ctx, ns, targetActive, nsSequence, exclusions, nsReplicas, timeoutPassed)
-			if err != nil {
-				l.Error(err, "failed to scale namespace", "namespace", ns)
-				allReady = false
+			if res.failed {
 				stageReady = false
-				blockingNamespaces = append(blockingNamespaces, ns)
+				allReady = false
+				if !slices.Contains(blockingNamespaces, ns) {
+					blockingNamespaces = append(blockingNamespaces, ns)
+				}
 				continue
 			}
 
-			if !nsReady {
+			if !res.scaled {
 				stageReady = false
 				allReady = false
 			}
 
 			// Merge back
-			if group.Status.OriginalReplicas == nil {
-				group.Status.OriginalReplicas = make(map[string]int32)
-			}
-			// First clear old ones for this namespace to sync deletions from engine
-			for k := range group.Status.OriginalReplicas {
-				if strings.HasPrefix(k, nsKeyPrefix) {
-					delete(group.Status.OriginalReplicas, k)
+			if res.originals != nil {
+				nsKeyPrefix := ns + "/"
+				// First clear old ones for this namespace to sync deletions from engine
+				for k := range group.Status.OriginalReplicas {
+					if strings.HasPrefix(k, nsKeyPrefix) {
+						delete(group.Status.OriginalReplicas, k)
+					}
+				}
+				for k, v := range res.originals {
+					group.Status.OriginalReplicas[nsKeyPrefix+k] = v
 				}
-			}
-			for k, v := range updatedOriginals {
-				group.Status.OriginalReplicas[nsKeyPrefix+k] = v
 			}
 
-			// c. Check if namespace reached target phase
-			phase := r.Engine.ComputePhase(ctx, ns, targetActive)
-			if (targetActive && phase == "ScaledUp") || (!targetActive && phase == "ScaledDown") {
+			if res.reached {
 				namespacesReady++
 				readyNamespaces = append(readyNamespaces, ns)
 			} else {
 				stageReady = false
 				allReady = false
-				// Prevent duplicate appends if ScaleTarget also failed
-				found := false
-				for _, bNs := range blockingNamespaces {
-					if bNs == ns {
-						found = true
-						break
-					}
-				}
-				if !found {
+				if !slices.Contains(blockingNamespaces, ns) {
 					blockingNamespaces = append(blockingNamespaces, ns)
 				}
 			}
@@ -287,28 +245,25 @@ func (r *ScalingGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request
 
 		if !stageReady {
 			l.Info("Stage not ready, waiting before next stage", "stageIndex", i)
+			currentStage = i + 1
 			break // Stop at this stage, wait for next reconcile
 		}
 	}
 
-	if !allReady && len(blockingNamespaces) > 0 {
-		stageNumber := 0
-		for idx, stage := range stages {
-			// Find which stage the first blocking namespace belongs to
-			for _, sNs := range stage {
-				if sNs == blockingNamespaces[0] {
-					stageNumber = idx + 1
-					break
-				}
-			}
-			if stageNumber != 0 {
-				break
-			}
-		}
+	if !allReady && len(blockingNamespaces) > 0 && !freeze.Frozen {
+		// Blocking namespaces all belong to the stage the loop stopped at
+		stageNumber := currentStage
 
 		if timeoutPassed {
-			msg := fmt.Sprintf("Timeout exceeded 1 min. Strict sequence is still active. Waiting on Stage %d: %s", stageNumber, strings.Join(blockingNamespaces, ", "))
+			msg := fmt.Sprintf("Timeout exceeded %s. Strict sequence is still active. Waiting on Stage %d: %s", timeout, stageNumber, strings.Join(blockingNamespaces, ", "))
 			r.Recorder.Event(group, "Warning", "ScalingTimeout", msg)
+			r.Notifier.NotifyTimeout(PhaseNotification{
+				Kind:               "ScalingGroup",
+				Name:               group.Name,
+				Namespace:          group.Namespace,
+				NewPhase:           group.Status.Phase,
+				BlockingNamespaces: blockingNamespaces,
+			}, group.Status.LastAction.Time)
 		} else {
 			msg := fmt.Sprintf("Executing Stage %d. Waiting for targets in: %s", stageNumber, strings.Join(blockingNamespaces, ", "))
 			r.Recorder.Event(group, "Normal", "ScalingActive", msg)
@@ -320,10 +275,16 @@ func (r *ScalingGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	}
 
 	// 5. Update Status
+	group.Status.ManagedNamespaces = managedNamespaces
 	group.Status.ManagedCount = managedCount
 	group.Status.NamespacesReady = namespacesReady
 	group.Status.NamespacesTotal = namespacesTotal
 	group.Status.ReadyNamespaces = readyNamespaces
+	group.Status.CurrentStage = currentStage
+	group.Status.TotalStages = len(stages)
+	group.Status.BlockingNamespaces = blockingNamespaces
+	group.Status.ActionHistory = recordAction(group.Status.ActionHistory, r.Engine, targetActive, changes)
+	group.Status.NextTransition, group.Status.NextTransitionState = nextTransition(r.Engine, group.Spec.Schedules, group.Spec.Active, jitter)
 
 	newPhase := "ScaledUp"
 	if allReady {
@@ -344,9 +305,19 @@ func (r *ScalingGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		oldPhase := group.Status.Phase
 		group.Status.Phase = newPhase
 		group.Status.LastAction = metav1.Now()
+		trackScaleUp(&group.Status, oldPhase, newPhase, r.Engine.Now())
 
 		// Emit Event on Phase transition
 		r.Recorder.Eventf(group, "Normal", "PhaseTransition", "Group phase transitioned from %s to %s", oldPhase, newPhase)
+		r.Notifier.Notify(PhaseNotification{
+			Type:               NotificationPhaseTransition,
+			Kind:               "ScalingGroup",
+			Name:               group.Name,
+			Namespace:          group.Namespace,
+			OldPhase:           oldPhase,
+			NewPhase:           newPhase,
+			BlockingNamespaces: blockingNamespaces,
+		})
 	} else if group.Status.LastAction.IsZero() {
 		group.Status.LastAction = metav1.Now()
 	}
@@ -357,16 +328,170 @@ func (r *ScalingGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request
 
 	// Requeue faster if scaling is in progress
 	if !allReady {
-		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		return ctrl.Result{RequeueAfter: r.Requeue.inProgress()}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: r.Requeue.base()}, nil
+}
+
+// trackScaleUp times the scale-ups of a group on its phase transitions: the clock starts
+// when the group enters ScalingUp and stops when it reaches ScaledUp, updating the last and
+// rolling average durations. A scale-up interrupted by a scale-down is not counted.
+func trackScaleUp(status *finopsv1.ScalingGroupStatus, oldPhase, newPhase string, now time.Time) {
+	switch {
+	case newPhase == "ScalingUp" && oldPhase != "ScalingUp":
+		status.ScaleUpStartedAt = &metav1.Time{Time: now}
+	case newPhase == "ScaledUp" && status.ScaleUpStartedAt != nil:
+		seconds := int64(now.Sub(status.ScaleUpStartedAt.Time).Seconds())
+		status.ScaleUpStartedAt = nil
+		status.LastScaleUpSeconds = seconds
+		status.ScaleUpSamples = min(status.ScaleUpSamples+1, finopsv1.ScaleUpAverageWindow)
+		// Moving average, older scale-ups weigh less once the window is full
+		avg := float64(status.AvgScaleUpSeconds)
+		avg += (float64(seconds) - avg) / float64(status.ScaleUpSamples)
+		status.AvgScaleUpSeconds = int64(math.Round(avg))
+	case newPhase != "ScalingUp":
+		status.ScaleUpStartedAt = nil
+	}
+}
+
+// resolveNamespaces returns the namespaces listed by a group followed by those matching its
+// namespace selector, sorted by name. Terminating namespaces are left out of the selection.
+func (r *ScalingGroupReconciler) resolveNamespaces(ctx context.Context, group *finopsv1.ScalingGroup) ([]string, error) {
+	namespaces := slices.Clone(group.Spec.Namespaces)
+	if group.Spec.NamespaceSelector == nil {
+		return namespaces, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(group.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespaceSelector: %w", err)
+	}
+	list := &corev1.NamespaceList{}
+	if err := r.List(ctx, list, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
 	}
 
-	return ctrl.Result{RequeueAfter: time.Minute}, nil
+	var selected []string
+	for _, ns := range list.Items {
+		if ns.DeletionTimestamp.IsZero() && !slices.Contains(namespaces, ns.Name) {
+			selected = append(selected, ns.Name)
+		}
+	}
+	slices.Sort(selected)
+	return append(namespaces, selected...), nil
+}
+
+// maxConcurrentStageTargets bounds how many targets of a single stage scale at once
+const maxConcurrentStageTargets = 5
+
+// stageTargetResult is the outcome of scaling one target of a stage
+type stageTargetResult struct {
+	// skipped is set for external targets missing from the spec
+	skipped bool
+	// failed is set when the target could not be scaled or checked
+	failed bool
+	// scaled is false while ScaleTarget still has steps to perform
+	scaled bool
+	// reached is set once the target is in the desired phase
+	reached bool
+	// originals are the updated original replicas of a namespace, keyed without the namespace prefix
+	originals map[string]int32
+	// changes are the replica updates made in the namespace
+	changes []finopsv1.ReplicaChange
+}
+
+// scaleStageTarget scales a namespace or an "ext:" external target of a stage. It only
+// reads the group, so the targets of a stage can be scaled concurrently. While frozen the
+// target is only checked against the desired state.
+func (r *ScalingGroupReconciler) scaleStageTarget(ctx context.Context, group *finopsv1.ScalingGroup, ns string, configs []finopsv1.ScalingConfig, targetActive, timeoutPassed, frozen bool) stageTargetResult {
+	l := logf.FromContext(ctx)
+
+	// Handle External Targets embedded in the sequence
+	if strings.HasPrefix(ns, "ext:") {
+		extId := strings.TrimPrefix(ns, "ext:")
+		var extTarget *finopsv1.ExternalTarget
+		for i := range group.Spec.ExternalTargets {
+			if group.Spec.ExternalTargets[i].Identifier == extId {
+				extTarget = &group.Spec.ExternalTargets[i]
+				break
+			}
+		}
+
+		if extTarget == nil {
+			l.Info("External target in sequence not found in spec", "target", extId)
+			return stageTargetResult{skipped: true}
+		}
+
+		provider, ok := r.Engine.Providers[extTarget.Provider]
+		if !ok {
+			l.Error(fmt.Errorf("provider not found"), "cannot scale external target", "target", extTarget.Identifier)
+			return stageTargetResult{failed: true}
+		}
+
+		if !frozen {
+			if err := provider.Scale(ctx, *extTarget, targetActive); err != nil {
+				l.Error(err, "failed to scale external target", "target", extTarget.Identifier)
+				return stageTargetResult{failed: true}
+			}
+		}
+
+		isRdy, err := provider.IsReady(ctx, *extTarget, targetActive)
+		if err != nil {
+			l.Error(err, "failed to check readiness of external target", "target", extTarget.Identifier)
+			return stageTargetResult{failed: true}
+		}
+		return stageTargetResult{scaled: true, reached: isRdy}
+	}
+
+	// a. Use the individual ScalingConfig for exclusions and sequence inheritance
+	var exclusions scaling.Exclusions
+	var nsSequence []string
+	for _, cfg := range configs {
+		if cfg.Spec.TargetNamespace == ns {
+			exclusions = scaling.Exclusions{
+				Names:       cfg.Spec.Exclusions,
+				Conditional: cfg.Spec.ConditionalExclusions,
+			}
+			nsSequence = cfg.Spec.Sequence
+			l.Info("Found ScalingConfig for inheritance", "namespace", ns, "config", cfg.Name)
+			break
+		}
+	}
+
+	// b. Scale Target
+	nsKeyPrefix := ns + "/"
+	nsReplicas := make(map[string]int32)
+	for k, v := range group.Status.OriginalReplicas {
+		if strings.HasPrefix(k, nsKeyPrefix) {
+			nsReplicas[strings.TrimPrefix(k, nsKeyPrefix)] = v
+		}
+	}
+	if frozen {
+		phase := r.Engine.ComputePhase(ctx, ns, targetActive, group.Spec.ScaleKinds, group.Spec.ScaleReplicaSets, group.Spec.ScaleDownReplicaPercent, nsReplicas)
+		reached := (targetActive && phase == "ScaledUp") || (!targetActive && phase == "ScaledDown")
+		return stageTargetResult{scaled: reached, reached: reached}
+	}
+
+	updatedOriginals, nsReady, changes, err := r.Engine.ScaleTargetChanges(ctx, ns, targetActive, nsSequence, exclusions, group.Spec.ScaleKinds, group.Spec.ScaleReplicaSets, nsReplicas, group.Spec.ScaleDownReplicaPercent, group.Spec.ScaleUpStepPercent, timeoutPassed)
+	if err != nil {
+		l.Error(err, "failed to scale namespace", "namespace", ns)
+		return stageTargetResult{failed: true}
+	}
+
+	// c. Check if namespace reached target phase
+	phase := r.Engine.ComputePhase(ctx, ns, targetActive, group.Spec.ScaleKinds, group.Spec.ScaleReplicaSets, group.Spec.ScaleDownReplicaPercent, updatedOriginals)
+	return stageTargetResult{
+		scaled:    nsReady,
+		reached:   (targetActive && phase == "ScaledUp") || (!targetActive && phase == "ScaledDown"),
+		originals: updatedOriginals,
+		changes:   changes,
+	}
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ScalingGroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if r.Engine == nil {
-		r.Engine = &scaling.Engine{Client: r.Client}
+		r.Engine = &scaling.Engine{Client: r.Client, Recorder: mgr.GetEventRecorderFor("kubex-scaling"), Limiter: r.ScaleLimiter}
 	}
 	if r.Engine.Providers == nil {
 		r.Engine.Providers = make(map[string]scaling.ExternalProvider)
@@ -382,9 +507,39 @@ func (r *ScalingGroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 
 	r.Recorder = mgr.GetEventRecorderFor("scalinggroup-controller")
+	if r.APIReader == nil {
+		r.APIReader = mgr.GetAPIReader()
+	}
 
+	// Namespaces joining or leaving a selector change the managed set right away
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&finopsv1.ScalingGroup{}).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.groupsForNamespace), builder.WithPredicates(predicate.LabelChangedPredicate{})).
 		Named("scalinggroup").
-		Complete(r)
+		Complete(r.Stats.Wrap("scalinggroup", r))
+}
+
+// groupsForNamespace maps a namespace to the ScalingGroups whose selector matches it or that
+// managed it on their last reconcile.
+func (r *ScalingGroupReconciler) groupsForNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	groupList := &finopsv1.ScalingGroupList{}
+	if err := r.List(ctx, groupList); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list ScalingGroups for namespace", "namespace", obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, g := range groupList.Items {
+		if g.Spec.NamespaceSelector == nil {
+			continue
+		}
+		matches := false
+		if selector, err := metav1.LabelSelectorAsSelector(g.Spec.NamespaceSelector); err == nil {
+			matches = selector.Matches(labels.Set(obj.GetLabels()))
+		}
+		if matches || slices.Contains(g.Status.ManagedNamespaces, obj.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&g)})
+		}
+	}
+	return requests
 }
@@ -18,12 +18,18 @@ package controller
 
 import (
 	"context"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -102,3 +108,137 @@ var _ = Describe("ScalingGroup Controller", func() {
 		})
 	})
 })
+
+var _ = Describe("ScalingGroup namespace selector", func() {
+	It("should manage the listed namespaces and those matching the selector", func() {
+		ctx := context.Background()
+		namespace := func(name string, labels map[string]string) *corev1.Namespace {
+			return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+		}
+		group := &finopsv1.ScalingGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "kubex"},
+			Spec: finopsv1.ScalingGroupSpec{
+				Namespaces:        []string{"shop-gateway"},
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"solution": "shop"}},
+			},
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithStatusSubresource(group).WithObjects(
+			group,
+			namespace("shop-gateway", nil),
+			namespace("shop-orders", map[string]string{"solution": "shop"}),
+			namespace("shop-cart", map[string]string{"solution": "shop"}),
+			namespace("billing", map[string]string{"solution": "billing"}),
+		).Build()
+
+		reconciler := &ScalingGroupReconciler{
+			Client:   fakeClient,
+			Scheme:   fakeClient.Scheme(),
+			Engine:   &scaling.Engine{Client: fakeClient},
+			Recorder: record.NewFakeRecorder(100),
+		}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(group)})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(group), group)).To(Succeed())
+		Expect(group.Status.ManagedNamespaces).To(Equal([]string{"shop-gateway", "shop-cart", "shop-orders"}))
+		Expect(group.Status.NamespacesTotal).To(Equal(3))
+
+		By("mapping namespaces that join or leave the selector to the group")
+		Expect(reconciler.groupsForNamespace(ctx, namespace("shop-search", map[string]string{"solution": "shop"}))).To(HaveLen(1))
+		Expect(reconciler.groupsForNamespace(ctx, namespace("shop-cart", nil))).To(HaveLen(1))
+		Expect(reconciler.groupsForNamespace(ctx, namespace("billing", map[string]string{"solution": "billing"}))).To(BeEmpty())
+	})
+})
+
+var _ = Describe("ScalingGroup stage progress", func() {
+	It("should report the stage being executed and what it waits for", func() {
+		ctx := context.Background()
+		active := true
+		replicas := int32(2)
+		group := &finopsv1.ScalingGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "kubex"},
+			Spec: finopsv1.ScalingGroupSpec{
+				Namespaces: []string{"db", "api", "web"},
+				Sequence:   []string{"db", "api web"},
+				Active:     &active,
+			},
+		}
+		db := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "db"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithStatusSubresource(group, db).WithObjects(group, db).Build()
+
+		reconciler := &ScalingGroupReconciler{
+			Client:   fakeClient,
+			Scheme:   fakeClient.Scheme(),
+			Engine:   &scaling.Engine{Client: fakeClient},
+			Recorder: record.NewFakeRecorder(100),
+		}
+		key := client.ObjectKeyFromObject(group)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeClient.Get(ctx, key, group)).To(Succeed())
+		Expect(group.Status.CurrentStage).To(Equal(1))
+		Expect(group.Status.TotalStages).To(Equal(2))
+		Expect(group.Status.BlockingNamespaces).To(Equal([]string{"db"}))
+
+		By("moving to the last stage once the database is ready")
+		db.Status.Replicas = replicas
+		db.Status.ReadyReplicas = replicas
+		db.Status.AvailableReplicas = replicas
+		db.Status.UpdatedReplicas = replicas
+		Expect(fakeClient.Status().Update(ctx, db)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeClient.Get(ctx, key, group)).To(Succeed())
+		Expect(group.Status.CurrentStage).To(Equal(2))
+		Expect(group.Status.TotalStages).To(Equal(2))
+		Expect(group.Status.BlockingNamespaces).To(BeEmpty())
+	})
+})
+
+var _ = Describe("trackScaleUp", func() {
+	start := time.Date(2025, 1, 6, 7, 0, 0, 0, time.UTC)
+
+	It("should time scale-ups from ScalingUp to ScaledUp", func() {
+		status := &finopsv1.ScalingGroupStatus{}
+		trackScaleUp(status, "ScaledDown", "ScalingUp", start)
+		Expect(status.ScaleUpStartedAt.Time).To(Equal(start))
+
+		trackScaleUp(status, "ScalingUp", "ScaledUp", start.Add(90*time.Second))
+		Expect(status.ScaleUpStartedAt).To(BeNil())
+		Expect(status.LastScaleUpSeconds).To(Equal(int64(90)))
+		Expect(status.AvgScaleUpSeconds).To(Equal(int64(90)))
+
+		trackScaleUp(status, "ScaledDown", "ScalingUp", start)
+		trackScaleUp(status, "ScalingUp", "ScaledUp", start.Add(30*time.Second))
+		Expect(status.LastScaleUpSeconds).To(Equal(int64(30)))
+		Expect(status.AvgScaleUpSeconds).To(Equal(int64(60)))
+		Expect(status.ScaleUpSamples).To(Equal(int32(2)))
+	})
+
+	It("should not count interrupted scale-ups", func() {
+		status := &finopsv1.ScalingGroupStatus{}
+		trackScaleUp(status, "ScaledDown", "ScalingUp", start)
+		trackScaleUp(status, "ScalingUp", "ScalingDown", start.Add(time.Minute))
+		Expect(status.ScaleUpStartedAt).To(BeNil())
+
+		trackScaleUp(status, "ScalingDown", "ScaledUp", start.Add(2*time.Minute))
+		Expect(status.ScaleUpSamples).To(BeZero())
+	})
+
+	It("should average over the last scale-ups only", func() {
+		status := &finopsv1.ScalingGroupStatus{}
+		for range finopsv1.ScaleUpAverageWindow {
+			trackScaleUp(status, "ScaledDown", "ScalingUp", start)
+			trackScaleUp(status, "ScalingUp", "ScaledUp", start.Add(100*time.Second))
+		}
+		trackScaleUp(status, "ScaledDown", "ScalingUp", start)
+		trackScaleUp(status, "ScalingUp", "ScaledUp", start.Add(200*time.Second))
+		Expect(status.ScaleUpSamples).To(Equal(int32(finopsv1.ScaleUpAverageWindow)))
+		Expect(status.AvgScaleUpSeconds).To(Equal(int64(110)))
+	})
+})
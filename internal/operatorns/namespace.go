@@ -0,0 +1,26 @@
+// Package operatorns resolves the namespace the operator keeps its custom resources in.
+package operatorns
+
+import "os"
+
+// DefaultNamespace is used when neither environment variable is set, e.g. when running
+// the operator locally outside a pod
+const DefaultNamespace = "kubex"
+
+// Namespace returns KUBEX_OPERATOR_NAMESPACE when set, otherwise the POD_NAMESPACE injected
+// by the Helm chart, falling back to DefaultNamespace.
+func Namespace() string {
+	ns, _ := Resolve()
+	return ns
+}
+
+// Resolve is Namespace, also reporting whether DefaultNamespace was used as a fallback.
+func Resolve() (ns string, fallback bool) {
+	if ns := os.Getenv("KUBEX_OPERATOR_NAMESPACE"); ns != "" {
+		return ns, false
+	}
+	if ns := os.Getenv("POD_NAMESPACE"); ns != "" {
+		return ns, false
+	}
+	return DefaultNamespace, true
+}
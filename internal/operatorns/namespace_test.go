@@ -0,0 +1,28 @@
+package operatorns
+
+import "testing"
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name         string
+		override     string
+		podNamespace string
+		expected     string
+		fallback     bool
+	}{
+		{"override wins", "kubex-system", "other", "kubex-system", false},
+		{"pod namespace", "", "kubex-system", "kubex-system", false},
+		{"fallback", "", "", DefaultNamespace, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("KUBEX_OPERATOR_NAMESPACE", tt.override)
+			t.Setenv("POD_NAMESPACE", tt.podNamespace)
+			ns, fallback := Resolve()
+			if ns != tt.expected || fallback != tt.fallback {
+				t.Errorf("Resolve() = %q, %v; want %q, %v", ns, fallback, tt.expected, tt.fallback)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+// Package podqos computes the Kubernetes quality of service class of a pod.
+package podqos
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Class returns the QoS class the kubelet reported for the pod, computing it from the
+// container resources like the kubelet does when the status isn't set yet (e.g. pending pods)
+func Class(pod *corev1.Pod) corev1.PodQOSClass {
+	if pod.Status.QOSClass != "" {
+		return pod.Status.QOSClass
+	}
+
+	containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	set, guaranteed := false, true
+	for _, c := range containers {
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			req, hasReq := c.Resources.Requests[name]
+			lim, hasLim := c.Resources.Limits[name]
+			hasReq = hasReq && !req.IsZero()
+			hasLim = hasLim && !lim.IsZero()
+			if hasReq || hasLim {
+				set = true
+			}
+			// A request defaults to its limit, so only a limit matching the request is guaranteed
+			if !hasLim || (hasReq && req.Cmp(lim) != 0) {
+				guaranteed = false
+			}
+		}
+	}
+
+	switch {
+	case !set:
+		return corev1.PodQOSBestEffort
+	case guaranteed:
+		return corev1.PodQOSGuaranteed
+	default:
+		return corev1.PodQOSBurstable
+	}
+}
@@ -0,0 +1,67 @@
+package podqos
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestClass(t *testing.T) {
+	list := func(cpu, mem string) corev1.ResourceList {
+		l := corev1.ResourceList{}
+		if cpu != "" {
+			l[corev1.ResourceCPU] = resource.MustParse(cpu)
+		}
+		if mem != "" {
+			l[corev1.ResourceMemory] = resource.MustParse(mem)
+		}
+		return l
+	}
+	container := func(req, lim corev1.ResourceList) corev1.Container {
+		return corev1.Container{Name: "app", Resources: corev1.ResourceRequirements{Requests: req, Limits: lim}}
+	}
+
+	tests := []struct {
+		name     string
+		pod      corev1.Pod
+		expected corev1.PodQOSClass
+	}{
+		{"reported by the kubelet", corev1.Pod{
+			Spec:   corev1.PodSpec{Containers: []corev1.Container{container(nil, nil)}},
+			Status: corev1.PodStatus{QOSClass: corev1.PodQOSGuaranteed},
+		}, corev1.PodQOSGuaranteed},
+		{"no resources", corev1.Pod{
+			Spec: corev1.PodSpec{Containers: []corev1.Container{container(nil, nil), container(list("", ""), nil)}},
+		}, corev1.PodQOSBestEffort},
+		{"limits equal requests", corev1.Pod{
+			Spec: corev1.PodSpec{Containers: []corev1.Container{container(list("500m", "256Mi"), list("0.5", "256Mi"))}},
+		}, corev1.PodQOSGuaranteed},
+		{"limits only", corev1.Pod{
+			Spec: corev1.PodSpec{Containers: []corev1.Container{container(nil, list("1", "1Gi"))}},
+		}, corev1.PodQOSGuaranteed},
+		{"requests below limits", corev1.Pod{
+			Spec: corev1.PodSpec{Containers: []corev1.Container{container(list("100m", "256Mi"), list("1", "256Mi"))}},
+		}, corev1.PodQOSBurstable},
+		{"one container without limits", corev1.Pod{
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				container(list("1", "1Gi"), list("1", "1Gi")),
+				container(list("100m", ""), nil),
+			}},
+		}, corev1.PodQOSBurstable},
+		{"init container requests", corev1.Pod{
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{container(list("100m", ""), nil)},
+				Containers:     []corev1.Container{container(nil, nil)},
+			},
+		}, corev1.PodQOSBurstable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Class(&tt.pod); got != tt.expected {
+				t.Errorf("Class() = %s; want %s", got, tt.expected)
+			}
+		})
+	}
+}
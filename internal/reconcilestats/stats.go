@@ -0,0 +1,108 @@
+// Package reconcilestats aggregates the reconciles of each controller for the API.
+package reconcilestats
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// lastReconcile complements the reconcile duration and error metrics controller-runtime
+// already exports (controller_runtime_reconcile_time_seconds and
+// controller_runtime_reconcile_errors_total), which do not tell when a controller last ran.
+var lastReconcile = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "kubex_reconcile_last_timestamp_seconds",
+	Help: "Unix time at which the controller last finished a reconcile",
+}, []string{"controller"})
+
+func init() {
+	metrics.Registry.MustRegister(lastReconcile)
+}
+
+// Stats are the reconciles of one controller since the operator started
+type Stats struct {
+	Controller string `json:"controller"`
+	Reconciles int64  `json:"reconciles"`
+	Errors     int64  `json:"errors"`
+	// LastReconcile is when the last reconcile finished, unset until the first one
+	LastReconcile     *time.Time `json:"lastReconcile,omitempty"`
+	LastError         string     `json:"lastError,omitempty"`
+	LastDurationMs    float64    `json:"lastDurationMs"`
+	AverageDurationMs float64    `json:"averageDurationMs"`
+	MaxDurationMs     float64    `json:"maxDurationMs"`
+}
+
+// Recorder collects the Stats of the reconcilers it wraps. A nil Recorder records nothing.
+type Recorder struct {
+	mu    sync.Mutex
+	stats map[string]*Stats
+	total map[string]time.Duration
+}
+
+// NewRecorder returns an empty Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{stats: map[string]*Stats{}, total: map[string]time.Duration{}}
+}
+
+// Wrap returns a reconciler recording the duration and outcome of every call to rec under
+// the given controller name.
+func (r *Recorder) Wrap(controller string, rec reconcile.Reconciler) reconcile.Reconciler {
+	if r == nil {
+		return rec
+	}
+	r.mu.Lock()
+	if _, ok := r.stats[controller]; !ok {
+		r.stats[controller] = &Stats{Controller: controller}
+	}
+	r.mu.Unlock()
+
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		start := time.Now()
+		res, err := rec.Reconcile(ctx, req)
+		r.observe(controller, start, time.Since(start), err)
+		return res, err
+	})
+}
+
+func (r *Recorder) observe(controller string, start time.Time, d time.Duration, err error) {
+	end := start.Add(d)
+	lastReconcile.WithLabelValues(controller).Set(float64(end.UnixNano()) / 1e9)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.stats[controller]
+	s.Reconciles++
+	s.LastReconcile = &end
+	if err != nil {
+		s.Errors++
+		s.LastError = err.Error()
+	}
+	r.total[controller] += d
+	s.LastDurationMs = milliseconds(d)
+	s.AverageDurationMs = milliseconds(r.total[controller] / time.Duration(s.Reconciles))
+	s.MaxDurationMs = max(s.MaxDurationMs, s.LastDurationMs)
+}
+
+// Snapshot returns a copy of the Stats of every wrapped controller, sorted by name
+func (r *Recorder) Snapshot() []Stats {
+	result := []Stats{}
+	if r == nil {
+		return result
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.stats {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Controller < result[j].Controller })
+	return result
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,61 @@
+package reconcilestats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestRecorder(t *testing.T) {
+	recorder := NewRecorder()
+	fail := false
+	rec := recorder.Wrap("scalinggroup", reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+		time.Sleep(2 * time.Millisecond)
+		if fail {
+			return reconcile.Result{}, errors.New("conflict")
+		}
+		return reconcile.Result{RequeueAfter: time.Minute}, nil
+	}))
+	recorder.Wrap("namespacefinops", reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+		return reconcile.Result{}, nil
+	}))
+
+	if res, err := rec.Reconcile(context.Background(), reconcile.Request{}); err != nil || res.RequeueAfter != time.Minute {
+		t.Fatalf("expected the wrapped result to be returned, got %v, %v", res, err)
+	}
+	fail = true
+	if _, err := rec.Reconcile(context.Background(), reconcile.Request{}); err == nil {
+		t.Fatal("expected the wrapped error to be returned")
+	}
+
+	stats := recorder.Snapshot()
+	if len(stats) != 2 || stats[0].Controller != "namespacefinops" || stats[1].Controller != "scalinggroup" {
+		t.Fatalf("expected both controllers sorted by name, got %+v", stats)
+	}
+	if stats[0].Reconciles != 0 || stats[0].LastReconcile != nil {
+		t.Errorf("expected no reconciles for namespacefinops, got %+v", stats[0])
+	}
+	s := stats[1]
+	if s.Reconciles != 2 || s.Errors != 1 || s.LastError != "conflict" || s.LastReconcile == nil {
+		t.Errorf("unexpected scalinggroup stats %+v", s)
+	}
+	if s.AverageDurationMs < 2 || s.MaxDurationMs < s.AverageDurationMs {
+		t.Errorf("expected durations of at least 2ms, got %+v", s)
+	}
+}
+
+func TestNilRecorder(t *testing.T) {
+	var recorder *Recorder
+	rec := reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+		return reconcile.Result{}, nil
+	})
+	if _, err := recorder.Wrap("scalinggroup", rec).Reconcile(context.Background(), reconcile.Request{}); err != nil {
+		t.Fatal(err)
+	}
+	if stats := recorder.Snapshot(); stats == nil || len(stats) != 0 {
+		t.Errorf("expected an empty snapshot, got %v", stats)
+	}
+}
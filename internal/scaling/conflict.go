@@ -0,0 +1,30 @@
+package scaling
+
+import (
+	"slices"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+)
+
+// ManagingGroup returns the ScalingGroup that takes over a namespace from its individual
+// ScalingConfig ("Group Wins"), or nil when no group lists it. When several groups list the
+// namespace the first by name wins, so the outcome does not depend on list order.
+func ManagingGroup(groups []finopsv1.ScalingGroup, ns string) *finopsv1.ScalingGroup {
+	var managing *finopsv1.ScalingGroup
+	for i := range groups {
+		g := &groups[i]
+		if !GroupManages(g, ns) {
+			continue
+		}
+		if managing == nil || g.Name < managing.Name {
+			managing = g
+		}
+	}
+	return managing
+}
+
+// GroupManages reports whether a ScalingGroup manages a namespace, either listed explicitly
+// or matched by its namespace selector on the last reconcile.
+func GroupManages(g *finopsv1.ScalingGroup, ns string) bool {
+	return slices.Contains(g.Spec.Namespaces, ns) || slices.Contains(g.Status.ManagedNamespaces, ns)
+}
@@ -2,21 +2,77 @@ package scaling
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+// MinReplicasAnnotation sets the minimum replica count a workload is restored to on scale-up.
+// It protects critical services when the recorded original replicas were lost.
+const MinReplicasAnnotation = "finops.kubex.io/min-replicas"
+
+// ManagedAnnotation set to "false" opts a workload out of all Kubex management: it is never
+// scaled nor optimized, whatever the groups and configs targeting its namespace.
+const ManagedAnnotation = "finops.kubex.io/managed"
+
+// OptedOutReason is the reason of the events telling that a workload was left alone because
+// of ManagedAnnotation
+const OptedOutReason = "OptedOut"
+
+// Unmanaged reports whether obj opted out of Kubex management through ManagedAnnotation
+func Unmanaged(obj client.Object) bool {
+	return obj.GetAnnotations()[ManagedAnnotation] == "false"
+}
+
 type Engine struct {
 	Client    client.Client
 	Providers map[string]ExternalProvider
+	// Recorder receives warnings about individual workloads, may be nil
+	Recorder record.EventRecorder
+	// Clock tells the time schedules and timeouts are evaluated at, the real clock when nil
+	Clock Clock
+	// Limiter caps the replica updates in flight, shared by the engines of all reconcilers.
+	// Unlimited when nil.
+	Limiter *Limiter
+}
+
+// Clock returns the current time, tests replace it to freeze time
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// Now returns the current time of the engine clock
+func (e *Engine) Now() time.Time {
+	if e.Clock == nil {
+		return realClock{}.Now()
+	}
+	return e.Clock.Now()
+}
+
+// StageTimedOut reports whether more than timeout passed since lastAction, after which a
+// blocking stage is bypassed.
+func (e *Engine) StageTimedOut(lastAction time.Time, timeout time.Duration) bool {
+	return e.Now().Sub(lastAction) > timeout
 }
 
 // ExternalProvider defines the interface for 3rd party cloud service scaling
@@ -33,6 +89,11 @@ type ExternalProvider interface {
 
 // IsActive checks if the namespace/group should be active based on schedules and manual override.
 func (e *Engine) IsActive(schedules []finopsv1.ScalingSchedule, manualActive *bool) bool {
+	return e.IsActiveAt(schedules, manualActive, e.Now())
+}
+
+// IsActiveAt evaluates the schedules and manual override at the given time.
+func (e *Engine) IsActiveAt(schedules []finopsv1.ScalingSchedule, manualActive *bool, at time.Time) bool {
 	// 1. Manual override takes priority if explicitly set (non-nil)
 	if manualActive != nil {
 		return *manualActive
@@ -47,7 +108,7 @@ func (e *Engine) IsActive(schedules []finopsv1.ScalingSchedule, manualActive *bo
 			}
 			hasValidSchedule = true
 
-			now := time.Now()
+			now := at
 			if s.Timezone != "" {
 				loc, err := time.LoadLocation(s.Timezone)
 				if err == nil {
@@ -58,19 +119,22 @@ func (e *Engine) IsActive(schedules []finopsv1.ScalingSchedule, manualActive *bo
 			weekday := int(now.Weekday())
 			nowMinutes := now.Hour()*60 + now.Minute()
 
-			matchesDay := false
-			for _, d := range s.Days {
-				if d == weekday {
-					matchesDay = true
-					break
+			startMin := parseMinutes(s.StartTime)
+			endMin := parseMinutes(s.EndTime)
+
+			if s.Overnight && endMin < startMin {
+				// The window started today or is the tail of yesterday's window
+				yesterday := (weekday + 6) % 7
+				if (nowMinutes >= startMin && slices.Contains(s.Days, weekday)) ||
+					(nowMinutes <= endMin && slices.Contains(s.Days, yesterday)) {
+					return true
 				}
-			}
-			if !matchesDay {
 				continue
 			}
 
-			startMin := parseMinutes(s.StartTime)
-			endMin := parseMinutes(s.EndTime)
+			if !slices.Contains(s.Days, weekday) {
+				continue
+			}
 
 			if nowMinutes >= startMin && nowMinutes <= endMin {
 				return true
@@ -91,36 +155,81 @@ func parseMinutes(hhmm string) int {
 	return h*60 + m
 }
 
+// Exclusions selects the workloads ScaleTarget leaves untouched.
+type Exclusions struct {
+	// Names are never scaled, in either direction
+	Names []string
+	// Conditional are not scaled down while their schedule is active
+	Conditional []finopsv1.ConditionalExclusion
+}
+
+// excludes reports whether the workload called name is skipped when scaling towards active.
+func (e *Engine) excludes(x Exclusions, name string, active bool) bool {
+	if isExcluded(name, x.Names) {
+		return true
+	}
+	if active {
+		return false
+	}
+	for _, c := range x.Conditional {
+		if len(c.Schedules) > 0 && isExcluded(name, c.Names) && e.IsActive(c.Schedules, nil) {
+			return true
+		}
+	}
+	return false
+}
+
 // ScaleTarget handles scaling for a specific namespace.
 // It returns the updated map of original replicas and a boolean indicating if target state is fully reached.
-func (e *Engine) ScaleTarget(ctx context.Context, ns string, active bool, sequence []string, exclusions []string, originalReplicas map[string]int32, timeoutPassed bool) (map[string]int32, bool, error) {
+// scaleKinds lists additional kinds ("group/version:Kind") scaled through their scale subresource.
+// downPercent keeps that percentage of the original replicas running when scaling down, 0 scales to zero.
+// replicaSets also scales the ReplicaSets no Deployment or other controller owns.
+// downPercent keeps that percentage of the original replicas running when scaling down, 0 scales to zero.
+// upStepPercent ramps workloads back up in steps of that percentage of their original replicas, 0 restores them at once.
+func (e *Engine) ScaleTarget(ctx context.Context, ns string, active bool, sequence []string, exclusions Exclusions, scaleKinds []string, replicaSets bool, originalReplicas map[string]int32, downPercent, upStepPercent int32, timeoutPassed bool) (map[string]int32, bool, error) {
+	originals, ready, _, err := e.ScaleTargetChanges(ctx, ns, active, sequence, exclusions, scaleKinds, replicaSets, originalReplicas, downPercent, upStepPercent, timeoutPassed)
+	return originals, ready, err
+}
+
+// ScaleTargetChanges is ScaleTarget also returning the replica updates it attempted, failed
+// ones carrying their error.
+func (e *Engine) ScaleTargetChanges(ctx context.Context, ns string, active bool, sequence []string, exclusions Exclusions, scaleKinds []string, replicaSets bool, originalReplicas map[string]int32, downPercent, upStepPercent int32, timeoutPassed bool) (map[string]int32, bool, []finopsv1.ReplicaChange, error) {
 	l := log.FromContext(ctx).WithValues("namespace", ns, "targetActive", active)
+	var changes []finopsv1.ReplicaChange
 
 	if originalReplicas == nil {
 		originalReplicas = make(map[string]int32)
 	}
+	down := downTargets{percent: downPercent, originals: originalReplicas}
 
 	// 1. List all scalable resources in the namespace
-	deployments := &appsv1.DeploymentList{}
-	if err := e.Client.List(ctx, deployments, client.InNamespace(ns)); err != nil {
-		return nil, false, err
+	workloads, err := e.listWorkloads(ctx, ns, scaleKinds, replicaSets)
+	if err != nil {
+		return nil, false, nil, err
 	}
 
-	statefulSets := &appsv1.StatefulSetList{}
-	if err := e.Client.List(ctx, statefulSets, client.InNamespace(ns)); err != nil {
-		return nil, false, err
+	hpas, err := e.listHPAs(ctx, ns)
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	pdbs, err := e.listPDBs(ctx, ns)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	if active && upStepPercent > 0 {
+		down.ramp = rampTargets(workloads, hpas, originalReplicas)
 	}
 
 	// 2. Filter exclusions
 	scalableResources := []client.Object{}
-	for i := range deployments.Items {
-		if !isExcluded(deployments.Items[i].Name, exclusions) {
-			scalableResources = append(scalableResources, &deployments.Items[i])
+	for _, obj := range workloads {
+		if Unmanaged(obj) {
+			e.recordOptOut(ctx, obj, active)
+			continue
 		}
-	}
-	for i := range statefulSets.Items {
-		if !isExcluded(statefulSets.Items[i].Name, exclusions) {
-			scalableResources = append(scalableResources, &statefulSets.Items[i])
+		if !e.excludes(exclusions, obj.GetName(), active) {
+			scalableResources = append(scalableResources, obj)
 		}
 	}
 
@@ -151,68 +260,146 @@ func (e *Engine) ScaleTarget(ctx context.Context, ns string, active bool, sequen
 
 		// First, check if this priority group is ALREADY ready.
 		// If so, we move to the next.
-		if e.isGroupReady(ctx, objs, active) {
+		if e.isGroupReady(ctx, objs, active, down) {
+			if active {
+				// Scaled up in an earlier reconcile, the originals are no longer needed
+				for _, obj := range objs {
+					delete(originalReplicas, replicaKey(obj))
+				}
+			}
 			continue
 		}
 
 		// Group is not ready. Act on it.
 		l.Info("Scaling priority group", "priority", p, "count", len(objs))
+		changed := false
 		for _, obj := range objs {
-			key := fmt.Sprintf("%T/%s", obj, obj.GetName())
+			key := replicaKey(obj)
 
 			// Target replicas for this object
 			var target int32
-			current := getReplicas(obj)
+			current, err := e.getReplicas(ctx, obj)
+			if err != nil {
+				l.Error(err, "failed to read replicas", "resource", key)
+				continue
+			}
+			hpa := hpas[workloadRef(obj)]
+			pdb := matchingPDB(obj, pdbs)
 
 			if !active {
-				target = 0
+				// Never scale up a workload that already runs below its partial target
+				target = min(down.target(obj, current), current)
+				if pdb != nil {
+					// Step down one replica per reconcile instead of dropping at once
+					target = max(target, gradualScaleDownTarget(obj, current))
+				}
 			} else {
-				if current > 0 {
+				if final, ok := down.ramp[key]; ok && current < final {
+					// Add one step, the readiness of the group holds back the next one
+					target = stepUpTarget(obj, current, final, upStepPercent)
+				} else if down.restoring(obj, current) {
+					// Bring a partially scaled-down workload back to its recorded count
+					target = originalReplicas[key]
+				} else if current > 0 {
 					// Respect manual or HPA scaling that occurred during active state.
 					target = current
+				} else if hpa != nil {
+					// Hand control back to the HPA instead of forcing the recorded count
+					target = hpaMinReplicas(hpa)
 				} else {
-					if t, ok := originalReplicas[key]; ok {
+					floor := minReplicas(obj)
+					if t, ok := originalReplicas[key]; ok && t >= floor {
 						target = t
 					} else {
 						// Fallback if no record of original replicas
-						target = 1
+						target = floor
 					}
 				}
 			}
 			if current != target {
 				// Record original IF scaling down for the first time
 				if !active && current > 0 {
-					originalReplicas[key] = current
+					// A gradual scale-down passes through intermediate counts, keep the first one.
+					// So does an interrupted stepped scale-up, keep the count it was heading to.
+					if original, recorded := originalReplicas[key]; !recorded || (pdb == nil && original <= current) {
+						originalReplicas[key] = current
+					}
+					if pdb != nil && pdb.Status.DisruptionsAllowed < current-target && e.Recorder != nil {
+						e.Recorder.Eventf(obj, corev1.EventTypeWarning, "PodDisruptionBudgetViolation",
+							"Scaling from %d to %d replicas exceeds the %d disruptions allowed by PodDisruptionBudget %s",
+							current, target, pdb.Status.DisruptionsAllowed, pdb.Name)
+					}
+					if hpa != nil {
+						if err := e.disableHPA(ctx, hpa); err != nil {
+							l.Error(err, "failed to mark HPA as disabled", "hpa", hpa.Name)
+						}
+					}
 				}
 
 				l.Info("Setting replicas", "resource", key, "from", current, "to", target)
-				if err := e.setReplicas(ctx, obj, target); err != nil {
+				err := e.setReplicas(ctx, obj, target)
+				if errors.Is(err, ErrScaleThrottled) {
+					// Leave the rest to the next reconcile, which requeues shortly while not ready
+					l.Info("Too many scale operations in flight, retrying later", "resource", key)
+					return originalReplicas, false, changes, nil
+				}
+				change := finopsv1.ReplicaChange{Namespace: ns, Kind: workloadGVK(obj).Kind, Name: obj.GetName(), From: current, To: target}
+				if err != nil {
 					l.Error(err, "failed to update replicas", "resource", key, "target", target)
+					change.Error = err.Error()
+					changes = append(changes, change)
+					continue
+				}
+				changes = append(changes, change)
+				changed = true
+			}
+
+			if active && hpa != nil {
+				if err := e.enableHPA(ctx, hpa); err != nil {
+					l.Error(err, "failed to re-enable HPA", "hpa", hpa.Name)
 				}
 			}
 		}
 
-		// After acting, check if it reached readiness.
+		// After acting, check if it reached readiness. Nothing to re-check when no replica
+		// count changed, the group was found not ready above.
 		// If not, we return false and stop here (strict sequencing).
-		if !e.isGroupReady(ctx, objs, active) {
+		ready := changed && e.isGroupReady(ctx, objs, active, down)
+		if !ready {
 			if timeoutPassed {
-				l.Info("Priority group not yet ready, but 1-minute timeout passed! Bypassing strict sequence for this group.", "priority", p)
+				l.Info("Priority group not yet ready, but the stage timeout passed! Bypassing strict sequence for this group.", "priority", p)
 			} else {
 				l.Info("Priority group not yet ready, stopping for now", "priority", p)
-				return originalReplicas, false, nil
+				return originalReplicas, false, changes, nil
 			}
 		}
 
 		// If scaling UP, we can now safely remove from originals IF they are ready.
-		if active && e.isGroupReady(ctx, objs, active) {
+		if active && ready {
 			for _, obj := range objs {
-				key := fmt.Sprintf("%T/%s", obj, obj.GetName())
-				delete(originalReplicas, key)
+				delete(originalReplicas, replicaKey(obj))
 			}
 		}
 	}
 
-	return originalReplicas, true, nil
+	return originalReplicas, true, changes, nil
+}
+
+// recordOptOut tells through an event that an unmanaged workload was not scaled. Workloads
+// already in the target state are not reported, so settled namespaces stay quiet.
+func (e *Engine) recordOptOut(ctx context.Context, obj client.Object, active bool) {
+	if e.Recorder == nil {
+		return
+	}
+	current, err := e.getReplicas(ctx, obj)
+	if err != nil || active == (current > 0) {
+		return
+	}
+	direction := "down"
+	if active {
+		direction = "up"
+	}
+	e.Recorder.Eventf(obj, corev1.EventTypeNormal, OptedOutReason, "Not scaled %s, %s is set to false", direction, ManagedAnnotation)
 }
 
 func isExcluded(name string, exclusions []string) bool {
@@ -237,40 +424,117 @@ func isExcluded(name string, exclusions []string) bool {
 	return false
 }
 
+// getSequenceIndex returns the index of the first sequence entry matching obj. Entries use
+// the "Group/Version:Kind/Name" format; "Kind/Name" and a bare name match regardless of the
+// group or kind respectively. The name portion accepts globs such as "db-*".
 func getSequenceIndex(obj client.Object, sequence []string) int {
-	name := obj.GetName()
+	gvk := workloadGVK(obj)
 	for i, s := range sequence {
-		if s == "*" {
+		if sequenceEntryMatches(strings.TrimSpace(s), gvk, obj.GetName()) {
 			return i
 		}
-		if strings.HasSuffix(s, "*") {
-			if strings.HasPrefix(name, strings.TrimSuffix(s, "*")) {
-				return i
-			}
+	}
+	return 999 // Parallel at the end/start
+}
+
+func sequenceEntryMatches(entry string, gvk schema.GroupVersionKind, name string) bool {
+	if gv, rest, ok := strings.Cut(entry, ":"); ok {
+		parsed, err := schema.ParseGroupVersion(gv)
+		if err != nil || parsed.Group != gvk.Group {
+			return false
 		}
-		if strings.Contains(s, name) {
-			return i
+		entry = rest
+		if !strings.Contains(entry, "/") {
+			return false
 		}
 	}
-	return 999 // Parallel at the end/start
+	if kind, pattern, ok := strings.Cut(entry, "/"); ok {
+		if !strings.EqualFold(kind, gvk.Kind) {
+			return false
+		}
+		entry = pattern
+	}
+	matched, err := path.Match(entry, name)
+	return err == nil && matched
+}
+
+// workloadGVK returns the group, version and kind of a workload. Typed objects read from
+// the client carry no TypeMeta, so their kind comes from the Go type.
+func workloadGVK(obj client.Object) schema.GroupVersionKind {
+	switch obj.(type) {
+	case *appsv1.Deployment:
+		return appsv1.SchemeGroupVersion.WithKind("Deployment")
+	case *appsv1.StatefulSet:
+		return appsv1.SchemeGroupVersion.WithKind("StatefulSet")
+	case *appsv1.ReplicaSet:
+		return appsv1.SchemeGroupVersion.WithKind("ReplicaSet")
+	}
+	return obj.GetObjectKind().GroupVersionKind()
+}
+
+// minReplicas reads MinReplicasAnnotation, defaulting to 1 when unset or invalid.
+func minReplicas(obj client.Object) int32 {
+	v, ok := obj.GetAnnotations()[MinReplicasAnnotation]
+	if !ok {
+		return 1
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 32)
+	if err != nil || n < 1 {
+		return 1
+	}
+	return int32(n)
+}
+
+// replicaKey identifies a workload in the recorded original replicas.
+func replicaKey(obj client.Object) string {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		gvk := u.GroupVersionKind()
+		return fmt.Sprintf("%s.%s/%s", gvk.Kind, gvk.Group, u.GetName())
+	}
+	return fmt.Sprintf("%T/%s", obj, obj.GetName())
 }
 
-func getReplicas(obj client.Object) int32 {
+func (e *Engine) getReplicas(ctx context.Context, obj client.Object) (int32, error) {
 	switch v := obj.(type) {
 	case *appsv1.Deployment:
-		return *v.Spec.Replicas
+		return replicasOrDefault(v.Spec.Replicas), nil
 	case *appsv1.StatefulSet:
-		return *v.Spec.Replicas
+		return replicasOrDefault(v.Spec.Replicas), nil
+	case *appsv1.ReplicaSet:
+		return replicasOrDefault(v.Spec.Replicas), nil
+	case *unstructured.Unstructured:
+		scale, err := e.getScale(ctx, v)
+		if err != nil {
+			return 0, err
+		}
+		return scale.Spec.Replicas, nil
+	}
+	return 0, nil
+}
+
+// replicasOrDefault treats an unset replica count as 1, as the API server defaults it.
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
 	}
-	return 0
+	return *replicas
 }
 
 func (e *Engine) setReplicas(ctx context.Context, obj client.Object, count int32) error {
+	if !e.Limiter.TryAcquire() {
+		return ErrScaleThrottled
+	}
+	defer e.Limiter.Release()
+
 	switch v := obj.(type) {
 	case *appsv1.Deployment:
 		v.Spec.Replicas = &count
 	case *appsv1.StatefulSet:
 		v.Spec.Replicas = &count
+	case *appsv1.ReplicaSet:
+		v.Spec.Replicas = &count
+	case *unstructured.Unstructured:
+		return e.setScale(ctx, v, count)
 	}
 	return e.Client.Update(ctx, obj)
 }
@@ -287,62 +551,182 @@ func (e *Engine) hasRemainingPods(ctx context.Context, ns string, matchLabels ma
 	return len(pods.Items) > 0
 }
 
-func (e *Engine) isGroupReady(ctx context.Context, objs []client.Object, targetActive bool) bool {
+// isGroupReady reports whether every object reached the target state. Deployments,
+// StatefulSets and ReplicaSets are refreshed from a single List per kind rather than one Get each, and the
+// pods of a scale-down are listed once for the whole namespace.
+func (e *Engine) isGroupReady(ctx context.Context, objs []client.Object, targetActive bool, down downTargets) bool {
+	if len(objs) == 0 {
+		return true
+	}
+	ns := objs[0].GetNamespace()
+
+	var deployments map[string]*appsv1.Deployment
+	var statefulSets map[string]*appsv1.StatefulSet
+	var replicaSets map[string]*appsv1.ReplicaSet
 	for _, o := range objs {
-		// Refetch to get latest status
-		key := client.ObjectKey{Name: o.GetName(), Namespace: o.GetNamespace()}
-		switch v := o.(type) {
+		switch o.(type) {
 		case *appsv1.Deployment:
-			e.Client.Get(ctx, key, v)
-			if targetActive {
-				target := int32(0)
-				if v.Spec.Replicas != nil {
-					target = *v.Spec.Replicas
-				}
-				// If target is still 0, the deployment hasn't been scaled up yet → NOT ready
-				if target == 0 {
+			if deployments == nil {
+				list := &appsv1.DeploymentList{}
+				if err := e.Client.List(ctx, list, client.InNamespace(ns)); err != nil {
 					return false
 				}
-				if v.Status.ReadyReplicas < target {
-					return false
-				}
-			} else {
-				if v.Status.ReadyReplicas > 0 || v.Status.Replicas > 0 {
-					return false
-				}
-				if v.Spec.Selector != nil && e.hasRemainingPods(ctx, v.GetNamespace(), v.Spec.Selector.MatchLabels) {
-					return false
+				deployments = make(map[string]*appsv1.Deployment, len(list.Items))
+				for i := range list.Items {
+					deployments[list.Items[i].Name] = &list.Items[i]
 				}
 			}
 		case *appsv1.StatefulSet:
-			e.Client.Get(ctx, key, v)
-			if targetActive {
-				target := int32(0)
-				if v.Spec.Replicas != nil {
-					target = *v.Spec.Replicas
-				}
-				if target == 0 {
+			if statefulSets == nil {
+				list := &appsv1.StatefulSetList{}
+				if err := e.Client.List(ctx, list, client.InNamespace(ns)); err != nil {
 					return false
 				}
-				if v.Status.ReadyReplicas < target {
-					return false
+				statefulSets = make(map[string]*appsv1.StatefulSet, len(list.Items))
+				for i := range list.Items {
+					statefulSets[list.Items[i].Name] = &list.Items[i]
 				}
-			} else {
-				if v.Status.ReadyReplicas > 0 || v.Status.Replicas > 0 {
+			}
+		case *appsv1.ReplicaSet:
+			if replicaSets == nil {
+				list := &appsv1.ReplicaSetList{}
+				if err := e.Client.List(ctx, list, client.InNamespace(ns)); err != nil {
 					return false
 				}
-				if v.Spec.Selector != nil && e.hasRemainingPods(ctx, v.GetNamespace(), v.Spec.Selector.MatchLabels) {
-					return false
+				replicaSets = make(map[string]*appsv1.ReplicaSet, len(list.Items))
+				for i := range list.Items {
+					replicaSets[list.Items[i].Name] = &list.Items[i]
 				}
 			}
 		}
 	}
+
+	var pods *corev1.PodList
+	remainingPods := func(selector *metav1.LabelSelector) bool {
+		if selector == nil || len(selector.MatchLabels) == 0 {
+			return false
+		}
+		if pods == nil {
+			pods = &corev1.PodList{}
+			if err := e.Client.List(ctx, pods, client.InNamespace(ns)); err != nil {
+				pods = nil
+				return true // assume pods exist if we can't be sure
+			}
+		}
+		matcher := labels.SelectorFromSet(selector.MatchLabels)
+		for _, p := range pods.Items {
+			if matcher.Matches(labels.Set(p.Labels)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, o := range objs {
+		switch v := o.(type) {
+		case *appsv1.Deployment:
+			// Refresh in place so callers act on the latest spec and status
+			latest, ok := deployments[v.Name]
+			if !ok {
+				return false
+			}
+			*v = *latest
+			if !workloadReady(v, v.Spec.Replicas, v.Status.Replicas, v.Status.ReadyReplicas, targetActive, down) {
+				return false
+			}
+			if !targetActive && down.target(v, replicasOrDefault(v.Spec.Replicas)) == 0 && remainingPods(v.Spec.Selector) {
+				return false
+			}
+		case *appsv1.StatefulSet:
+			latest, ok := statefulSets[v.Name]
+			if !ok {
+				return false
+			}
+			*v = *latest
+			if !workloadReady(v, v.Spec.Replicas, v.Status.Replicas, v.Status.ReadyReplicas, targetActive, down) {
+				return false
+			}
+			// Ready pods may still be replaced by a rolling update resumed by the scale-up
+			if targetActive && !statefulSetRolledOut(v) {
+				return false
+			}
+			if !targetActive && down.target(v, replicasOrDefault(v.Spec.Replicas)) == 0 && remainingPods(v.Spec.Selector) {
+				return false
+			}
+		case *appsv1.ReplicaSet:
+			latest, ok := replicaSets[v.Name]
+			if !ok {
+				return false
+			}
+			*v = *latest
+			if !workloadReady(v, v.Spec.Replicas, v.Status.Replicas, v.Status.ReadyReplicas, targetActive, down) {
+				return false
+			}
+			if !targetActive && down.target(v, replicasOrDefault(v.Spec.Replicas)) == 0 && remainingPods(v.Spec.Selector) {
+				return false
+			}
+		case *unstructured.Unstructured:
+			if !e.isScaleReady(ctx, v, targetActive, down) {
+				return false
+			}
+		}
+	}
 	return true
 }
 
+// workloadReady compares the replica counts of a Deployment, StatefulSet or ReplicaSet to the target
+// state, not counting pods still terminating.
+func workloadReady(obj client.Object, specReplicas *int32, replicas, readyReplicas int32, targetActive bool, down downTargets) bool {
+	if targetActive {
+		// If target is still 0, the workload hasn't been scaled up yet → NOT ready
+		target := int32(0)
+		if specReplicas != nil {
+			target = *specReplicas
+		}
+		return target > 0 && readyReplicas >= target && !down.restoring(obj, target)
+	}
+	target := down.target(obj, replicasOrDefault(specReplicas))
+	if target == 0 {
+		return readyReplicas == 0 && replicas == 0
+	}
+	return scaledDown(replicasOrDefault(specReplicas), replicas, target)
+}
+
+// statefulSetRolledOut reports whether every pod of a StatefulSet runs the revision its
+// update strategy expects. With a rolling update partition only the pods from the partition
+// ordinal up are updated, the others are counted as current. Pods of an OnDelete strategy
+// are only replaced when deleted, so they never hold the StatefulSet back.
+func statefulSetRolledOut(ss *appsv1.StatefulSet) bool {
+	if ss.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		return true
+	}
+	replicas := replicasOrDefault(ss.Spec.Replicas)
+	partition := int32(0)
+	if ru := ss.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil {
+		partition = min(max(*ru.Partition, 0), replicas)
+	}
+	if ss.Status.UpdatedReplicas < replicas-partition {
+		return false
+	}
+	if ss.Status.CurrentRevision == ss.Status.UpdateRevision {
+		return true
+	}
+	// Pods below the partition keep the current revision until the partition is lowered
+	return ss.Status.CurrentReplicas+ss.Status.UpdatedReplicas >= replicas
+}
+
+// scaledDown reports whether a workload runs at most its scaled-down target with no extra
+// pods left. Pods of a full shutdown are checked separately.
+func scaledDown(specReplicas, replicas, target int32) bool {
+	return specReplicas <= target && replicas <= specReplicas
+}
+
 // ComputePhase checks actual replica states in the namespace and returns one of:
 // ScaledUp, ScalingUp, ScaledDown, ScalingDown, PartlyScaled
-func (e *Engine) ComputePhase(ctx context.Context, ns string, targetActive bool) string {
+// scaleKinds, replicaSets, downPercent and originalReplicas are those passed to ScaleTarget,
+// a workload kept at its partial target counts as scaled down. Unmanaged workloads are left out.
+func (e *Engine) ComputePhase(ctx context.Context, ns string, targetActive bool, scaleKinds []string, replicaSets bool, downPercent int32, originalReplicas map[string]int32) string {
+	down := downTargets{percent: downPercent, originals: originalReplicas}
 	deployments := &appsv1.DeploymentList{}
 	_ = e.Client.List(ctx, deployments, client.InNamespace(ns))
 	statefulSets := &appsv1.StatefulSetList{}
@@ -353,40 +737,68 @@ func (e *Engine) ComputePhase(ctx context.Context, ns string, targetActive bool)
 	zeroCount := 0    // spec.replicas == 0
 	readyCount := 0   // all pods ready (readyReplicas == spec.replicas)
 
-	for _, d := range deployments.Items {
-		totalResources++
-		replicas := int32(1)
-		if d.Spec.Replicas != nil {
-			replicas = *d.Spec.Replicas
+	// rolledOut is false while pods are still being replaced, see statefulSetRolledOut
+	count := func(obj client.Object, specReplicas *int32, statusReplicas, readyReplicas int32, rolledOut bool, selector *metav1.LabelSelector) {
+		if Unmanaged(obj) {
+			return
 		}
-		if replicas == 0 && d.Status.Replicas == 0 {
-			if d.Spec.Selector != nil && e.hasRemainingPods(ctx, ns, d.Spec.Selector.MatchLabels) {
+		totalResources++
+		replicas := replicasOrDefault(specReplicas)
+		target := down.target(obj, replicas)
+		if scaledDown(replicas, statusReplicas, target) {
+			if target == 0 && selector != nil && e.hasRemainingPods(ctx, ns, selector.MatchLabels) {
 				runningCount++
 			} else {
 				zeroCount++
 			}
 		} else {
 			runningCount++
-			if replicas > 0 && d.Status.ReadyReplicas >= replicas {
-				readyCount++
-			}
+		}
+		if replicas > 0 && readyReplicas >= replicas && rolledOut && !down.restoring(obj, replicas) {
+			readyCount++
 		}
 	}
-	for _, s := range statefulSets.Items {
-		totalResources++
-		replicas := int32(1)
-		if s.Spec.Replicas != nil {
-			replicas = *s.Spec.Replicas
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		count(d, d.Spec.Replicas, d.Status.Replicas, d.Status.ReadyReplicas, true, d.Spec.Selector)
+	}
+	for i := range statefulSets.Items {
+		s := &statefulSets.Items[i]
+		count(s, s.Spec.Replicas, s.Status.Replicas, s.Status.ReadyReplicas, statefulSetRolledOut(s), s.Spec.Selector)
+	}
+	if replicaSets {
+		standalone, _ := e.standaloneReplicaSets(ctx, ns)
+		for i := range standalone {
+			rs := &standalone[i]
+			count(rs, rs.Spec.Replicas, rs.Status.Replicas, rs.Status.ReadyReplicas, true, rs.Spec.Selector)
 		}
-		if replicas == 0 && s.Status.Replicas == 0 {
-			if s.Spec.Selector != nil && e.hasRemainingPods(ctx, ns, s.Spec.Selector.MatchLabels) {
-				runningCount++
-			} else {
+	}
+
+	for _, k := range scaleKinds {
+		gvk, err := ParseScaleKind(k)
+		if err != nil {
+			continue
+		}
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		_ = e.Client.List(ctx, list, client.InNamespace(ns))
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if Unmanaged(obj) {
+				continue
+			}
+			obj.SetGroupVersionKind(gvk)
+			scale, err := e.getScale(ctx, obj)
+			if err != nil {
+				continue
+			}
+			totalResources++
+			if scaledDown(scale.Spec.Replicas, scale.Status.Replicas, down.target(obj, scale.Spec.Replicas)) {
 				zeroCount++
+			} else {
+				runningCount++
 			}
-		} else {
-			runningCount++
-			if replicas > 0 && s.Status.ReadyReplicas >= replicas {
+			if scale.Spec.Replicas > 0 && e.isScaleReady(ctx, obj, true, down) {
 				readyCount++
 			}
 		}
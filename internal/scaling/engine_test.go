@@ -2,15 +2,26 @@ package scaling
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 
 	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -152,7 +163,7 @@ func TestComputePhase(t *testing.T) {
 	ctx := context.Background()
 
 	// Empty namespace -> ScaledUp if active=true, ScaledDown if active=false
-	if p := e.ComputePhase(ctx, "test-ns", true); p != "ScaledUp" {
+	if p := e.ComputePhase(ctx, "test-ns", true, nil, false, 0, nil); p != "ScaledUp" {
 		t.Errorf("Expected ScaledUp for empty ns, got %v", p)
 	}
 
@@ -166,7 +177,7 @@ func TestComputePhase(t *testing.T) {
 	}
 	e.Client.Create(ctx, d1)
 
-	if p := e.ComputePhase(ctx, "test-ns", false); p != "ScaledDown" {
+	if p := e.ComputePhase(ctx, "test-ns", false, nil, false, 0, nil); p != "ScaledDown" {
 		t.Errorf("Expected ScaledDown, got %v", p)
 	}
 
@@ -179,11 +190,42 @@ func TestComputePhase(t *testing.T) {
 	e.Client.Create(ctx, s1)
 
 	// Mixed state
-	if p := e.ComputePhase(ctx, "test-ns", false); p != "ScalingDown" && p != "PartlyScaled" {
+	if p := e.ComputePhase(ctx, "test-ns", false, nil, false, 0, nil); p != "ScalingDown" && p != "PartlyScaled" {
 		t.Errorf("Expected ScalingDown or PartlyScaled, got %v", p)
 	}
 }
 
+func TestGetSequenceIndexByKind(t *testing.T) {
+	sequence := []string{"apps/v1:StatefulSet/db-*", "apps/v1:Deployment/my-app", "apps/v1:Deployment/*", "argoproj.io/v1alpha1:Rollout/*"}
+
+	rollout := &unstructured.Unstructured{}
+	rollout.SetGroupVersionKind(schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"})
+	rollout.SetName("canary")
+
+	tests := []struct {
+		desc     string
+		obj      client.Object
+		expected int
+	}{
+		{"statefulset matching glob", &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db-postgres"}}, 0},
+		{"deployment by exact name", &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "my-app"}}, 1},
+		{"deployment by wildcard", &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "db-postgres"}}, 2},
+		{"statefulset not matched by deployment wildcard", &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "cache"}}, 999},
+		{"custom kind", rollout, 3},
+	}
+
+	for _, tt := range tests {
+		if actual := getSequenceIndex(tt.obj, sequence); actual != tt.expected {
+			t.Errorf("%s: getSequenceIndex(%q) = %d; want %d", tt.desc, tt.obj.GetName(), actual, tt.expected)
+		}
+	}
+
+	// Kind/Name without a group version
+	if actual := getSequenceIndex(&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "cache"}}, []string{"Deployment/cache", "StatefulSet/cache"}); actual != 1 {
+		t.Errorf("getSequenceIndex(StatefulSet/cache) = %d; want 1", actual)
+	}
+}
+
 func TestScaleTarget(t *testing.T) {
 	e := buildMockEngine()
 	ctx := context.Background()
@@ -199,7 +241,7 @@ func TestScaleTarget(t *testing.T) {
 	orig := make(map[string]int32)
 
 	// Scale Down
-	newOrig, _, err := e.ScaleTarget(ctx, "test-ns", false, nil, nil, orig, false)
+	newOrig, _, err := e.ScaleTarget(ctx, "test-ns", false, nil, Exclusions{}, nil, false, orig, 0, 0, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -217,6 +259,105 @@ func TestScaleTarget(t *testing.T) {
 	}
 }
 
+func TestScaleTargetConditionalExclusions(t *testing.T) {
+	e := buildMockEngine()
+	ctx := context.Background()
+
+	one := int32(1)
+	for _, name := range []string{"batch-worker", "nightly-report"} {
+		e.Client.Create(ctx, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &one},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 1},
+		})
+	}
+
+	always := []finopsv1.ScalingSchedule{{Days: []int{0, 1, 2, 3, 4, 5, 6}, StartTime: "00:00", EndTime: "23:59"}}
+	tomorrow := []finopsv1.ScalingSchedule{{Days: []int{(int(time.Now().Weekday()) + 1) % 7}, StartTime: "00:00", EndTime: "23:59"}}
+	exclusions := Exclusions{Conditional: []finopsv1.ConditionalExclusion{
+		{Names: []string{"batch-*"}, Schedules: always},
+		{Names: []string{"nightly-report"}, Schedules: tomorrow},
+	}}
+
+	orig, _, err := e.ScaleTarget(ctx, "test-ns", false, nil, exclusions, nil, false, nil, 0, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	worker := &appsv1.Deployment{}
+	e.Client.Get(ctx, client.ObjectKey{Name: "batch-worker", Namespace: "test-ns"}, worker)
+	if *worker.Spec.Replicas != 1 {
+		t.Errorf("Expected batch-worker to be kept up while its exclusion is active, got %d", *worker.Spec.Replicas)
+	}
+	report := &appsv1.Deployment{}
+	e.Client.Get(ctx, client.ObjectKey{Name: "nightly-report", Namespace: "test-ns"}, report)
+	if *report.Spec.Replicas != 0 {
+		t.Errorf("Expected nightly-report to scale down outside its exclusion schedule, got %d", *report.Spec.Replicas)
+	}
+
+	// Scale-up is never blocked by conditional exclusions
+	if _, _, err := e.ScaleTarget(ctx, "test-ns", true, nil, exclusions, nil, false, orig, 0, 0, false); err != nil {
+		t.Fatal(err)
+	}
+	e.Client.Get(ctx, client.ObjectKey{Name: "nightly-report", Namespace: "test-ns"}, report)
+	if *report.Spec.Replicas != 1 {
+		t.Errorf("Expected nightly-report to scale back up, got %d", *report.Spec.Replicas)
+	}
+}
+
+func TestScaleTargetNilReplicas(t *testing.T) {
+	e := buildMockEngine()
+	ctx := context.Background()
+
+	// Replicas left unset, as GitOps tools commonly create Deployments
+	d1 := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "defaulted", Namespace: "test-ns"},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: 1},
+	}
+	e.Client.Create(ctx, d1)
+
+	newOrig, _, err := e.ScaleTarget(ctx, "test-ns", false, nil, Exclusions{}, nil, false, nil, 0, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newOrig["*v1.Deployment/defaulted"] != 1 {
+		t.Errorf("Expected unset replicas to be saved as 1, got %d", newOrig["*v1.Deployment/defaulted"])
+	}
+
+	scaledD := &appsv1.Deployment{}
+	e.Client.Get(ctx, client.ObjectKey{Name: "defaulted", Namespace: "test-ns"}, scaledD)
+	if scaledD.Spec.Replicas == nil || *scaledD.Spec.Replicas != 0 {
+		t.Errorf("Expected replicas to be 0, got %v", scaledD.Spec.Replicas)
+	}
+}
+
+func TestScaleTargetMinReplicas(t *testing.T) {
+	e := buildMockEngine()
+	ctx := context.Background()
+
+	zero := int32(0)
+	d1 := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "critical",
+			Namespace:   "test-ns",
+			Annotations: map[string]string{MinReplicasAnnotation: "3"},
+		},
+		Spec: appsv1.DeploymentSpec{Replicas: &zero},
+	}
+	e.Client.Create(ctx, d1)
+
+	// Scale Up without any recorded original replicas
+	if _, _, err := e.ScaleTarget(ctx, "test-ns", true, nil, Exclusions{}, nil, false, nil, 0, 0, false); err != nil {
+		t.Fatal(err)
+	}
+
+	scaledD := &appsv1.Deployment{}
+	e.Client.Get(ctx, client.ObjectKey{Name: "critical", Namespace: "test-ns"}, scaledD)
+	if *scaledD.Spec.Replicas != 3 {
+		t.Errorf("Expected replicas to be the annotated minimum 3, got %d", *scaledD.Spec.Replicas)
+	}
+}
+
 func TestIsGroupReady(t *testing.T) {
 	e := buildMockEngine()
 	ctx := context.Background()
@@ -232,14 +373,976 @@ func TestIsGroupReady(t *testing.T) {
 	objs := []client.Object{d1}
 
 	// Target active = true, but readyReplicas = 0 < targetReplicas(1) -> False
-	if ready := e.isGroupReady(ctx, objs, true); ready {
+	if ready := e.isGroupReady(ctx, objs, true, downTargets{}); ready {
 		t.Errorf("Expected group to NOT be ready")
 	}
 
 	// Update to ready
 	d1.Status.ReadyReplicas = 1
 	e.Client.Status().Update(ctx, d1)
-	if ready := e.isGroupReady(ctx, objs, true); !ready {
+	if ready := e.isGroupReady(ctx, objs, true, downTargets{}); !ready {
 		t.Errorf("Expected group to be ready")
 	}
 }
+
+func TestScaleTargetWithHPA(t *testing.T) {
+	e := buildMockEngine()
+	ctx := context.Background()
+
+	five := int32(5)
+	two := int32(2)
+	d1 := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-ns"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &five},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: 5},
+	}
+	e.Client.Create(ctx, d1)
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-ns"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+			MinReplicas:    &two,
+			MaxReplicas:    10,
+		},
+	}
+	e.Client.Create(ctx, hpa)
+
+	// Scale Down: the HPA is marked as disabled
+	orig, _, err := e.ScaleTarget(ctx, "test-ns", false, nil, Exclusions{}, nil, false, nil, 0, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := &autoscalingv2.HorizontalPodAutoscaler{}
+	e.Client.Get(ctx, client.ObjectKey{Name: "web", Namespace: "test-ns"}, current)
+	if _, ok := current.Annotations[HPADisabledAnnotation]; !ok {
+		t.Errorf("Expected HPA to be annotated as disabled")
+	}
+
+	// Scale Up: replicas are handed back to the HPA instead of restoring 5
+	if _, _, err := e.ScaleTarget(ctx, "test-ns", true, nil, Exclusions{}, nil, false, orig, 0, 0, false); err != nil {
+		t.Fatal(err)
+	}
+	scaledD := &appsv1.Deployment{}
+	e.Client.Get(ctx, client.ObjectKey{Name: "web", Namespace: "test-ns"}, scaledD)
+	if *scaledD.Spec.Replicas != 2 {
+		t.Errorf("Expected replicas to be the HPA minimum 2, got %d", *scaledD.Spec.Replicas)
+	}
+	e.Client.Get(ctx, client.ObjectKey{Name: "web", Namespace: "test-ns"}, current)
+	if _, ok := current.Annotations[HPADisabledAnnotation]; ok {
+		t.Errorf("Expected HPA annotation to be removed on scale up")
+	}
+}
+
+func TestScaleTargetWithPDB(t *testing.T) {
+	e := buildMockEngine()
+	recorder := record.NewFakeRecorder(10)
+	e.Recorder = recorder
+	ctx := context.Background()
+
+	three := int32(3)
+	podLabels := map[string]string{"app": "db"}
+	s1 := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "test-ns"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &three,
+			Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: podLabels}},
+		},
+		Status: appsv1.StatefulSetStatus{Replicas: 3, ReadyReplicas: 3},
+	}
+	e.Client.Create(ctx, s1)
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "test-ns"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: podLabels}},
+		Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 0},
+	}
+	e.Client.Create(ctx, pdb)
+
+	orig, ready, err := e.ScaleTarget(ctx, "test-ns", false, nil, Exclusions{}, nil, false, nil, 0, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ready {
+		t.Errorf("Expected scale-down to be in progress")
+	}
+
+	scaled := &appsv1.StatefulSet{}
+	e.Client.Get(ctx, client.ObjectKey{Name: "db", Namespace: "test-ns"}, scaled)
+	if *scaled.Spec.Replicas != 2 {
+		t.Errorf("Expected a single replica step down to 2, got %d", *scaled.Spec.Replicas)
+	}
+	if orig["*v1.StatefulSet/db"] != 3 {
+		t.Errorf("Expected original replicas 3 to be saved, got %d", orig["*v1.StatefulSet/db"])
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "PodDisruptionBudgetViolation") {
+			t.Errorf("Expected a PDB violation warning, got %q", event)
+		}
+	default:
+		t.Errorf("Expected a PDB violation warning event")
+	}
+
+	// Next step once the terminated pod is gone keeps the first recorded count
+	scaled.Status.Replicas = 2
+	e.Client.Status().Update(ctx, scaled)
+	orig, _, _ = e.ScaleTarget(ctx, "test-ns", false, nil, Exclusions{}, nil, false, orig, 0, 0, false)
+	e.Client.Get(ctx, client.ObjectKey{Name: "db", Namespace: "test-ns"}, scaled)
+	if *scaled.Spec.Replicas != 1 {
+		t.Errorf("Expected replicas to step down to 1, got %d", *scaled.Spec.Replicas)
+	}
+	if orig["*v1.StatefulSet/db"] != 3 {
+		t.Errorf("Expected original replicas to stay 3, got %d", orig["*v1.StatefulSet/db"])
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	e := buildMockEngine()
+	ctx := context.Background()
+
+	e.Client.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-ns"}})
+	e.Client.Create(ctx, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "db-postgres", Namespace: "test-ns"}})
+	e.Client.Create(ctx, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "test-ns"}})
+	e.Client.Create(ctx, &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "debug-tools", Namespace: "test-ns"}})
+
+	result, err := e.ValidateConfig(ctx, finopsv1.ScalingConfigSpec{
+		TargetNamespace: "test-ns",
+		Sequence:        []string{"db-*", "cache"},
+		Exclusions:      []string{"debug-*"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Sequence) != 2 || len(result.Sequence[0].Matches) != 1 || result.Sequence[0].Matches[0] != "Deployment/db-postgres" {
+		t.Errorf("Expected db entry to match Deployment/db-postgres, got %+v", result.Sequence)
+	}
+	if len(result.Unsequenced) != 1 || result.Unsequenced[0] != "Deployment/frontend" {
+		t.Errorf("Expected frontend to be unsequenced, got %v", result.Unsequenced)
+	}
+	if len(result.Excluded) != 1 || result.Excluded[0] != "StatefulSet/debug-tools" {
+		t.Errorf("Expected debug-tools to be excluded, got %v", result.Excluded)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], `"cache"`) {
+		t.Errorf("Expected a warning for the cache entry, got %v", result.Warnings)
+	}
+
+	// Missing namespace
+	result, err = e.ValidateConfig(ctx, finopsv1.ScalingConfigSpec{TargetNamespace: "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "does not exist") {
+		t.Errorf("Expected a missing namespace warning, got %v", result.Warnings)
+	}
+}
+
+func TestValidateGroup(t *testing.T) {
+	e := buildMockEngine()
+	ctx := context.Background()
+
+	e.Client.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-a"}})
+	e.Client.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-b"}})
+
+	result, err := e.ValidateGroup(ctx, finopsv1.ScalingGroupSpec{
+		Namespaces: []string{"ns-a", "ns-b"},
+		Sequence:   []string{"ns-a ext:db", "ns-c"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Sequence[0].Matches) != 1 || result.Sequence[0].Matches[0] != "ns-a" {
+		t.Errorf("Expected first stage to match ns-a only, got %v", result.Sequence[0].Matches)
+	}
+	if len(result.Sequence[1].Matches) != 0 {
+		t.Errorf("Expected second stage to match nothing, got %v", result.Sequence[1].Matches)
+	}
+	if len(result.Unsequenced) != 1 || result.Unsequenced[0] != "ns-b" {
+		t.Errorf("Expected ns-b to be unsequenced, got %v", result.Unsequenced)
+	}
+	// Undeclared ext:db, missing ns-c and the empty second stage
+	if len(result.Warnings) != 3 {
+		t.Errorf("Expected 3 warnings, got %v", result.Warnings)
+	}
+}
+
+func TestScaleTargetWithScaleSubresource(t *testing.T) {
+	scheme := runtime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	finopsv1.AddToScheme(scheme)
+
+	rollout := &unstructured.Unstructured{}
+	rollout.SetGroupVersionKind(schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"})
+	rollout.SetName("canary")
+	rollout.SetNamespace("test-ns")
+	unstructured.SetNestedField(rollout.Object, int64(3), "spec", "replicas")
+	unstructured.SetNestedField(rollout.Object, int64(3), "status", "replicas")
+
+	// The fake client has no scale subresource for custom resources, emulate it on spec.replicas
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(rollout).WithInterceptorFuncs(interceptor.Funcs{
+		SubResourceGet: func(ctx context.Context, c client.Client, subResource string, obj client.Object, sub client.Object, opts ...client.SubResourceGetOption) error {
+			u := obj.(*unstructured.Unstructured)
+			if err := c.Get(ctx, client.ObjectKeyFromObject(u), u); err != nil {
+				return err
+			}
+			replicas, _, _ := unstructured.NestedInt64(u.Object, "spec", "replicas")
+			status, _, _ := unstructured.NestedInt64(u.Object, "status", "replicas")
+			scale := sub.(*autoscalingv1.Scale)
+			scale.Spec.Replicas = int32(replicas)
+			scale.Status.Replicas = int32(status)
+			return nil
+		},
+		SubResourceUpdate: func(ctx context.Context, c client.Client, subResource string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+			updateOpts := &client.SubResourceUpdateOptions{}
+			updateOpts.ApplyOptions(opts)
+			u := obj.(*unstructured.Unstructured)
+			if err := c.Get(ctx, client.ObjectKeyFromObject(u), u); err != nil {
+				return err
+			}
+			scale := updateOpts.SubResourceBody.(*autoscalingv1.Scale)
+			// Pretend the pods follow immediately
+			unstructured.SetNestedField(u.Object, int64(scale.Spec.Replicas), "spec", "replicas")
+			unstructured.SetNestedField(u.Object, int64(scale.Spec.Replicas), "status", "replicas")
+			return c.Update(ctx, u)
+		},
+	}).Build()
+	e := &Engine{Client: c}
+	ctx := context.Background()
+	kinds := []string{"argoproj.io/v1alpha1:Rollout"}
+
+	orig, _, err := e.ScaleTarget(ctx, "test-ns", false, nil, Exclusions{}, kinds, false, nil, 0, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if orig["Rollout.argoproj.io/canary"] != 3 {
+		t.Errorf("Expected original replicas of the Rollout to be recorded, got %v", orig)
+	}
+
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(rollout.GroupVersionKind())
+	c.Get(ctx, client.ObjectKey{Name: "canary", Namespace: "test-ns"}, current)
+	if replicas, _, _ := unstructured.NestedInt64(current.Object, "spec", "replicas"); replicas != 0 {
+		t.Errorf("Expected Rollout to be scaled to 0, got %d", replicas)
+	}
+	if p := e.ComputePhase(ctx, "test-ns", false, kinds, false, 0, nil); p != "ScaledDown" {
+		t.Errorf("Expected ScaledDown, got %s", p)
+	}
+
+	if _, _, err := e.ScaleTarget(ctx, "test-ns", true, nil, Exclusions{}, kinds, false, orig, 0, 0, false); err != nil {
+		t.Fatal(err)
+	}
+	c.Get(ctx, client.ObjectKey{Name: "canary", Namespace: "test-ns"}, current)
+	if replicas, _, _ := unstructured.NestedInt64(current.Object, "spec", "replicas"); replicas != 3 {
+		t.Errorf("Expected Rollout to be restored to 3, got %d", replicas)
+	}
+
+	if _, _, err := e.ScaleTarget(ctx, "test-ns", true, nil, Exclusions{}, []string{"Rollout"}, false, nil, 0, 0, false); err == nil {
+		t.Errorf("Expected an error for a malformed scale kind")
+	}
+}
+
+// BenchmarkScaleTargetAPICalls scales down a namespace of 50 Deployments and reports the
+// Get and List calls issued per ScaleTarget run as apicalls/op.
+func BenchmarkScaleTargetAPICalls(b *testing.B) {
+	scheme := runtime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	finopsv1.AddToScheme(scheme)
+
+	one := int32(1)
+	var objs []client.Object
+	for i := 0; i < 50; i++ {
+		objs = append(objs, &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("app-%d", i), Namespace: "test-ns"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &one,
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": fmt.Sprintf("app-%d", i)}},
+			},
+			Status: appsv1.DeploymentStatus{Replicas: 1, ReadyReplicas: 1},
+		})
+	}
+
+	var calls int
+	funcs := interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			calls++
+			return c.Get(ctx, key, obj, opts...)
+		},
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			calls++
+			return c.List(ctx, list, opts...)
+		},
+	}
+
+	ctx := context.Background()
+	calls = 0
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		seed := make([]client.Object, len(objs))
+		for j, o := range objs {
+			seed[j] = o.DeepCopyObject().(client.Object)
+		}
+		e := &Engine{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(seed...).WithInterceptorFuncs(funcs).Build()}
+		b.StartTimer()
+
+		if _, _, err := e.ScaleTarget(ctx, "test-ns", false, nil, Exclusions{}, nil, false, nil, 0, 0, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(calls)/float64(b.N), "apicalls/op")
+}
+
+func TestNextTransition(t *testing.T) {
+	engine := &Engine{}
+	weekdays := finopsv1.ScalingSchedule{Days: []int{1, 2, 3, 4, 5}, StartTime: "08:00", EndTime: "18:00", Timezone: "UTC"}
+	truthy := true
+
+	tests := []struct {
+		name      string
+		schedules []finopsv1.ScalingSchedule
+		manual    *bool
+		now       time.Time
+		wantAt    time.Time
+		wantState bool
+		wantOK    bool
+	}{
+		{
+			name:      "before the window starts",
+			schedules: []finopsv1.ScalingSchedule{weekdays},
+			now:       time.Date(2026, 3, 2, 7, 30, 0, 0, time.UTC), // Monday
+			wantAt:    time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC),
+			wantState: true,
+			wantOK:    true,
+		},
+		{
+			name:      "inside the window",
+			schedules: []finopsv1.ScalingSchedule{weekdays},
+			now:       time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC),
+			wantAt:    time.Date(2026, 3, 2, 18, 1, 0, 0, time.UTC),
+			wantState: false,
+			wantOK:    true,
+		},
+		{
+			name:      "over the weekend",
+			schedules: []finopsv1.ScalingSchedule{weekdays},
+			now:       time.Date(2026, 3, 6, 20, 0, 0, 0, time.UTC), // Friday
+			wantAt:    time.Date(2026, 3, 9, 8, 0, 0, 0, time.UTC),
+			wantState: true,
+			wantOK:    true,
+		},
+		{
+			name: "adjacent schedules in another timezone",
+			schedules: []finopsv1.ScalingSchedule{
+				weekdays,
+				{Days: []int{1}, StartTime: "19:01", EndTime: "22:00", Timezone: "Europe/Berlin"},
+			},
+			now:       time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC),
+			wantAt:    time.Date(2026, 3, 2, 21, 1, 0, 0, time.UTC),
+			wantState: false,
+			wantOK:    true,
+		},
+		{
+			name:      "manual override",
+			schedules: []finopsv1.ScalingSchedule{weekdays},
+			manual:    &truthy,
+			now:       time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "no schedules",
+			now:  time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at, state, ok := engine.NextTransition(tt.schedules, tt.manual, tt.now)
+			if ok != tt.wantOK || !at.Equal(tt.wantAt) || state != tt.wantState {
+				t.Errorf("NextTransition() = %v, %v, %v; want %v, %v, %v", at, state, ok, tt.wantAt, tt.wantState, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestIsActiveAtOvernight(t *testing.T) {
+	engine := &Engine{}
+
+	// Friday night until Saturday morning
+	schedules := []finopsv1.ScalingSchedule{{Days: []int{5}, StartTime: "22:00", EndTime: "06:00", Timezone: "UTC", Overnight: true}}
+
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2026, 3, 6, 21, 59, 0, 0, time.UTC), false},
+		{time.Date(2026, 3, 6, 23, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 3, 7, 5, 30, 0, 0, time.UTC), true},
+		{time.Date(2026, 3, 7, 6, 1, 0, 0, time.UTC), false},
+		{time.Date(2026, 3, 7, 23, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		if got := engine.IsActiveAt(schedules, nil, tt.at); got != tt.want {
+			t.Errorf("IsActiveAt(%v) = %v; want %v", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestIsActiveAtTimezone(t *testing.T) {
+	engine := &Engine{}
+
+	// Business hours in New York, 14:00-23:00 UTC while daylight saving time applies
+	schedules := []finopsv1.ScalingSchedule{{Days: []int{1, 2, 3, 4, 5}, StartTime: "09:00", EndTime: "18:00", Timezone: "America/New_York"}}
+
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2026, 6, 1, 12, 59, 0, 0, time.UTC), false},
+		{time.Date(2026, 6, 1, 13, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 6, 1, 22, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 6, 1, 22, 1, 0, 0, time.UTC), false},
+		// Saturday 01:00 UTC is Friday 21:00 in New York, after hours
+		{time.Date(2026, 6, 6, 1, 0, 0, 0, time.UTC), false},
+		{time.Date(2026, 6, 5, 21, 0, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		if got := engine.IsActiveAt(schedules, nil, tt.at); got != tt.want {
+			t.Errorf("IsActiveAt(%v) = %v; want %v", tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestDownReplicas(t *testing.T) {
+	tests := []struct {
+		original, percent, expected int32
+	}{
+		{4, 0, 0},
+		{4, 25, 1},
+		{4, 50, 2},
+		{3, 50, 2},
+		{5, 10, 1},
+		{2, 100, 2},
+		{0, 50, 0},
+	}
+	for _, tt := range tests {
+		if actual := downReplicas(tt.original, tt.percent); actual != tt.expected {
+			t.Errorf("downReplicas(%d, %d) = %d; want %d", tt.original, tt.percent, actual, tt.expected)
+		}
+	}
+}
+
+func TestScaleTargetPartialScaleDown(t *testing.T) {
+	e := buildMockEngine()
+	ctx := context.Background()
+
+	four := int32(4)
+	d1 := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "app1", Namespace: "test-ns"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &four},
+		Status:     appsv1.DeploymentStatus{Replicas: 4, ReadyReplicas: 4},
+	}
+	e.Client.Create(ctx, d1)
+
+	// Scale down to 25% keeps a single replica
+	orig, _, err := e.ScaleTarget(ctx, "test-ns", false, nil, Exclusions{}, nil, false, map[string]int32{}, 25, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if orig["*v1.Deployment/app1"] != 4 {
+		t.Errorf("Expected original replicas 4 to be saved, got %d", orig["*v1.Deployment/app1"])
+	}
+	scaled := &appsv1.Deployment{}
+	e.Client.Get(ctx, client.ObjectKey{Name: "app1", Namespace: "test-ns"}, scaled)
+	if *scaled.Spec.Replicas != 1 {
+		t.Fatalf("Expected replicas to be 1, got %d", *scaled.Spec.Replicas)
+	}
+
+	// Once the pods settled the namespace counts as scaled down
+	scaled.Status.Replicas = 1
+	scaled.Status.ReadyReplicas = 1
+	if err := e.Client.Status().Update(ctx, scaled); err != nil {
+		t.Fatal(err)
+	}
+	if p := e.ComputePhase(ctx, "test-ns", false, nil, false, 25, orig); p != "ScaledDown" {
+		t.Errorf("Expected ScaledDown, got %v", p)
+	}
+	if p := e.ComputePhase(ctx, "test-ns", true, nil, false, 25, orig); p == "ScaledUp" {
+		t.Errorf("Expected a partially scaled namespace not to be ScaledUp")
+	}
+
+	// Scaling up restores the original count
+	if _, _, err := e.ScaleTarget(ctx, "test-ns", true, nil, Exclusions{}, nil, false, orig, 25, 0, false); err != nil {
+		t.Fatal(err)
+	}
+	e.Client.Get(ctx, client.ObjectKey{Name: "app1", Namespace: "test-ns"}, scaled)
+	if *scaled.Spec.Replicas != 4 {
+		t.Errorf("Expected replicas to be restored to 4, got %d", *scaled.Spec.Replicas)
+	}
+}
+
+// fakeClock is a frozen clock moved forward by the tests
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestIsActiveFrozenClock(t *testing.T) {
+	// Monday 10:00 UTC
+	clock := &fakeClock{now: time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)}
+	engine := &Engine{Clock: clock}
+	schedules := []finopsv1.ScalingSchedule{{Days: []int{1, 2, 3, 4, 5}, StartTime: "08:00", EndTime: "18:00", Timezone: "UTC"}}
+
+	if !engine.IsActive(schedules, nil) {
+		t.Errorf("expected the schedule to be active on Monday at 10:00")
+	}
+	clock.now = time.Date(2026, 6, 1, 18, 1, 0, 0, time.UTC)
+	if engine.IsActive(schedules, nil) {
+		t.Errorf("expected the schedule to be inactive on Monday at 18:01")
+	}
+	clock.now = time.Date(2026, 6, 6, 10, 0, 0, 0, time.UTC)
+	if engine.IsActive(schedules, nil) {
+		t.Errorf("expected the schedule to be inactive on Saturday")
+	}
+}
+
+func TestStageTimedOut(t *testing.T) {
+	lastAction := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: lastAction}
+	engine := &Engine{Clock: clock}
+	timeout := 90 * time.Second
+
+	clock.now = lastAction.Add(timeout - time.Second)
+	if engine.StageTimedOut(lastAction, timeout) {
+		t.Errorf("expected no timeout before %s", timeout)
+	}
+	clock.now = lastAction.Add(timeout)
+	if engine.StageTimedOut(lastAction, timeout) {
+		t.Errorf("expected no timeout at exactly %s", timeout)
+	}
+	clock.now = lastAction.Add(timeout + time.Nanosecond)
+	if !engine.StageTimedOut(lastAction, timeout) {
+		t.Errorf("expected a timeout right after %s", timeout)
+	}
+}
+
+func TestManagingGroup(t *testing.T) {
+	groups := []finopsv1.ScalingGroup{
+		{ObjectMeta: metav1.ObjectMeta{Name: "zeta"}, Spec: finopsv1.ScalingGroupSpec{Namespaces: []string{"shop", "api"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "alpha"}, Spec: finopsv1.ScalingGroupSpec{Namespaces: []string{"api"}}},
+	}
+
+	if g := ManagingGroup(groups, "shop"); g == nil || g.Name != "zeta" {
+		t.Errorf("expected shop to be managed by zeta, got %v", g)
+	}
+	if g := ManagingGroup(groups, "api"); g == nil || g.Name != "alpha" {
+		t.Errorf("expected the first group by name to win for api, got %v", g)
+	}
+	if g := ManagingGroup(groups, "billing"); g != nil {
+		t.Errorf("expected billing to be unmanaged, got %s", g.Name)
+	}
+}
+
+func TestScaleTargetLimiter(t *testing.T) {
+	e := buildMockEngine()
+	e.Limiter = NewLimiter(1)
+	ctx := context.Background()
+
+	one := int32(1)
+	e.Client.Create(ctx, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "app1", Namespace: "test-ns"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &one},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: 1},
+	})
+	replicas := func() int32 {
+		d := &appsv1.Deployment{}
+		e.Client.Get(ctx, client.ObjectKey{Name: "app1", Namespace: "test-ns"}, d)
+		return *d.Spec.Replicas
+	}
+
+	// Another reconcile holds the only slot
+	if !e.Limiter.TryAcquire() {
+		t.Fatal("expected a free slot")
+	}
+	if e.Limiter.TryAcquire() {
+		t.Fatal("expected the limiter to be full")
+	}
+	_, ready, err := e.ScaleTarget(ctx, "test-ns", false, nil, Exclusions{}, nil, false, nil, 0, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ready || replicas() != 1 {
+		t.Errorf("throttled scale should leave the workload and report not ready, got ready %v and %d replicas", ready, replicas())
+	}
+
+	e.Limiter.Release()
+	if _, _, err := e.ScaleTarget(ctx, "test-ns", false, nil, Exclusions{}, nil, false, nil, 0, 0, false); err != nil {
+		t.Fatal(err)
+	}
+	if replicas() != 0 {
+		t.Errorf("expected the scale to proceed once a slot is free, got %d replicas", replicas())
+	}
+	if !e.Limiter.TryAcquire() {
+		t.Error("the slot was not released after the update")
+	}
+
+	var unlimited *Limiter
+	if !unlimited.TryAcquire() || NewLimiter(0) != nil {
+		t.Error("a nil limiter should be unlimited")
+	}
+}
+
+func TestScaleTargetChanges(t *testing.T) {
+	scheme := runtime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	one, two := int32(1), int32(2)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "test-ns"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &two},
+			Status:     appsv1.DeploymentStatus{Replicas: 2, ReadyReplicas: 2},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "test-ns"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &one},
+			Status:     appsv1.StatefulSetStatus{Replicas: 1, ReadyReplicas: 1},
+		},
+	).WithInterceptorFuncs(interceptor.Funcs{
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			if obj.GetName() == "db" {
+				return fmt.Errorf("update rejected")
+			}
+			return c.Update(ctx, obj, opts...)
+		},
+	}).Build()
+	e := &Engine{Client: c}
+
+	_, _, changes, err := e.ScaleTargetChanges(context.Background(), "test-ns", false, nil, Exclusions{}, nil, false, nil, 0, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]finopsv1.ReplicaChange{
+		"api": {Namespace: "test-ns", Kind: "Deployment", Name: "api", From: 2, To: 0},
+		"db":  {Namespace: "test-ns", Kind: "StatefulSet", Name: "db", From: 1, To: 0, Error: "update rejected"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), changes)
+	}
+	for _, change := range changes {
+		if change != want[change.Name] {
+			t.Errorf("got change %+v, want %+v", change, want[change.Name])
+		}
+	}
+
+	// Nothing left to change
+	_, _, changes, _ = e.ScaleTargetChanges(context.Background(), "test-ns", false, nil, Exclusions{}, nil, false, nil, 0, 0, true)
+	if len(changes) != 1 || changes[0].Name != "db" {
+		t.Errorf("only the failed update should be attempted again, got %+v", changes)
+	}
+}
+
+func TestScaleTargetStepUp(t *testing.T) {
+	e := buildMockEngine()
+	ctx := context.Background()
+
+	zero := int32(0)
+	e.Client.Create(ctx, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "app1", Namespace: "test-ns"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &zero},
+	})
+	get := func() *appsv1.Deployment {
+		d := &appsv1.Deployment{}
+		e.Client.Get(ctx, client.ObjectKey{Name: "app1", Namespace: "test-ns"}, d)
+		return d
+	}
+	markReady := func() {
+		d := get()
+		d.Status.Replicas, d.Status.ReadyReplicas = *d.Spec.Replicas, *d.Spec.Replicas
+		e.Client.Status().Update(ctx, d)
+	}
+	orig := map[string]int32{"*v1.Deployment/app1": 8}
+
+	// 25% of 8 is two replicas per step
+	for _, want := range []int32{2, 4, 6, 8} {
+		var ready bool
+		var err error
+		orig, ready, err = e.ScaleTarget(ctx, "test-ns", true, nil, Exclusions{}, nil, false, orig, 0, 25, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := *get().Spec.Replicas; got != want {
+			t.Fatalf("expected a step to %d replicas, got %d", want, got)
+		}
+		if ready {
+			t.Fatalf("the scale-up should not be ready before the pods of the last step are")
+		}
+
+		// The next step waits for the pods of this one
+		orig, _, _ = e.ScaleTarget(ctx, "test-ns", true, nil, Exclusions{}, nil, false, orig, 0, 25, false)
+		if got := *get().Spec.Replicas; got != want {
+			t.Fatalf("expected to wait at %d replicas until ready, got %d", want, got)
+		}
+		markReady()
+	}
+
+	orig, ready, err := e.ScaleTarget(ctx, "test-ns", true, nil, Exclusions{}, nil, false, orig, 0, 25, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ready || len(orig) != 0 {
+		t.Errorf("expected the scale-up to complete and clear the originals, got ready %v and %v", ready, orig)
+	}
+}
+
+func TestScaleTargetStepUpInterrupted(t *testing.T) {
+	e := buildMockEngine()
+	ctx := context.Background()
+
+	two := int32(2)
+	e.Client.Create(ctx, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "app1", Namespace: "test-ns"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &two},
+		Status:     appsv1.DeploymentStatus{Replicas: 2, ReadyReplicas: 2},
+	})
+
+	// Scaling down halfway through a ramp to 8 keeps 8 as the count to restore
+	orig, _, err := e.ScaleTarget(ctx, "test-ns", false, nil, Exclusions{}, nil, false, map[string]int32{"*v1.Deployment/app1": 8}, 0, 25, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if orig["*v1.Deployment/app1"] != 8 {
+		t.Errorf("expected the original replicas to stay 8, got %d", orig["*v1.Deployment/app1"])
+	}
+}
+
+func TestScaleTargetUnmanaged(t *testing.T) {
+	e := buildMockEngine()
+	recorder := record.NewFakeRecorder(10)
+	e.Recorder = recorder
+	ctx := context.Background()
+
+	two := int32(2)
+	e.Client.Create(ctx, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "pinned",
+			Namespace:   "test-ns",
+			Annotations: map[string]string{ManagedAnnotation: "false"},
+		},
+		Spec:   appsv1.DeploymentSpec{Replicas: &two},
+		Status: appsv1.DeploymentStatus{Replicas: 2, ReadyReplicas: 2},
+	})
+
+	orig, ready, err := e.ScaleTarget(ctx, "test-ns", false, nil, Exclusions{}, nil, false, nil, 0, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ready || len(orig) != 0 {
+		t.Errorf("an unmanaged workload should be left out entirely, got ready %v and originals %v", ready, orig)
+	}
+	d := &appsv1.Deployment{}
+	e.Client.Get(ctx, client.ObjectKey{Name: "pinned", Namespace: "test-ns"}, d)
+	if *d.Spec.Replicas != 2 {
+		t.Errorf("expected the unmanaged workload to keep 2 replicas, got %d", *d.Spec.Replicas)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, OptedOutReason) {
+			t.Errorf("expected an %s event, got %q", OptedOutReason, event)
+		}
+	default:
+		t.Error("expected an event confirming the opt-out")
+	}
+
+	if phase := e.ComputePhase(ctx, "test-ns", false, nil, false, 0, nil); phase != "ScaledDown" {
+		t.Errorf("an unmanaged workload should not hold the namespace in %s", phase)
+	}
+}
+
+func TestScaleTargetStandaloneReplicaSets(t *testing.T) {
+	e := buildMockEngine()
+	ctx := context.Background()
+
+	two, three := int32(2), int32(3)
+	isController := true
+	e.Client.Create(ctx, &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "test-ns"},
+		Spec:       appsv1.ReplicaSetSpec{Replicas: &two},
+		Status:     appsv1.ReplicaSetStatus{Replicas: 2, ReadyReplicas: 2},
+	})
+	e.Client.Create(ctx, &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "web-7d9f",
+			Namespace:       "test-ns",
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", UID: "web-uid", Controller: &isController}},
+		},
+		Spec:   appsv1.ReplicaSetSpec{Replicas: &three},
+		Status: appsv1.ReplicaSetStatus{Replicas: 3, ReadyReplicas: 3},
+	})
+	replicas := func(name string) int32 {
+		rs := &appsv1.ReplicaSet{}
+		e.Client.Get(ctx, client.ObjectKey{Name: name, Namespace: "test-ns"}, rs)
+		return *rs.Spec.Replicas
+	}
+
+	// Left alone unless enabled
+	if _, _, err := e.ScaleTarget(ctx, "test-ns", false, nil, Exclusions{}, nil, false, nil, 0, 0, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := replicas("legacy"); got != 2 {
+		t.Fatalf("expected the ReplicaSet to be ignored by default, got %d replicas", got)
+	}
+
+	orig, _, err := e.ScaleTarget(ctx, "test-ns", false, nil, Exclusions{}, nil, true, nil, 0, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := replicas("legacy"); got != 0 {
+		t.Errorf("expected the standalone ReplicaSet scaled to 0, got %d", got)
+	}
+	if got := replicas("web-7d9f"); got != 3 {
+		t.Errorf("expected the ReplicaSet of a Deployment to be left to it, got %d replicas", got)
+	}
+	if len(orig) != 1 || orig["*v1.ReplicaSet/legacy"] != 2 {
+		t.Errorf("expected only the standalone ReplicaSet recorded, got %v", orig)
+	}
+
+	rs := &appsv1.ReplicaSet{}
+	e.Client.Get(ctx, client.ObjectKey{Name: "legacy", Namespace: "test-ns"}, rs)
+	rs.Status = appsv1.ReplicaSetStatus{}
+	e.Client.Status().Update(ctx, rs)
+	if phase := e.ComputePhase(ctx, "test-ns", false, nil, true, 0, orig); phase != "ScaledDown" {
+		t.Errorf("expected ScaledDown, got %s", phase)
+	}
+
+	if _, _, err := e.ScaleTarget(ctx, "test-ns", true, nil, Exclusions{}, nil, true, orig, 0, 0, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := replicas("legacy"); got != 2 {
+		t.Errorf("expected the standalone ReplicaSet restored to 2, got %d", got)
+	}
+}
+
+func TestEvaluateIdle(t *testing.T) {
+	history := func(usages ...string) []finopsv1.MetricDataPoint {
+		points := make([]finopsv1.MetricDataPoint, len(usages))
+		for i, u := range usages {
+			points[i] = finopsv1.MetricDataPoint{CPU: finopsv1.ResourceMetrics{Usage: u}}
+		}
+		return points
+	}
+	policy := &finopsv1.AutoIdlePolicy{IdleBelowMillicores: 50, ActiveAboveMillicores: 200, IdlePoints: 3}
+
+	tests := []struct {
+		name     string
+		policy   *finopsv1.AutoIdlePolicy
+		history  []finopsv1.MetricDataPoint
+		idle     bool
+		expected bool
+	}{
+		{"no policy", nil, history("1m", "1m", "1m"), false, false},
+		{"no history", policy, nil, false, false},
+		{"not enough points", policy, history("1m", "1m"), false, false},
+		{"idle after enough low points", policy, history("500m", "10m", "20m", "30m"), false, true},
+		{"one busy point keeps active", policy, history("10m", "60m", "10m"), false, false},
+		{"stays idle between thresholds", policy, history("10m", "10m", "100m"), true, true},
+		{"wakes up above activeAbove", policy, history("10m", "10m", "250m"), true, false},
+		{"default points", &finopsv1.AutoIdlePolicy{IdleBelowMillicores: 50, ActiveAboveMillicores: 200},
+			history("1m", "1m", "1m", "1m", "1m", "1m", "1m", "1m", "1m"), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EvaluateIdle(tt.policy, tt.history, tt.idle); got != tt.expected {
+				t.Errorf("EvaluateIdle() = %v; want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStatefulSetRollout(t *testing.T) {
+	e := buildMockEngine()
+	ctx := context.Background()
+
+	three, two := int32(3), int32(2)
+	ss := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "test-ns"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &three,
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: &two},
+			},
+		},
+		// All pods ready, but db-2 still runs the previous revision
+		Status: appsv1.StatefulSetStatus{
+			Replicas: 3, ReadyReplicas: 3, CurrentReplicas: 3, UpdatedReplicas: 0,
+			CurrentRevision: "db-1", UpdateRevision: "db-2",
+		},
+	}
+	e.Client.Create(ctx, ss)
+
+	if p := e.ComputePhase(ctx, "test-ns", true, nil, false, 0, nil); p != "ScalingUp" {
+		t.Errorf("Expected ScalingUp while the partition is rolling out, got %v", p)
+	}
+	if e.isGroupReady(ctx, []client.Object{ss.DeepCopy()}, true, downTargets{}) {
+		t.Error("Expected the StatefulSet not to be ready while the partition is rolling out")
+	}
+
+	// db-2 is updated, db-0 and db-1 stay on the current revision below the partition
+	ss.Status.CurrentReplicas, ss.Status.UpdatedReplicas = 2, 1
+	e.Client.Update(ctx, ss)
+	if p := e.ComputePhase(ctx, "test-ns", true, nil, false, 0, nil); p != "ScaledUp" {
+		t.Errorf("Expected ScaledUp once the pods from the partition up are updated, got %v", p)
+	}
+	if !e.isGroupReady(ctx, []client.Object{ss.DeepCopy()}, true, downTargets{}) {
+		t.Error("Expected the StatefulSet to be ready once the pods from the partition up are updated")
+	}
+
+	// OnDelete never rolls out by itself
+	ss.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
+	ss.Status.CurrentReplicas, ss.Status.UpdatedReplicas = 3, 0
+	if !statefulSetRolledOut(ss) {
+		t.Error("Expected an OnDelete StatefulSet to count as rolled out")
+	}
+}
+
+func TestJitterOffset(t *testing.T) {
+	if d := JitterOffset("non-prod", 0); d != 0 {
+		t.Errorf("Expected no offset without jitter, got %s", d)
+	}
+
+	offsets := map[time.Duration]bool{}
+	for _, name := range []string{"non-prod", "staging", "qa", "dev", "demo"} {
+		d := JitterOffset(name, 300)
+		if d < 0 || d > 300*time.Second {
+			t.Errorf("JitterOffset(%q) = %s; want within 0-300s", name, d)
+		}
+		if again := JitterOffset(name, 300); again != d {
+			t.Errorf("Expected the offset of %q to be stable, got %s then %s", name, d, again)
+		}
+		offsets[d] = true
+	}
+	if len(offsets) < 2 {
+		t.Errorf("Expected objects to be spread over the window, got %v", offsets)
+	}
+}
+
+func TestIsActiveJittered(t *testing.T) {
+	schedules := []finopsv1.ScalingSchedule{{Days: []int{0, 1, 2, 3, 4, 5, 6}, StartTime: "08:00", EndTime: "17:59"}}
+	clock := &fakeClock{now: time.Date(2026, 3, 2, 18, 0, 30, 0, time.UTC)}
+	e := &Engine{Clock: clock}
+
+	if e.IsActiveJittered(schedules, nil, 0) {
+		t.Error("Expected the window to be over without jitter")
+	}
+	if !e.IsActiveJittered(schedules, nil, 45*time.Second) {
+		t.Error("Expected a 45s offset to keep the window open until 18:00:45")
+	}
+	off := false
+	if e.IsActiveJittered(schedules, &off, 45*time.Second) {
+		t.Error("Expected the manual override not to be delayed")
+	}
+
+	at, active, ok := e.NextTransitionJittered(schedules, nil, clock.now, 45*time.Second)
+	if !ok || active || !at.Equal(time.Date(2026, 3, 2, 18, 0, 45, 0, time.UTC)) {
+		t.Errorf("Expected the window to close at 18:00:45, got %s (active %v, ok %v)", at, active, ok)
+	}
+}
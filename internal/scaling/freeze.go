@@ -0,0 +1,72 @@
+package scaling
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FreezeConfigMapName is the ConfigMap of the operator namespace holding the cluster-wide
+// scaling freeze. While frozen, the scaling reconcilers keep reporting the observed state
+// but change no replica counts, whatever the schedules and manual overrides say.
+const FreezeConfigMapName = "kubex-scaling-freeze"
+
+// Keys of the freeze ConfigMap
+const (
+	freezeFrozenKey = "frozen"
+	freezeReasonKey = "reason"
+	freezeSinceKey  = "since"
+	freezeByKey     = "by"
+)
+
+// Freeze is the state of the scaling freeze
+type Freeze struct {
+	Frozen bool   `json:"frozen"`
+	Reason string `json:"reason,omitempty"`
+	// Since is when the freeze was turned on
+	Since *metav1.Time `json:"since,omitempty"`
+	// By is the user who turned the freeze on through the API
+	By string `json:"by,omitempty"`
+}
+
+// FreezeFromConfigMap reads the freeze from its ConfigMap, not frozen when cm is nil.
+// Only "true" freezes, so the ConfigMap can also be written by hand:
+// kubectl create configmap kubex-scaling-freeze --from-literal=frozen=true
+func FreezeFromConfigMap(cm *corev1.ConfigMap) Freeze {
+	if cm == nil || cm.Data[freezeFrozenKey] != "true" {
+		return Freeze{}
+	}
+	f := Freeze{Frozen: true, Reason: cm.Data[freezeReasonKey], By: cm.Data[freezeByKey]}
+	if since, err := time.Parse(time.RFC3339, cm.Data[freezeSinceKey]); err == nil {
+		f.Since = &metav1.Time{Time: since}
+	} else {
+		// Fall back to when the ConfigMap was created
+		f.Since = cm.CreationTimestamp.DeepCopy()
+	}
+	return f
+}
+
+// ApplyTo writes the freeze into the data of its ConfigMap
+func (f Freeze) ApplyTo(cm *corev1.ConfigMap) {
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	for _, k := range []string{freezeFrozenKey, freezeReasonKey, freezeSinceKey, freezeByKey} {
+		delete(cm.Data, k)
+	}
+	cm.Data[freezeFrozenKey] = "false"
+	if !f.Frozen {
+		return
+	}
+	cm.Data[freezeFrozenKey] = "true"
+	if f.Reason != "" {
+		cm.Data[freezeReasonKey] = f.Reason
+	}
+	if f.Since != nil {
+		cm.Data[freezeSinceKey] = f.Since.UTC().Format(time.RFC3339)
+	}
+	if f.By != "" {
+		cm.Data[freezeByKey] = f.By
+	}
+}
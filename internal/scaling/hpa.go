@@ -0,0 +1,69 @@
+package scaling
+
+import (
+	"context"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// HPADisabledAnnotation marks an HPA whose target was scaled to zero by Kubex. The HPA
+// controller stops acting on a target with zero replicas by itself; the annotation records
+// that the HPA was enabled so that control is handed back to it on scale-up.
+const HPADisabledAnnotation = "finops.kubex.io/hpa-disabled"
+
+// listHPAs returns the HPAs of a namespace indexed by their scale target (Kind/name).
+func (e *Engine) listHPAs(ctx context.Context, ns string) (map[string]*autoscalingv2.HorizontalPodAutoscaler, error) {
+	list := &autoscalingv2.HorizontalPodAutoscalerList{}
+	if err := e.Client.List(ctx, list, client.InNamespace(ns)); err != nil {
+		return nil, err
+	}
+
+	hpas := make(map[string]*autoscalingv2.HorizontalPodAutoscaler, len(list.Items))
+	for i := range list.Items {
+		ref := list.Items[i].Spec.ScaleTargetRef
+		if ref.APIVersion != "" && ref.APIVersion != appsv1.SchemeGroupVersion.String() {
+			continue
+		}
+		hpas[ref.Kind+"/"+ref.Name] = &list.Items[i]
+	}
+	return hpas, nil
+}
+
+// hpaMinReplicas is the replica count handed back to an HPA on scale-up.
+func hpaMinReplicas(hpa *autoscalingv2.HorizontalPodAutoscaler) int32 {
+	if hpa.Spec.MinReplicas != nil && *hpa.Spec.MinReplicas > 0 {
+		return *hpa.Spec.MinReplicas
+	}
+	return 1
+}
+
+func isHPADisabled(hpa *autoscalingv2.HorizontalPodAutoscaler) bool {
+	_, ok := hpa.Annotations[HPADisabledAnnotation]
+	return ok
+}
+
+// disableHPA records that the HPA was active before its target is scaled down.
+func (e *Engine) disableHPA(ctx context.Context, hpa *autoscalingv2.HorizontalPodAutoscaler) error {
+	if isHPADisabled(hpa) {
+		return nil
+	}
+	patch := client.MergeFrom(hpa.DeepCopy())
+	if hpa.Annotations == nil {
+		hpa.Annotations = make(map[string]string)
+	}
+	hpa.Annotations[HPADisabledAnnotation] = e.Now().UTC().Format(time.RFC3339)
+	return e.Client.Patch(ctx, hpa, patch)
+}
+
+// enableHPA clears the marker set by disableHPA once the target runs again.
+func (e *Engine) enableHPA(ctx context.Context, hpa *autoscalingv2.HorizontalPodAutoscaler) error {
+	if !isHPADisabled(hpa) {
+		return nil
+	}
+	patch := client.MergeFrom(hpa.DeepCopy())
+	delete(hpa.Annotations, HPADisabledAnnotation)
+	return e.Client.Patch(ctx, hpa, patch)
+}
@@ -0,0 +1,50 @@
+package scaling
+
+import (
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// DefaultIdlePoints is how many consecutive idle history points scale a namespace down
+// when the auto-idle policy does not set IdlePoints
+const DefaultIdlePoints = 10
+
+// EvaluateIdle tells whether a namespace is idle under its auto-idle policy, from its
+// NamespaceFinOps history, oldest first, and whether it was idle so far. A namespace in use
+// becomes idle once its last IdlePoints points are all under IdleBelowMillicores, and an
+// idle one stays idle until the latest point reaches ActiveAboveMillicores. Without enough
+// history a namespace is never considered idle.
+func EvaluateIdle(policy *finopsv1.AutoIdlePolicy, history []finopsv1.MetricDataPoint, idle bool) bool {
+	if policy == nil || len(history) == 0 {
+		return false
+	}
+
+	if idle {
+		usage, ok := cpuMillicores(history[len(history)-1])
+		return !ok || usage < policy.ActiveAboveMillicores
+	}
+
+	points := int(policy.IdlePoints)
+	if points <= 0 {
+		points = DefaultIdlePoints
+	}
+	if len(history) < points {
+		return false
+	}
+	for _, p := range history[len(history)-points:] {
+		usage, ok := cpuMillicores(p)
+		if !ok || usage >= policy.IdleBelowMillicores {
+			return false
+		}
+	}
+	return true
+}
+
+// cpuMillicores parses the CPU usage of a history point
+func cpuMillicores(p finopsv1.MetricDataPoint) (int64, bool) {
+	q, err := resource.ParseQuantity(p.CPU.Usage)
+	if err != nil {
+		return 0, false
+	}
+	return q.MilliValue(), true
+}
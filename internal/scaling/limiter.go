@@ -0,0 +1,60 @@
+package scaling
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// ErrScaleThrottled is returned when every slot of the Limiter is taken
+var ErrScaleThrottled = errors.New("too many scale operations in flight")
+
+// Limiter caps the replica updates in flight across the engines sharing it, smoothing the
+// bursts of many schedules firing at once. A nil Limiter is unlimited.
+type Limiter struct {
+	slots chan struct{}
+}
+
+// NewLimiter returns a Limiter allowing max concurrent scale operations, nil (unlimited)
+// when max is 0 or less
+func NewLimiter(max int) *Limiter {
+	if max <= 0 {
+		return nil
+	}
+	return &Limiter{slots: make(chan struct{}, max)}
+}
+
+// MaxConcurrentScalesFromEnv reads KUBEX_MAX_CONCURRENT_SCALES, 0 (unlimited) when unset
+func MaxConcurrentScalesFromEnv() (int, error) {
+	v := os.Getenv("KUBEX_MAX_CONCURRENT_SCALES")
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid KUBEX_MAX_CONCURRENT_SCALES %q, expected a number, 0 for no limit", v)
+	}
+	return n, nil
+}
+
+// TryAcquire takes a slot without waiting and reports whether it got one
+func (l *Limiter) TryAcquire() bool {
+	if l == nil {
+		return true
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Release frees a slot taken by TryAcquire
+func (l *Limiter) Release() {
+	if l == nil {
+		return
+	}
+	<-l.slots
+}
@@ -0,0 +1,35 @@
+package scaling
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
+
+// WorkloadOwner resolves the Deployment or StatefulSet owning a pod from its owner
+// references, following ReplicaSets up to their Deployment. Both are empty for pods
+// without such an owner.
+func WorkloadOwner(ctx context.Context, c client.Reader, ns string, owners []metav1.OwnerReference) (kind, name string) {
+	for _, or := range owners {
+		if or.Kind == "ReplicaSet" {
+			// Get RS to find Deployment
+			var rs appsv1.ReplicaSet
+			if err := c.Get(ctx, client.ObjectKey{Name: or.Name, Namespace: ns}, &rs); err == nil {
+				for _, rsor := range rs.OwnerReferences {
+					if rsor.Kind == "Deployment" {
+						name = rsor.Name
+						kind = "Deployment"
+					}
+				}
+			}
+		} else if or.Kind == "StatefulSet" {
+			name = or.Name
+			kind = "StatefulSet"
+		}
+	}
+	return kind, name
+}
@@ -0,0 +1,101 @@
+package scaling
+
+import (
+	"math"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// downReplicas is the replica count a workload keeps while scaled down to percent of its
+// original count: rounded, at least 1 and never above the original. A percent of 0
+// means a full shutdown.
+func downReplicas(original, percent int32) int32 {
+	if percent <= 0 || original <= 0 {
+		return 0
+	}
+	target := int32(math.Round(float64(original) * float64(percent) / 100))
+	return max(1, min(target, original))
+}
+
+// downTargets resolves what "scaled down" means for each workload when a namespace keeps
+// a percentage of its replicas instead of going to zero, and which counts a stepped
+// scale-up still has to reach.
+type downTargets struct {
+	percent   int32
+	originals map[string]int32
+	// ramp holds the final replicas of the workloads scaled up in steps, by replicaKey
+	ramp map[string]int32
+}
+
+// target returns the scaled-down replica count of obj. It is derived from the recorded
+// original replicas, or from the current count before the first scale-down recorded them.
+func (d downTargets) target(obj client.Object, replicas int32) int32 {
+	if d.percent <= 0 {
+		return 0
+	}
+	if original, ok := d.originals[replicaKey(obj)]; ok {
+		replicas = original
+	}
+	return downReplicas(replicas, d.percent)
+}
+
+// restoring reports whether obj still runs below the original count recorded by a partial
+// scale-down or below the final count of a stepped scale-up, in which case scaling up must
+// bring that count back.
+func (d downTargets) restoring(obj client.Object, replicas int32) bool {
+	if final, ok := d.ramp[replicaKey(obj)]; ok && replicas < final {
+		return true
+	}
+	if d.percent <= 0 {
+		return false
+	}
+	original, ok := d.originals[replicaKey(obj)]
+	return ok && replicas < original
+}
+
+// rampTargets returns the final replicas of the workloads a stepped scale-up ramps: those
+// with recorded original replicas and no HPA, which takes over scaling them instead.
+func rampTargets(workloads []client.Object, hpas map[string]*autoscalingv2.HorizontalPodAutoscaler, originals map[string]int32) map[string]int32 {
+	ramp := make(map[string]int32)
+	for _, obj := range workloads {
+		original, ok := originals[replicaKey(obj)]
+		if !ok || hpas[workloadRef(obj)] != nil {
+			continue
+		}
+		ramp[replicaKey(obj)] = max(original, minReplicas(obj))
+	}
+	return ramp
+}
+
+// stepUpTarget returns the next replica count of a workload ramped up to final in steps of
+// percent of final: one step more than now, once the pods of the current step are ready.
+func stepUpTarget(obj client.Object, current, final, percent int32) int32 {
+	if current > 0 && readyReplicas(obj) < current {
+		// Pods of the previous step are still starting
+		return current
+	}
+	step := max(1, int32(math.Ceil(float64(final)*float64(percent)/100)))
+	return min(current+step, final)
+}
+
+// readyReplicas is the number of ready pods reported in the status of a workload
+func readyReplicas(obj client.Object) int32 {
+	switch v := obj.(type) {
+	case *appsv1.Deployment:
+		return v.Status.ReadyReplicas
+	case *appsv1.StatefulSet:
+		return v.Status.ReadyReplicas
+	case *appsv1.ReplicaSet:
+		return v.Status.ReadyReplicas
+	case *unstructured.Unstructured:
+		ready, found, _ := unstructured.NestedInt64(v.Object, "status", "readyReplicas")
+		if !found {
+			ready, _, _ = unstructured.NestedInt64(v.Object, "status", "replicas")
+		}
+		return int32(ready)
+	}
+	return 0
+}
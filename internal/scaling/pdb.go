@@ -0,0 +1,78 @@
+package scaling
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// listPDBs returns the PodDisruptionBudgets of a namespace.
+func (e *Engine) listPDBs(ctx context.Context, ns string) ([]policyv1.PodDisruptionBudget, error) {
+	list := &policyv1.PodDisruptionBudgetList{}
+	if err := e.Client.List(ctx, list, client.InNamespace(ns)); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// matchingPDB returns the first PodDisruptionBudget selecting the pods of a workload.
+func matchingPDB(obj client.Object, pdbs []policyv1.PodDisruptionBudget) *policyv1.PodDisruptionBudget {
+	var podLabels map[string]string
+	switch v := obj.(type) {
+	case *appsv1.Deployment:
+		podLabels = v.Spec.Template.Labels
+	case *appsv1.StatefulSet:
+		podLabels = v.Spec.Template.Labels
+	case *appsv1.ReplicaSet:
+		podLabels = v.Spec.Template.Labels
+	case *unstructured.Unstructured:
+		podLabels, _, _ = unstructured.NestedStringMap(v.Object, "spec", "template", "metadata", "labels")
+	}
+	if len(podLabels) == 0 {
+		return nil
+	}
+
+	for i := range pdbs {
+		if pdbs[i].Spec.Selector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdbs[i].Spec.Selector)
+		if err != nil || selector.Empty() {
+			continue
+		}
+		if selector.Matches(labels.Set(podLabels)) {
+			return &pdbs[i]
+		}
+	}
+	return nil
+}
+
+// gradualScaleDownTarget returns the next replica count for a workload protected by a PDB:
+// one replica less than now, once the previous step has fully terminated.
+func gradualScaleDownTarget(obj client.Object, current int32) int32 {
+	var observed int32
+	switch v := obj.(type) {
+	case *appsv1.Deployment:
+		observed = v.Status.Replicas
+	case *appsv1.StatefulSet:
+		observed = v.Status.Replicas
+	case *appsv1.ReplicaSet:
+		observed = v.Status.Replicas
+	case *unstructured.Unstructured:
+		replicas, _, _ := unstructured.NestedInt64(v.Object, "status", "replicas")
+		observed = int32(replicas)
+	}
+	if observed > current {
+		// Pods of the previous step are still terminating
+		return current
+	}
+	if current > 0 {
+		return current - 1
+	}
+	return 0
+}
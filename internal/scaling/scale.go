@@ -0,0 +1,153 @@
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ParseScaleKind parses a workload kind managed through the scale subresource, written as
+// "group/version:Kind" (e.g. "argoproj.io/v1alpha1:Rollout").
+func ParseScaleKind(s string) (schema.GroupVersionKind, error) {
+	gv, kind, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok || gv == "" || kind == "" {
+		return schema.GroupVersionKind{}, fmt.Errorf("invalid scale kind %q, expected group/version:Kind", s)
+	}
+	parsed, err := schema.ParseGroupVersion(gv)
+	if err != nil {
+		return schema.GroupVersionKind{}, fmt.Errorf("invalid scale kind %q: %w", s, err)
+	}
+	return parsed.WithKind(kind), nil
+}
+
+// listWorkloads returns the Deployments and StatefulSets of a namespace, its standalone
+// ReplicaSets when replicaSets is set, followed by the objects of every additional scale kind.
+func (e *Engine) listWorkloads(ctx context.Context, ns string, scaleKinds []string, replicaSets bool) ([]client.Object, error) {
+	var workloads []client.Object
+
+	deployments := &appsv1.DeploymentList{}
+	if err := e.Client.List(ctx, deployments, client.InNamespace(ns)); err != nil {
+		return nil, err
+	}
+	for i := range deployments.Items {
+		workloads = append(workloads, &deployments.Items[i])
+	}
+
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := e.Client.List(ctx, statefulSets, client.InNamespace(ns)); err != nil {
+		return nil, err
+	}
+	for i := range statefulSets.Items {
+		workloads = append(workloads, &statefulSets.Items[i])
+	}
+
+	if replicaSets {
+		list, err := e.standaloneReplicaSets(ctx, ns)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list {
+			workloads = append(workloads, &list[i])
+		}
+	}
+
+	for _, k := range scaleKinds {
+		gvk, err := ParseScaleKind(k)
+		if err != nil {
+			return nil, err
+		}
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := e.Client.List(ctx, list, client.InNamespace(ns)); err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			list.Items[i].SetGroupVersionKind(gvk)
+			workloads = append(workloads, &list.Items[i])
+		}
+	}
+	return workloads, nil
+}
+
+// standaloneReplicaSets lists the ReplicaSets of a namespace no controller owns. Those of
+// Deployments are scaled through their Deployment and must not be counted twice.
+func (e *Engine) standaloneReplicaSets(ctx context.Context, ns string) ([]appsv1.ReplicaSet, error) {
+	list := &appsv1.ReplicaSetList{}
+	if err := e.Client.List(ctx, list, client.InNamespace(ns)); err != nil {
+		return nil, err
+	}
+	standalone := list.Items[:0]
+	for _, rs := range list.Items {
+		if metav1.GetControllerOf(&rs) == nil {
+			standalone = append(standalone, rs)
+		}
+	}
+	return standalone, nil
+}
+
+// getScale reads the scale subresource of a generic workload.
+func (e *Engine) getScale(ctx context.Context, obj client.Object) (*autoscalingv1.Scale, error) {
+	scale := &autoscalingv1.Scale{}
+	if err := e.Client.SubResource("scale").Get(ctx, obj, scale); err != nil {
+		return nil, err
+	}
+	return scale, nil
+}
+
+// setScale writes the replica count of a generic workload through its scale subresource.
+func (e *Engine) setScale(ctx context.Context, obj client.Object, count int32) error {
+	scale := &autoscalingv1.Scale{Spec: autoscalingv1.ScaleSpec{Replicas: count}}
+	return e.Client.SubResource("scale").Update(ctx, obj, client.WithSubResourceBody(scale))
+}
+
+// isScaleReady reports whether a generic workload reached the target state. Readiness is
+// taken from status.readyReplicas when the kind exposes it, as Rollouts do, and from the
+// replica count of the scale subresource otherwise.
+func (e *Engine) isScaleReady(ctx context.Context, obj *unstructured.Unstructured, targetActive bool, down downTargets) bool {
+	if err := e.Client.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		return false
+	}
+	scale, err := e.getScale(ctx, obj)
+	if err != nil {
+		return false
+	}
+
+	if targetActive {
+		if scale.Spec.Replicas == 0 || down.restoring(obj, scale.Spec.Replicas) {
+			return false
+		}
+		ready, found, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+		if !found {
+			ready = int64(scale.Status.Replicas)
+		}
+		return ready >= int64(scale.Spec.Replicas)
+	}
+
+	if target := down.target(obj, scale.Spec.Replicas); target > 0 {
+		return scaledDown(scale.Spec.Replicas, scale.Status.Replicas, target)
+	}
+	if scale.Spec.Replicas > 0 || scale.Status.Replicas > 0 {
+		return false
+	}
+	if scale.Status.Selector != "" {
+		selector, err := labels.Parse(scale.Status.Selector)
+		if err != nil {
+			return false
+		}
+		pods := &corev1.PodList{}
+		if err := e.Client.List(ctx, pods, client.InNamespace(obj.GetNamespace()), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return false
+		}
+		return len(pods.Items) == 0
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package scaling
+
+import (
+	"hash/fnv"
+	"sort"
+	"time"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+)
+
+// NextTransition returns the soonest time after now at which the schedules flip the desired
+// state, together with the state entered at that time. ok is false when the state never
+// changes, i.e. with a manual override or without valid schedules.
+func (e *Engine) NextTransition(schedules []finopsv1.ScalingSchedule, manualActive *bool, now time.Time) (at time.Time, active bool, ok bool) {
+	if manualActive != nil {
+		return time.Time{}, false, false
+	}
+
+	// The state can only change where a window starts or the minute after it ends, so
+	// checking those boundaries for the coming week covers every schedule
+	var boundaries []time.Time
+	for _, s := range schedules {
+		if len(s.Days) == 0 {
+			continue
+		}
+		local := now
+		if s.Timezone != "" {
+			if loc, err := time.LoadLocation(s.Timezone); err == nil {
+				local = now.In(loc)
+			}
+		}
+		startMin := parseMinutes(s.StartTime)
+		endMin := parseMinutes(s.EndTime) + 1
+		for d := 0; d <= 7; d++ {
+			for _, m := range []int{startMin, endMin} {
+				b := time.Date(local.Year(), local.Month(), local.Day()+d, 0, m, 0, 0, local.Location())
+				if b.After(now) {
+					boundaries = append(boundaries, b)
+				}
+			}
+		}
+	}
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i].Before(boundaries[j]) })
+
+	current := e.IsActiveAt(schedules, nil, now)
+	for _, b := range boundaries {
+		if state := e.IsActiveAt(schedules, nil, b); state != current {
+			return b, state, true
+		}
+	}
+	return time.Time{}, false, false
+}
+
+// JitterOffset returns how long the schedule boundaries of the object called name are
+// delayed, between 0 and seconds. It is derived from a hash of the name rather than drawn
+// at random, so an object flips at the same offset on every reconcile while objects
+// sharing a schedule are spread over the window.
+func JitterOffset(name string, seconds int32) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return time.Duration(h.Sum32()%uint32(seconds+1)) * time.Second
+}
+
+// IsActiveJittered is IsActive with the schedule boundaries delayed by offset, see
+// JitterOffset. The manual override is not delayed.
+func (e *Engine) IsActiveJittered(schedules []finopsv1.ScalingSchedule, manualActive *bool, offset time.Duration) bool {
+	return e.IsActiveAt(schedules, manualActive, e.Now().Add(-offset))
+}
+
+// NextTransitionJittered is NextTransition with the schedule boundaries delayed by offset
+func (e *Engine) NextTransitionJittered(schedules []finopsv1.ScalingSchedule, manualActive *bool, now time.Time, offset time.Duration) (at time.Time, active bool, ok bool) {
+	at, active, ok = e.NextTransition(schedules, manualActive, now.Add(-offset))
+	if !ok {
+		return time.Time{}, false, false
+	}
+	return at.Add(offset), active, true
+}
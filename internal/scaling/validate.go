@@ -0,0 +1,179 @@
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SequenceMatch lists what a single sequence entry resolves to
+type SequenceMatch struct {
+	Entry   string   `json:"entry"`
+	Matches []string `json:"matches"`
+}
+
+// ValidationResult describes how a ScalingConfig or ScalingGroup spec resolves against the cluster
+type ValidationResult struct {
+	Sequence []SequenceMatch `json:"sequence"`
+	// Unsequenced are resources not matched by any entry, scaled in the last stage
+	Unsequenced []string `json:"unsequenced,omitempty"`
+	// Excluded are workloads skipped because of the exclusions
+	Excluded []string `json:"excluded,omitempty"`
+	Warnings []string `json:"warnings"`
+}
+
+// ValidateConfig resolves the sequence and exclusions of a ScalingConfig spec against the
+// workloads of its target namespace, using the same matching as ScaleTarget.
+func (e *Engine) ValidateConfig(ctx context.Context, spec finopsv1.ScalingConfigSpec) (*ValidationResult, error) {
+	result := &ValidationResult{Sequence: []SequenceMatch{}, Warnings: []string{}}
+	for _, entry := range spec.Sequence {
+		result.Sequence = append(result.Sequence, SequenceMatch{Entry: entry, Matches: []string{}})
+	}
+
+	exists, err := e.namespaceExists(ctx, spec.TargetNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Namespace %q does not exist", spec.TargetNamespace))
+		return result, nil
+	}
+
+	workloads, err := e.listWorkloads(ctx, spec.TargetNamespace, spec.ScaleKinds, spec.ScaleReplicaSets)
+	if err != nil {
+		return nil, err
+	}
+
+	usedExclusions := make(map[string]bool)
+	for _, obj := range workloads {
+		ref := workloadRef(obj)
+		if isExcluded(obj.GetName(), spec.Exclusions) {
+			result.Excluded = append(result.Excluded, ref)
+			for _, ex := range spec.Exclusions {
+				if isExcluded(obj.GetName(), []string{ex}) {
+					usedExclusions[ex] = true
+				}
+			}
+			continue
+		}
+
+		idx := getSequenceIndex(obj, spec.Sequence)
+		if idx < len(result.Sequence) {
+			result.Sequence[idx].Matches = append(result.Sequence[idx].Matches, ref)
+		} else {
+			result.Unsequenced = append(result.Unsequenced, ref)
+		}
+	}
+
+	for _, m := range result.Sequence {
+		if len(m.Matches) == 0 {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Sequence entry %q matches no workloads in namespace %s", m.Entry, spec.TargetNamespace))
+		}
+	}
+	for _, ex := range spec.Exclusions {
+		if strings.TrimSpace(ex) != "" && !usedExclusions[ex] {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Exclusion %q matches no workloads in namespace %s", ex, spec.TargetNamespace))
+		}
+	}
+	if spec.AutoIdle != nil && spec.ScaleDownReplicaPercent == 0 && len(result.Excluded) == 0 {
+		result.Warnings = append(result.Warnings, "Auto-idle scales every workload to zero, only a manual override or the schedule can bring the namespace back up; set scaleDownReplicaPercent to keep some usage")
+	}
+
+	return result, nil
+}
+
+// ValidateGroup resolves the stages of a ScalingGroup spec, checking that every namespace
+// exists and is managed by the group and that external targets are declared.
+func (e *Engine) ValidateGroup(ctx context.Context, spec finopsv1.ScalingGroupSpec) (*ValidationResult, error) {
+	result := &ValidationResult{Sequence: []SequenceMatch{}, Warnings: []string{}}
+
+	managed := make(map[string]bool)
+	for _, ns := range spec.Namespaces {
+		managed[ns] = true
+		exists, err := e.namespaceExists(ctx, ns)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Namespace %q does not exist", ns))
+		}
+	}
+
+	sequenced := make(map[string]bool)
+	for _, entry := range spec.Sequence {
+		match := SequenceMatch{Entry: entry, Matches: []string{}}
+		for _, target := range strings.Fields(entry) {
+			if strings.HasPrefix(target, "ext:") {
+				id := strings.TrimPrefix(target, "ext:")
+				found := false
+				for _, ext := range spec.ExternalTargets {
+					if ext.Identifier == id {
+						found = true
+						break
+					}
+				}
+				if !found {
+					result.Warnings = append(result.Warnings, fmt.Sprintf("External target %q in sequence is not declared in externalTargets", id))
+					continue
+				}
+				match.Matches = append(match.Matches, target)
+				continue
+			}
+
+			if !managed[target] {
+				exists, err := e.namespaceExists(ctx, target)
+				if err != nil {
+					return nil, err
+				}
+				if !exists {
+					result.Warnings = append(result.Warnings, fmt.Sprintf("Namespace %q in sequence does not exist", target))
+					continue
+				}
+				result.Warnings = append(result.Warnings, fmt.Sprintf("Namespace %q is scaled by the sequence but is not listed in the group's namespaces", target))
+			}
+			sequenced[target] = true
+			match.Matches = append(match.Matches, target)
+		}
+		if len(match.Matches) == 0 {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Sequence entry %q matches no namespaces or external targets", entry))
+		}
+		result.Sequence = append(result.Sequence, match)
+	}
+
+	if len(spec.Sequence) > 0 {
+		for _, ns := range spec.Namespaces {
+			if !sequenced[ns] {
+				result.Unsequenced = append(result.Unsequenced, ns)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (e *Engine) namespaceExists(ctx context.Context, name string) (bool, error) {
+	if name == "" {
+		return false, nil
+	}
+	ns := &corev1.Namespace{}
+	if err := e.Client.Get(ctx, client.ObjectKey{Name: name}, ns); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// workloadRef identifies a workload as Kind/name.
+func workloadRef(obj client.Object) string {
+	if kind := workloadGVK(obj).Kind; kind != "" {
+		return kind + "/" + obj.GetName()
+	}
+	return obj.GetName()
+}
@@ -0,0 +1,49 @@
+// Package watchns restricts the operator to the namespaces listed in WATCH_NAMESPACES, so
+// that it can run with namespaced RBAC instead of cluster-wide access.
+package watchns
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+// Namespaces are the namespaces the operator watches, empty meaning every namespace
+type Namespaces []string
+
+// FromEnv reads the comma separated namespaces of WATCH_NAMESPACES, nil when unset.
+func FromEnv() Namespaces {
+	var namespaces Namespaces
+	for _, ns := range strings.Split(os.Getenv("WATCH_NAMESPACES"), ",") {
+		if ns = strings.TrimSpace(ns); ns != "" && !slices.Contains(namespaces, ns) {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
+// Restricted reports whether only some namespaces are watched
+func (n Namespaces) Restricted() bool {
+	return len(n) > 0
+}
+
+// Allows reports whether a namespace is watched
+func (n Namespaces) Allows(ns string) bool {
+	return !n.Restricted() || slices.Contains(n, ns)
+}
+
+// CacheNamespaces returns the namespaces the manager cache is restricted to: the watched
+// ones and the operator namespace holding the custom resources. It is nil when every
+// namespace is watched.
+func (n Namespaces) CacheNamespaces(operatorNs string) map[string]cache.Config {
+	if !n.Restricted() {
+		return nil
+	}
+	namespaces := map[string]cache.Config{operatorNs: {}}
+	for _, ns := range n {
+		namespaces[ns] = cache.Config{}
+	}
+	return namespaces
+}
@@ -0,0 +1,30 @@
+package watchns
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("WATCH_NAMESPACES", "")
+	if n := FromEnv(); n.Restricted() || !n.Allows("anything") {
+		t.Errorf("expected every namespace to be watched when unset, got %v", n)
+	}
+
+	t.Setenv("WATCH_NAMESPACES", "team-a, team-b,,team-a")
+	n := FromEnv()
+	if !slices.Equal(n, Namespaces{"team-a", "team-b"}) {
+		t.Fatalf("FromEnv() = %v; want [team-a team-b]", n)
+	}
+	if !n.Allows("team-b") || n.Allows("team-c") {
+		t.Errorf("expected only team-a and team-b to be allowed")
+	}
+
+	cached := n.CacheNamespaces("kubex")
+	if len(cached) != 3 {
+		t.Errorf("expected the operator namespace to be cached too, got %v", cached)
+	}
+	if _, ok := cached["kubex"]; !ok {
+		t.Errorf("expected kubex in the cached namespaces, got %v", cached)
+	}
+}
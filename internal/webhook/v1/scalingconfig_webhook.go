@@ -0,0 +1,71 @@
+/*
+Copyright 2026 migalsp.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+)
+
+// SetupScalingConfigWebhookWithManager registers the validating webhook for ScalingConfig.
+func SetupScalingConfigWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &finopsv1.ScalingConfig{}).
+		WithValidator(&ScalingConfigCustomValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-finops-kubex-io-v1-scalingconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=finops.kubex.io,resources=scalingconfigs,verbs=create;update,versions=v1,name=vscalingconfig-v1.kb.io,admissionReviewVersions=v1
+
+// ScalingConfigCustomValidator rejects ScalingConfigs whose schedules can never behave as written
+type ScalingConfigCustomValidator struct{}
+
+// ValidateCreate implements admission.Validator.
+func (v *ScalingConfigCustomValidator) ValidateCreate(_ context.Context, config *finopsv1.ScalingConfig) (admission.Warnings, error) {
+	return nil, validateScalingConfig(config)
+}
+
+// ValidateUpdate implements admission.Validator.
+func (v *ScalingConfigCustomValidator) ValidateUpdate(_ context.Context, _, config *finopsv1.ScalingConfig) (admission.Warnings, error) {
+	return nil, validateScalingConfig(config)
+}
+
+// ValidateDelete implements admission.Validator.
+func (v *ScalingConfigCustomValidator) ValidateDelete(_ context.Context, _ *finopsv1.ScalingConfig) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateScalingConfig(config *finopsv1.ScalingConfig) error {
+	spec := field.NewPath("spec")
+	errs := validateSchedules(spec.Child("schedules"), config.Spec.Schedules)
+	for i, c := range config.Spec.ConditionalExclusions {
+		errs = append(errs, validateSchedules(spec.Child("conditionalExclusions").Index(i).Child("schedules"), c.Schedules)...)
+	}
+	if p := config.Spec.AutoIdle; p != nil && p.ActiveAboveMillicores <= p.IdleBelowMillicores {
+		errs = append(errs, field.Invalid(spec.Child("autoIdle", "activeAboveMillicores"), p.ActiveAboveMillicores,
+			"must be above idleBelowMillicores so that the namespace does not flap between idle and active"))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(finopsv1.GroupVersion.WithKind("ScalingConfig").GroupKind(), config.Name, errs)
+}
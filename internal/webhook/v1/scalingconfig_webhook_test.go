@@ -0,0 +1,93 @@
+/*
+Copyright 2026 migalsp.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+)
+
+func TestScalingConfigValidator(t *testing.T) {
+	v := &ScalingConfigCustomValidator{}
+	weekdays := []int{1, 2, 3, 4, 5}
+
+	tests := []struct {
+		name     string
+		schedule finopsv1.ScalingSchedule
+		wantErr  string
+	}{
+		{name: "valid window", schedule: finopsv1.ScalingSchedule{Days: weekdays, StartTime: "08:00", EndTime: "18:00", Timezone: "Europe/Berlin"}},
+		{name: "flagged overnight window", schedule: finopsv1.ScalingSchedule{Days: weekdays, StartTime: "22:00", EndTime: "06:00", Overnight: true}},
+		{name: "end before start", schedule: finopsv1.ScalingSchedule{Days: weekdays, StartTime: "22:00", EndTime: "06:00"}, wantErr: "spec.schedules[0].endTime"},
+		{name: "empty window", schedule: finopsv1.ScalingSchedule{Days: weekdays, StartTime: "08:00", EndTime: "08:00"}, wantErr: "spec.schedules[0].endTime"},
+		{name: "overnight flag on a daytime window", schedule: finopsv1.ScalingSchedule{Days: weekdays, StartTime: "08:00", EndTime: "18:00", Overnight: true}, wantErr: "overnight"},
+		{name: "day out of range", schedule: finopsv1.ScalingSchedule{Days: []int{1, 7}, StartTime: "08:00", EndTime: "18:00"}, wantErr: "spec.schedules[0].days[1]"},
+		{name: "unknown timezone", schedule: finopsv1.ScalingSchedule{Days: weekdays, StartTime: "08:00", EndTime: "18:00", Timezone: "Mars/Olympus"}, wantErr: "spec.schedules[0].timezone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &finopsv1.ScalingConfig{Spec: finopsv1.ScalingConfigSpec{
+				TargetNamespace: "backend",
+				Schedules:       []finopsv1.ScalingSchedule{tt.schedule},
+			}}
+			_, err := v.ValidateCreate(context.Background(), config)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error mentioning %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScalingConfigValidatorConditionalExclusions(t *testing.T) {
+	config := &finopsv1.ScalingConfig{Spec: finopsv1.ScalingConfigSpec{
+		TargetNamespace: "backend",
+		ConditionalExclusions: []finopsv1.ConditionalExclusion{{
+			Names:     []string{"worker"},
+			Schedules: []finopsv1.ScalingSchedule{{Days: []int{1}, StartTime: "18:00", EndTime: "09:00"}},
+		}},
+	}}
+	_, err := (&ScalingConfigCustomValidator{}).ValidateUpdate(context.Background(), config, config)
+	if err == nil || !strings.Contains(err.Error(), "spec.conditionalExclusions[0].schedules[0].endTime") {
+		t.Fatalf("expected conditional exclusion schedule to be rejected, got %v", err)
+	}
+}
+
+func TestScalingConfigValidatorAutoIdle(t *testing.T) {
+	config := &finopsv1.ScalingConfig{Spec: finopsv1.ScalingConfigSpec{
+		TargetNamespace: "backend",
+		AutoIdle:        &finopsv1.AutoIdlePolicy{IdleBelowMillicores: 50, ActiveAboveMillicores: 50},
+	}}
+	_, err := (&ScalingConfigCustomValidator{}).ValidateCreate(context.Background(), config)
+	if err == nil || !strings.Contains(err.Error(), "spec.autoIdle.activeAboveMillicores") {
+		t.Fatalf("expected thresholds without a gap to be rejected, got %v", err)
+	}
+
+	config.Spec.AutoIdle.ActiveAboveMillicores = 200
+	if _, err := (&ScalingConfigCustomValidator{}).ValidateCreate(context.Background(), config); err != nil {
+		t.Errorf("expected a valid auto-idle policy, got %v", err)
+	}
+}
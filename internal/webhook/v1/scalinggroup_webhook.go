@@ -0,0 +1,74 @@
+/*
+Copyright 2026 migalsp.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+)
+
+// SetupScalingGroupWebhookWithManager registers the validating webhook for ScalingGroup.
+func SetupScalingGroupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, &finopsv1.ScalingGroup{}).
+		WithValidator(&ScalingGroupCustomValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-finops-kubex-io-v1-scalinggroup,mutating=false,failurePolicy=fail,sideEffects=None,groups=finops.kubex.io,resources=scalinggroups,verbs=create;update,versions=v1,name=vscalinggroup-v1.kb.io,admissionReviewVersions=v1
+
+// ScalingGroupCustomValidator rejects ScalingGroups without namespaces or with unusable schedules
+type ScalingGroupCustomValidator struct{}
+
+// ValidateCreate implements admission.Validator.
+func (v *ScalingGroupCustomValidator) ValidateCreate(_ context.Context, group *finopsv1.ScalingGroup) (admission.Warnings, error) {
+	return nil, validateScalingGroup(group)
+}
+
+// ValidateUpdate implements admission.Validator.
+func (v *ScalingGroupCustomValidator) ValidateUpdate(_ context.Context, _, group *finopsv1.ScalingGroup) (admission.Warnings, error) {
+	return nil, validateScalingGroup(group)
+}
+
+// ValidateDelete implements admission.Validator.
+func (v *ScalingGroupCustomValidator) ValidateDelete(_ context.Context, _ *finopsv1.ScalingGroup) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateScalingGroup(group *finopsv1.ScalingGroup) error {
+	spec := field.NewPath("spec")
+	var errs field.ErrorList
+	if len(group.Spec.Namespaces) == 0 && group.Spec.NamespaceSelector == nil {
+		errs = append(errs, field.Required(spec.Child("namespaces"), "a group must list namespaces or set a namespaceSelector"))
+	}
+	if group.Spec.NamespaceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(group.Spec.NamespaceSelector); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("namespaceSelector"), group.Spec.NamespaceSelector, err.Error()))
+		}
+	}
+	errs = append(errs, validateSchedules(spec.Child("schedules"), group.Spec.Schedules)...)
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(finopsv1.GroupVersion.WithKind("ScalingGroup").GroupKind(), group.Name, errs)
+}
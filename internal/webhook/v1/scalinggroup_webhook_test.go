@@ -0,0 +1,66 @@
+/*
+Copyright 2026 migalsp.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+)
+
+func TestScalingGroupValidator(t *testing.T) {
+	v := &ScalingGroupCustomValidator{}
+	schedules := []finopsv1.ScalingSchedule{{Days: []int{1, 2, 3, 4, 5}, StartTime: "08:00", EndTime: "18:00"}}
+
+	valid := &finopsv1.ScalingGroup{Spec: finopsv1.ScalingGroupSpec{Namespaces: []string{"backend"}, Schedules: schedules}}
+	if _, err := v.ValidateCreate(context.Background(), valid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	empty := &finopsv1.ScalingGroup{Spec: finopsv1.ScalingGroupSpec{Schedules: schedules}}
+	if _, err := v.ValidateCreate(context.Background(), empty); err == nil || !strings.Contains(err.Error(), "spec.namespaces") {
+		t.Fatalf("expected empty namespaces to be rejected, got %v", err)
+	}
+
+	bySelector := &finopsv1.ScalingGroup{Spec: finopsv1.ScalingGroupSpec{
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"solution": "shop"}},
+	}}
+	if _, err := v.ValidateCreate(context.Background(), bySelector); err != nil {
+		t.Fatalf("expected a selector without namespaces to be accepted, got %v", err)
+	}
+
+	badSelector := &finopsv1.ScalingGroup{Spec: finopsv1.ScalingGroupSpec{
+		NamespaceSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "solution", Operator: "Near"},
+		}},
+	}}
+	if _, err := v.ValidateCreate(context.Background(), badSelector); err == nil || !strings.Contains(err.Error(), "spec.namespaceSelector") {
+		t.Fatalf("expected an invalid selector to be rejected, got %v", err)
+	}
+
+	badSchedule := &finopsv1.ScalingGroup{Spec: finopsv1.ScalingGroupSpec{
+		Namespaces: []string{"backend"},
+		Schedules:  []finopsv1.ScalingSchedule{{Days: []int{1}, StartTime: "18:00", EndTime: "08:00"}},
+	}}
+	if _, err := v.ValidateUpdate(context.Background(), valid, badSchedule); err == nil || !strings.Contains(err.Error(), "spec.schedules[0].endTime") {
+		t.Fatalf("expected inverted window to be rejected, got %v", err)
+	}
+}
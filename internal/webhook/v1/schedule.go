@@ -0,0 +1,78 @@
+/*
+Copyright 2026 migalsp.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	finopsv1 "github.com/migalsp/kubex-operator/api/v1"
+)
+
+// validateSchedules checks the days, window and timezone of every schedule.
+func validateSchedules(path *field.Path, schedules []finopsv1.ScalingSchedule) field.ErrorList {
+	var errs field.ErrorList
+	for i, s := range schedules {
+		p := path.Index(i)
+
+		for j, d := range s.Days {
+			if d < 0 || d > 6 {
+				errs = append(errs, field.Invalid(p.Child("days").Index(j), d, "must be between 0 (Sunday) and 6 (Saturday)"))
+			}
+		}
+
+		start, startErr := parseClock(s.StartTime)
+		if startErr != nil {
+			errs = append(errs, field.Invalid(p.Child("startTime"), s.StartTime, startErr.Error()))
+		}
+		end, endErr := parseClock(s.EndTime)
+		if endErr != nil {
+			errs = append(errs, field.Invalid(p.Child("endTime"), s.EndTime, endErr.Error()))
+		}
+		if startErr == nil && endErr == nil {
+			if s.Overnight && end >= start {
+				errs = append(errs, field.Invalid(p.Child("endTime"), s.EndTime, "must be before startTime for an overnight window"))
+			} else if !s.Overnight && end <= start {
+				errs = append(errs, field.Invalid(p.Child("endTime"), s.EndTime, "must be after startTime, set overnight for a window running past midnight"))
+			}
+		}
+
+		if s.Timezone != "" {
+			if _, err := time.LoadLocation(s.Timezone); err != nil {
+				errs = append(errs, field.Invalid(p.Child("timezone"), s.Timezone, "unknown timezone"))
+			}
+		}
+	}
+	return errs
+}
+
+// ValidateSchedules checks schedules on their own, outside of a ScalingConfig or
+// ScalingGroup.
+func ValidateSchedules(schedules []finopsv1.ScalingSchedule) error {
+	return validateSchedules(field.NewPath("schedules"), schedules).ToAggregate()
+}
+
+// parseClock returns the minutes since midnight of an HH:MM time.
+func parseClock(hhmm string) (int, error) {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return 0, fmt.Errorf("must be a time in HH:MM format")
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}